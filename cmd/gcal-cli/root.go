@@ -1,12 +1,16 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/btafoya/gcal-cli/internal/commands"
 	"github.com/btafoya/gcal-cli/pkg/config"
 	"github.com/btafoya/gcal-cli/pkg/output"
+	"github.com/btafoya/gcal-cli/pkg/types"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -16,6 +20,15 @@ var (
 	outputFormat string
 	calendarID   string
 	timezone     string
+	backendType  string
+	fakeSeed     string
+	fakePersist  bool
+	offline      bool
+	queueOnFail  bool
+	profile      string
+	impersonate  string
+
+	errorsToStderr bool
 )
 
 // rootCmd represents the base command
@@ -29,12 +42,23 @@ a command-line interface optimized for machine-readable interactions.`,
 	SilenceUsage:  true,
 }
 
-// Execute runs the root command
+// Execute runs the root command. SIGINT and SIGTERM cancel the command's
+// context, so in-flight API requests and retries stop cleanly. The process
+// exits with the code for the error the command reported, if any.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	// Responses go to stdout; cobra would otherwise print them to stderr
+	rootCmd.SetOut(os.Stdout)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
+
+	// Errors returned by cobra itself are unknown commands or bad flags
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(types.ExitUsage)
 	}
+	os.Exit(commands.ExitCode())
 }
 
 func init() {
@@ -45,15 +69,38 @@ func init() {
 		"config file (default: ~/.config/gcal-cli/config.yaml)")
 	rootCmd.PersistentFlags().StringVar(&outputFormat, "format", "json",
 		"output format (json|text|minimal)")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "",
+		"account profile to use (default: $GCAL_PROFILE or the profile set with 'profiles use')")
 	rootCmd.PersistentFlags().StringVar(&calendarID, "calendar-id", "primary",
 		"calendar ID to operate on")
 	rootCmd.PersistentFlags().StringVar(&timezone, "timezone", "",
 		"timezone for operations (default: system timezone)")
+	rootCmd.PersistentFlags().StringVar(&backendType, "backend", "google",
+		"calendar backend (google|fake)")
+	rootCmd.PersistentFlags().StringVar(&fakeSeed, "fake-seed", "",
+		"JSON seed file for the fake backend")
+	rootCmd.PersistentFlags().BoolVar(&fakePersist, "fake-persist", false,
+		"write fake backend changes back to the seed file")
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", false,
+		"answer event reads from the local cache only")
+	rootCmd.PersistentFlags().BoolVar(&queueOnFail, "queue-on-failure", false,
+		"queue creates, updates and deletes that fail with network errors")
+	rootCmd.PersistentFlags().StringVar(&impersonate, "impersonate", "",
+		"user to act as with a service account (domain-wide delegation)")
+	rootCmd.PersistentFlags().BoolVar(&errorsToStderr, "errors-to-stderr", false,
+		"write error responses to stderr, keeping stdout for successful results")
 
 	// Bind flags to viper
 	viper.BindPFlag("output.default_format", rootCmd.PersistentFlags().Lookup("format"))
 	viper.BindPFlag("calendar.default_calendar_id", rootCmd.PersistentFlags().Lookup("calendar-id"))
 	viper.BindPFlag("calendar.default_timezone", rootCmd.PersistentFlags().Lookup("timezone"))
+	viper.BindPFlag("backend.type", rootCmd.PersistentFlags().Lookup("backend"))
+	viper.BindPFlag("backend.fake_seed", rootCmd.PersistentFlags().Lookup("fake-seed"))
+	viper.BindPFlag("backend.fake_persist", rootCmd.PersistentFlags().Lookup("fake-persist"))
+	viper.BindPFlag("cache.offline", rootCmd.PersistentFlags().Lookup("offline"))
+	viper.BindPFlag("queue.on_failure", rootCmd.PersistentFlags().Lookup("queue-on-failure"))
+	viper.BindPFlag("auth.impersonate", rootCmd.PersistentFlags().Lookup("impersonate"))
+	viper.BindPFlag("output.errors_to_stderr", rootCmd.PersistentFlags().Lookup("errors-to-stderr"))
 
 	// Add subcommands
 	formatter := getFormatter()
@@ -62,13 +109,34 @@ func init() {
 	rootCmd.AddCommand(commands.NewAuthCommand(formatter))
 	rootCmd.AddCommand(commands.NewEventsCommand(formatter))
 	rootCmd.AddCommand(commands.NewCalendarsCommand(formatter))
+	rootCmd.AddCommand(commands.NewServeCommand(formatter))
+	rootCmd.AddCommand(commands.NewCacheCommand(formatter))
+	rootCmd.AddCommand(commands.NewQueueCommand(formatter))
+	rootCmd.AddCommand(commands.NewProfilesCommand(formatter))
 }
 
 // initConfig reads in config file and ENV variables
 func initConfig() {
 	if err := config.Initialize(cfgFile); err != nil {
 		fmt.Fprintf(os.Stderr, "Error initializing config: %v\n", err)
-		os.Exit(1)
+		os.Exit(types.ExitConfig)
+	}
+
+	// Flags given on the command line win over the profile's settings
+	var explicit []string
+	if rootCmd.PersistentFlags().Changed("calendar-id") {
+		explicit = append(explicit, "calendar.default_calendar_id")
+	}
+	if rootCmd.PersistentFlags().Changed("timezone") {
+		explicit = append(explicit, "calendar.default_timezone")
+	}
+	if rootCmd.PersistentFlags().Changed("impersonate") {
+		explicit = append(explicit, "auth.impersonate")
+	}
+
+	if err := config.ApplyProfile(profile, explicit...); err != nil {
+		fmt.Fprintf(os.Stderr, "Error selecting profile: %v\n", err)
+		os.Exit(types.ExitConfig)
 	}
 }
 
@@ -3,6 +3,7 @@ package commands
 import (
 	"context"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/btafoya/gcal-cli/pkg/auth"
@@ -11,6 +12,8 @@ import (
 	"github.com/btafoya/gcal-cli/pkg/output"
 	"github.com/btafoya/gcal-cli/pkg/types"
 	"github.com/spf13/cobra"
+	"golang.org/x/oauth2"
+	"golang.org/x/term"
 )
 
 // NewAuthCommand creates the auth command group
@@ -24,99 +27,159 @@ func NewAuthCommand(formatter output.Formatter) *cobra.Command {
 	cmd.AddCommand(newAuthLoginCommand(formatter))
 	cmd.AddCommand(newAuthLogoutCommand(formatter))
 	cmd.AddCommand(newAuthStatusCommand(formatter))
+	cmd.AddCommand(newAuthMigrateTokensCommand(formatter))
 
 	return cmd
 }
 
 func newAuthLoginCommand(formatter output.Formatter) *cobra.Command {
-	return &cobra.Command{
-		Use:     "login",
-		Short:   "Authenticate with Google Calendar",
-		Long:    "Start the OAuth2 authentication flow to obtain and store Google Calendar credentials",
+	var (
+		device     bool
+		port       int
+		noBrowser  bool
+		scopeLevel string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "login",
+		Short: "Authenticate with Google Calendar",
+		Long: `Start the OAuth2 authentication flow to obtain and store Google Calendar credentials.
+
+The browser flow uses PKCE and a local callback server on --port (8080 by
+default), falling back to a free port if that one is busy. Instructions are
+printed to stderr; --no-browser prints the URL without opening a browser.
+
+With --device, no browser or local callback server is needed on this machine:
+a verification URL and code are printed to stderr, to be opened on any other
+device, and the token is stored once access is approved.`,
 		Example: examples.AuthLoginExamples,
 		Run: func(cmd *cobra.Command, args []string) {
-			ctx := context.Background()
-
-			// Get credentials and token paths from config
-			credentialsPath := config.GetString("auth.credentials_path")
-			tokenPath := config.GetString("auth.token_path")
+			ctx := cmd.Context()
 
 			// Create auth manager
-			manager, err := auth.NewManager(credentialsPath, tokenPath)
+			manager, err := newAuthManager()
 			if err != nil {
 				appErr, ok := err.(*types.AppError)
 				if !ok {
 					appErr = types.ErrAuthFailed("authentication initialization failed").
 						WithWrappedError(err)
 				}
-				response := types.ErrorResponse(appErr)
-				output, _ := formatter.Format(response)
-				cmd.Println(output)
+				outputError(cmd, formatter, appErr)
+				return
+			}
+
+			scopes, err := auth.ScopesForLevel(scopeLevel)
+			if err != nil {
+				outputError(cmd, formatter, err)
 				return
 			}
 
 			// Perform login
-			token, err := manager.Login(ctx)
+			opts := auth.LoginOptions{
+				Port:      port,
+				NoBrowser: noBrowser,
+				Out:       cmd.ErrOrStderr(),
+				Scopes:    scopes,
+			}
+			var token *oauth2.Token
+			if device {
+				token, err = manager.LoginDevice(ctx, opts)
+			} else {
+				token, err = manager.Login(ctx, opts)
+			}
 			if err != nil {
 				appErr, ok := err.(*types.AppError)
 				if !ok {
 					appErr = types.ErrAuthFailed("authentication failed").
 						WithWrappedError(err)
 				}
-				response := types.ErrorResponse(appErr)
-				output, _ := formatter.Format(response)
-				cmd.Println(output)
+				outputError(cmd, formatter, appErr)
 				return
 			}
 
+			// Logging in to a new profile creates it
+			profile := config.ActiveProfile()
+			if !config.ProfileExists(profile) {
+				if err := config.SaveProfile(profile, config.ProfileConfig{}); err != nil {
+					outputError(cmd, formatter, err)
+					return
+				}
+			}
+
 			// Get user info
 			email, _ := auth.GetUserInfo(token)
+			if identity := manager.Identity(); identity != "" {
+				email = identity
+			}
 			if email == "" {
 				email = "authenticated"
 			}
 
+			message := "Successfully authenticated with Google Calendar"
+			if manager.ServiceAccount != nil {
+				message = "Service account credentials verified"
+			}
+
 			response := types.SuccessResponse("auth_login", map[string]interface{}{
-				"message":    "Successfully authenticated with Google Calendar",
+				"message":    message,
 				"email":      email,
 				"expires_at": token.Expiry.Format(time.RFC3339),
+				"profile":    profile,
+				"authType":   authTypeOf(manager),
+				"scopes":     auth.TokenScopes(token),
 			})
 			output, err := formatter.Format(response)
 			if err != nil {
-				cmd.PrintErrf("Error formatting output: %v\n", err)
+				outputFormatError(cmd, err)
 				return
 			}
 			cmd.Println(output)
 		},
 	}
+
+	cmd.Flags().BoolVar(&device, "device", false, "use the device code flow (for SSH sessions and containers)")
+	cmd.Flags().IntVar(&port, "port", 0,
+		fmt.Sprintf("port for the local OAuth callback server (default %d, or a free port if it is busy)", auth.DefaultCallbackPort))
+	cmd.Flags().BoolVar(&noBrowser, "no-browser", false, "print the authorization URL instead of opening a browser")
+	cmd.Flags().StringVar(&scopeLevel, "scopes", auth.ScopeLevelFull, "access to request: readonly, events or full")
+
+	return cmd
 }
 
 func newAuthLogoutCommand(formatter output.Formatter) *cobra.Command {
-	return &cobra.Command{
-		Use:     "logout",
-		Short:   "Remove authentication credentials",
-		Long:    "Delete stored authentication token and remove Google Calendar access",
+	var revoke bool
+
+	cmd := &cobra.Command{
+		Use:   "logout",
+		Short: "Remove authentication credentials",
+		Long: `Delete stored authentication token and remove Google Calendar access.
+
+With --revoke, the grant is first revoked at Google so the token stops working
+everywhere; the local token is kept if revocation fails.`,
 		Example: examples.AuthLogoutExamples,
 		Run: func(cmd *cobra.Command, args []string) {
-			// Get token path from config
-			tokenPath := config.GetString("auth.token_path")
-
 			// Create auth manager
-			manager, err := auth.NewManager(
-				config.GetString("auth.credentials_path"),
-				tokenPath,
-			)
+			manager, err := newAuthManager()
 			if err != nil {
 				appErr, ok := err.(*types.AppError)
 				if !ok {
 					appErr = types.ErrAuthFailed("logout initialization failed").
 						WithWrappedError(err)
 				}
-				response := types.ErrorResponse(appErr)
-				output, _ := formatter.Format(response)
-				cmd.Println(output)
+				outputError(cmd, formatter, appErr)
 				return
 			}
 
+			// Revoke the grant before forgetting the token
+			revoked := false
+			if revoke && (manager.ServiceAccount != nil || manager.Storage.TokenExists()) {
+				if err := manager.Revoke(cmd.Context()); err != nil {
+					outputError(cmd, formatter, err)
+					return
+				}
+				revoked = true
+			}
+
 			// Perform logout
 			if err := manager.Logout(); err != nil {
 				appErr, ok := err.(*types.AppError)
@@ -124,49 +187,55 @@ func newAuthLogoutCommand(formatter output.Formatter) *cobra.Command {
 					appErr = types.ErrAuthFailed("logout failed").
 						WithWrappedError(err)
 				}
-				response := types.ErrorResponse(appErr)
-				output, _ := formatter.Format(response)
-				cmd.Println(output)
+				outputError(cmd, formatter, appErr)
 				return
 			}
 
+			message := "Successfully logged out and removed authentication credentials"
+			if manager.ServiceAccount != nil {
+				message = "Service accounts store no token; remove the key file to revoke access"
+			}
+
 			response := types.SuccessResponse("auth_logout", map[string]interface{}{
-				"message": "Successfully logged out and removed authentication credentials",
+				"message": message,
+				"revoked": revoked,
 			})
 			output, err := formatter.Format(response)
 			if err != nil {
-				cmd.PrintErrf("Error formatting output: %v\n", err)
+				outputFormatError(cmd, err)
 				return
 			}
 			cmd.Println(output)
 		},
 	}
+
+	cmd.Flags().BoolVar(&revoke, "revoke", false, "revoke the grant at Google before removing the local token")
+
+	return cmd
 }
 
 func newAuthStatusCommand(formatter output.Formatter) *cobra.Command {
-	return &cobra.Command{
-		Use:     "status",
-		Short:   "Check authentication status",
-		Long:    "Display current authentication status including token expiry and user email",
+	var live bool
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Check authentication status",
+		Long: `Display current authentication status including token expiry and user email.
+The active profile is checked with Google; other profiles report only what
+their stored token shows, unless --live is given.`,
 		Example: examples.AuthStatusExamples,
 		Run: func(cmd *cobra.Command, args []string) {
-			ctx := context.Background()
-
-			// Get credentials and token paths from config
-			credentialsPath := config.GetString("auth.credentials_path")
-			tokenPath := config.GetString("auth.token_path")
+			ctx := cmd.Context()
 
 			// Create auth manager
-			manager, err := auth.NewManager(credentialsPath, tokenPath)
+			manager, err := newAuthManager()
 			if err != nil {
 				appErr, ok := err.(*types.AppError)
 				if !ok {
 					appErr = types.ErrAuthFailed("status check initialization failed").
 						WithWrappedError(err)
 				}
-				response := types.ErrorResponse(appErr)
-				output, _ := formatter.Format(response)
-				cmd.Println(output)
+				outputError(cmd, formatter, appErr)
 				return
 			}
 
@@ -178,15 +247,25 @@ func newAuthStatusCommand(formatter output.Formatter) *cobra.Command {
 					appErr = types.ErrAuthFailed("status check failed").
 						WithWrappedError(err)
 				}
-				response := types.ErrorResponse(appErr)
-				output, _ := formatter.Format(response)
-				cmd.Println(output)
+				outputError(cmd, formatter, appErr)
 				return
 			}
 
 			// Build status response
 			statusData := map[string]interface{}{
 				"authenticated": authenticated,
+				"authType":      authTypeOf(manager),
+			}
+			if scopes, err := manager.Scopes(); err == nil && scopes != nil {
+				statusData["scopes"] = scopes
+			}
+			if manager.ServiceAccount != nil {
+				// The acting identity is the impersonated user, if any
+				statusData["identity"] = manager.Identity()
+				statusData["serviceAccount"] = manager.ServiceAccount.Config.Email
+				if subject := manager.ServiceAccount.Config.Subject; subject != "" {
+					statusData["impersonating"] = subject
+				}
 			}
 
 			if authenticated {
@@ -208,13 +287,274 @@ func newAuthStatusCommand(formatter output.Formatter) *cobra.Command {
 				statusData["message"] = "Not authenticated. Run 'gcal-cli auth login' to authenticate."
 			}
 
+			statusData["profile"] = config.ActiveProfile()
+			profiles, err := profileStatuses(ctx, live)
+			if err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+			statusData["profiles"] = profiles
+
 			response := types.SuccessResponse("auth_status", statusData)
 			output, err := formatter.Format(response)
 			if err != nil {
-				cmd.PrintErrf("Error formatting output: %v\n", err)
+				outputFormatError(cmd, err)
+				return
+			}
+			cmd.Println(output)
+		},
+	}
+
+	cmd.Flags().BoolVar(&live, "live", false, "check every profile with Google, not just the active one")
+
+	return cmd
+}
+
+// profileStatuses reports the authentication status of every profile. Only
+// the active profile is checked with Google unless live is set.
+func profileStatuses(ctx context.Context, live bool) ([]map[string]interface{}, error) {
+	names, err := config.ProfileNames()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]map[string]interface{}, 0, len(names))
+	for _, name := range names {
+		settings := config.ResolveProfile(name)
+		active := name == config.ActiveProfile()
+		status := map[string]interface{}{
+			"name":       name,
+			"active":     active,
+			"authType":   settings.AuthType,
+			"tokensPath": settings.TokensPath,
+		}
+		statuses = append(statuses, status)
+
+		if settings.AuthType != "service_account" {
+			localTokenStatus(status, settings.TokensPath)
+		}
+		if !active && !live {
+			continue
+		}
+
+		status["authenticated"] = false
+		manager, err := newAuthManagerFor(settings)
+		if err != nil {
+			status["error"] = err.Error()
+			continue
+		}
+
+		authenticated, email, expiresAt, err := manager.CheckAuthStatus(ctx)
+		status["authenticated"] = authenticated
+		if err != nil {
+			status["error"] = err.Error()
+			continue
+		}
+		if authenticated {
+			status["email"] = email
+			status["expires_at"] = expiresAt.Format(time.RFC3339)
+		}
+	}
+
+	return statuses, nil
+}
+
+// localTokenStatus adds what the token file at path shows to status, without
+// contacting Google or asking for a passphrase
+func localTokenStatus(status map[string]interface{}, path string) {
+	storage := auth.NewTokenStorage(path)
+	status["tokenPresent"] = storage.TokenExists()
+	if !storage.TokenExists() {
+		return
+	}
+
+	status["encrypted"] = storage.IsEncrypted()
+	if storage.IsEncrypted() {
+		// Reading it would need the passphrase
+		return
+	}
+
+	token, err := storage.LoadToken()
+	if err != nil {
+		status["error"] = err.Error()
+		return
+	}
+	if scopes := auth.TokenScopes(token); scopes != nil {
+		status["scopes"] = scopes
+	}
+	if !token.Expiry.IsZero() {
+		status["expires_at"] = token.Expiry.Format(time.RFC3339)
+	}
+}
+
+func newAuthMigrateTokensCommand(formatter output.Formatter) *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "migrate-tokens",
+		Short: "Encrypt stored tokens",
+		Long: `Convert plaintext token files to encrypted ones and switch auth.token_store
+to 'encrypted'. The passphrase is read from GCAL_TOKEN_PASSPHRASE or prompted
+for. Tokens that are already encrypted are left alone.`,
+		Example: examples.AuthMigrateTokensExamples,
+		Run: func(cmd *cobra.Command, args []string) {
+			names := []string{config.ActiveProfile()}
+			if all {
+				var err error
+				if names, err = config.ProfileNames(); err != nil {
+					outputError(cmd, formatter, err)
+					return
+				}
+			}
+
+			passphrase, err := tokenPassphrase(true)
+			if err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+
+			// Switch the store first: if a later profile fails, the tokens
+			// already encrypted must still be refreshed encrypted
+			config.Set("auth.token_store", "encrypted")
+			if err := config.Save(); err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+
+			results := make([]map[string]interface{}, 0, len(names))
+			for _, name := range names {
+				settings := config.ResolveProfile(name)
+				if name == config.ActiveProfile() {
+					settings.TokensPath = config.GetString("auth.tokens_path")
+				}
+				result := map[string]interface{}{
+					"profile":    name,
+					"tokensPath": settings.TokensPath,
+				}
+				results = append(results, result)
+
+				storage := auth.NewTokenStorage(settings.TokensPath)
+				storage.Passphrase = func() (string, error) { return passphrase, nil }
+				switch {
+				case !storage.TokenExists():
+					result["status"] = "no_token"
+					continue
+				case storage.IsEncrypted():
+					result["status"] = "already_encrypted"
+					continue
+				}
+
+				token, err := storage.LoadToken()
+				if err == nil {
+					storage.Encrypted = true
+					err = storage.SaveToken(token)
+				}
+				if err != nil {
+					outputError(cmd, formatter, err)
+					return
+				}
+				result["status"] = "encrypted"
+			}
+
+			response := types.SuccessResponse("auth_migrate_tokens", map[string]interface{}{
+				"tokens":     results,
+				"tokenStore": "encrypted",
+			})
+			output, err := formatter.Format(response)
+			if err != nil {
+				outputFormatError(cmd, err)
 				return
 			}
 			cmd.Println(output)
 		},
 	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "migrate the tokens of every profile")
+
+	return cmd
+}
+
+// configureTokenStorage applies auth.token_store to a token storage.
+// Encrypted tokens can always be read, whatever the setting, and are never
+// rewritten as plaintext.
+func configureTokenStorage(storage *auth.TokenStorage) error {
+	switch store := config.GetString("auth.token_store"); store {
+	case "", "file":
+	case "encrypted":
+		storage.Encrypted = true
+	default:
+		return types.ErrInvalidInput("auth.token_store",
+			fmt.Sprintf("unknown token store '%s' (must be 'file' or 'encrypted')", store))
+	}
+	if storage.IsEncrypted() {
+		storage.Encrypted = true
+	}
+
+	// A passphrase for a token that is not encrypted yet is a new one
+	storage.Passphrase = func() (string, error) {
+		return tokenPassphrase(!storage.IsEncrypted())
+	}
+	return nil
+}
+
+// tokenPassphraseEnv names the environment variable holding the token passphrase
+const tokenPassphraseEnv = "GCAL_TOKEN_PASSPHRASE"
+
+// promptedPassphrase keeps a prompted passphrase for the rest of the command
+var promptedPassphrase string
+
+// tokenPassphrase returns the passphrase for encrypted tokens from
+// GCAL_TOKEN_PASSPHRASE, or prompts for it on the terminal. A new passphrase
+// is asked for twice.
+func tokenPassphrase(confirm bool) (string, error) {
+	if passphrase := os.Getenv(tokenPassphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+	if promptedPassphrase != "" {
+		return promptedPassphrase, nil
+	}
+
+	passphrase, err := promptPassphrase("Token passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if confirm {
+		again, err := promptPassphrase("Repeat passphrase: ")
+		if err != nil {
+			return "", err
+		}
+		if again != passphrase {
+			return "", types.ErrInvalidInput("passphrase", "passphrases do not match")
+		}
+	}
+
+	promptedPassphrase = passphrase
+	return passphrase, nil
+}
+
+// promptPassphrase reads a passphrase from the terminal without echoing it
+func promptPassphrase(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", types.ErrMissingRequired(tokenPassphraseEnv).
+			WithSuggestedAction("Set " + tokenPassphraseEnv + " when not running in a terminal")
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", types.ErrInvalidInput("passphrase", "could not read passphrase").
+			WithWrappedError(err)
+	}
+
+	return string(passphrase), nil
+}
+
+// authTypeOf names the kind of credentials a manager uses
+func authTypeOf(manager *auth.Manager) string {
+	if manager.ServiceAccount != nil {
+		return "service_account"
+	}
+	return "oauth"
 }
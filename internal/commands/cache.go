@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"time"
+
+	"github.com/btafoya/gcal-cli/pkg/cache"
+	"github.com/btafoya/gcal-cli/pkg/config"
+	"github.com/btafoya/gcal-cli/pkg/examples"
+	"github.com/btafoya/gcal-cli/pkg/output"
+	"github.com/btafoya/gcal-cli/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// NewCacheCommand creates the cache command group
+func NewCacheCommand(formatter output.Formatter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the local event cache",
+		Long:  "Inspect and clear the on-disk cache used for event reads and --offline mode",
+	}
+
+	cmd.AddCommand(newCacheStatusCommand(formatter))
+	cmd.AddCommand(newCacheClearCommand(formatter))
+
+	return cmd
+}
+
+func newCacheStatusCommand(formatter output.Formatter) *cobra.Command {
+	return &cobra.Command{
+		Use:     "status",
+		Short:   "Show cache statistics",
+		Long:    "Show the cache directory, TTL, and the number, age and size of cached entries",
+		Example: examples.CacheStatusExamples,
+		Run: func(cmd *cobra.Command, args []string) {
+			status, err := openEventCache().Status()
+			if err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+
+			response := types.SuccessResponse("cache_status", map[string]interface{}{
+				"enabled": config.GetBool("cache.enabled"),
+				"status":  status,
+			})
+			output, err := formatter.Format(response)
+			if err != nil {
+				outputFormatError(cmd, err)
+				return
+			}
+			cmd.Println(output)
+		},
+	}
+}
+
+func newCacheClearCommand(formatter output.Formatter) *cobra.Command {
+	var calendarID string
+
+	cmd := &cobra.Command{
+		Use:     "clear",
+		Short:   "Remove cached entries",
+		Long:    "Remove all cached event reads, or only those for one calendar",
+		Example: examples.CacheClearExamples,
+		Run: func(cmd *cobra.Command, args []string) {
+			removed, err := openEventCache().Clear(calendarID)
+			if err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+
+			response := types.SuccessResponse("cache_clear", map[string]interface{}{
+				"removed":  removed,
+				"calendar": calendarID,
+			})
+			output, err := formatter.Format(response)
+			if err != nil {
+				outputFormatError(cmd, err)
+				return
+			}
+			cmd.Println(output)
+		},
+	}
+
+	cmd.Flags().StringVar(&calendarID, "calendar", "", "only clear entries for this calendar ID")
+
+	return cmd
+}
+
+// openEventCache opens the cache directory even when caching is disabled, so
+// leftover entries can still be inspected and cleared
+func openEventCache() *cache.Cache {
+	ttl := time.Duration(config.GetInt("cache.ttl_seconds")) * time.Second
+	return cache.New(eventCacheDir(), ttl)
+}
@@ -1,8 +1,6 @@
 package commands
 
 import (
-	"context"
-
 	"github.com/btafoya/gcal-cli/pkg/examples"
 	"github.com/btafoya/gcal-cli/pkg/output"
 	"github.com/btafoya/gcal-cli/pkg/types"
@@ -30,7 +28,7 @@ func newCalendarsListCommand(formatter output.Formatter) *cobra.Command {
 		Long:    "List all calendars accessible to the authenticated user with access roles and timezone information",
 		Example: examples.CalendarsListExamples,
 		Run: func(cmd *cobra.Command, args []string) {
-			ctx := context.Background()
+			ctx := cmd.Context()
 
 			// Get calendar client
 			client, err := getCalendarClient(ctx)
@@ -53,7 +51,7 @@ func newCalendarsListCommand(formatter output.Formatter) *cobra.Command {
 			})
 			output, err := formatter.Format(response)
 			if err != nil {
-				cmd.PrintErrf("Error formatting output: %v\n", err)
+				outputFormatError(cmd, err)
 				return
 			}
 			cmd.Println(output)
@@ -69,7 +67,7 @@ func newCalendarsGetCommand(formatter output.Formatter) *cobra.Command {
 		Example: examples.CalendarsGetExamples,
 		Args:    cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			ctx := context.Background()
+			ctx := cmd.Context()
 			var calendarID string
 			if len(args) > 0 {
 				calendarID = args[0]
@@ -95,7 +93,7 @@ func newCalendarsGetCommand(formatter output.Formatter) *cobra.Command {
 			})
 			output, err := formatter.Format(response)
 			if err != nil {
-				cmd.PrintErrf("Error formatting output: %v\n", err)
+				outputFormatError(cmd, err)
 				return
 			}
 			cmd.Println(output)
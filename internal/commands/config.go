@@ -34,9 +34,7 @@ func newConfigShowCommand(formatter output.Formatter) *cobra.Command {
 			if err != nil {
 				appErr := types.ErrConfigError("failed to display configuration").
 					WithWrappedError(err)
-				response := types.ErrorResponse(appErr)
-				output, _ := formatter.Format(response)
-				cmd.Println(output)
+				outputError(cmd, formatter, appErr)
 				return
 			}
 
@@ -52,7 +50,7 @@ func newConfigShowCommand(formatter output.Formatter) *cobra.Command {
 			})
 			output, err := formatter.Format(response)
 			if err != nil {
-				cmd.PrintErrf("Error formatting output: %v\n", err)
+				outputFormatError(cmd, err)
 				return
 			}
 			cmd.Println(output)
@@ -73,9 +71,7 @@ func newConfigInitCommand(formatter output.Formatter) *cobra.Command {
 					appErr = types.ErrConfigError("failed to create config directory").
 						WithWrappedError(err)
 				}
-				response := types.ErrorResponse(appErr)
-				output, _ := formatter.Format(response)
-				cmd.Println(output)
+				outputError(cmd, formatter, appErr)
 				return
 			}
 
@@ -85,9 +81,7 @@ func newConfigInitCommand(formatter output.Formatter) *cobra.Command {
 					appErr = types.ErrConfigError("failed to save config file").
 						WithWrappedError(err)
 				}
-				response := types.ErrorResponse(appErr)
-				output, _ := formatter.Format(response)
-				cmd.Println(output)
+				outputError(cmd, formatter, appErr)
 				return
 			}
 
@@ -98,7 +92,7 @@ func newConfigInitCommand(formatter output.Formatter) *cobra.Command {
 			})
 			output, err := formatter.Format(response)
 			if err != nil {
-				cmd.PrintErrf("Error formatting output: %v\n", err)
+				outputFormatError(cmd, err)
 				return
 			}
 			cmd.Println(output)
@@ -124,9 +118,7 @@ func newConfigSetCommand(formatter output.Formatter) *cobra.Command {
 					appErr = types.ErrConfigError("failed to save config file").
 						WithWrappedError(err)
 				}
-				response := types.ErrorResponse(appErr)
-				output, _ := formatter.Format(response)
-				cmd.Println(output)
+				outputError(cmd, formatter, appErr)
 				return
 			}
 
@@ -137,7 +129,7 @@ func newConfigSetCommand(formatter output.Formatter) *cobra.Command {
 			})
 			output, err := formatter.Format(response)
 			if err != nil {
-				cmd.PrintErrf("Error formatting output: %v\n", err)
+				outputFormatError(cmd, err)
 				return
 			}
 			cmd.Println(output)
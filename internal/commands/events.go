@@ -2,11 +2,16 @@ package commands
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/btafoya/gcal-cli/pkg/auth"
+	"github.com/btafoya/gcal-cli/pkg/cache"
 	"github.com/btafoya/gcal-cli/pkg/calendar"
 	"github.com/btafoya/gcal-cli/pkg/config"
 	"github.com/btafoya/gcal-cli/pkg/examples"
@@ -28,20 +33,33 @@ func NewEventsCommand(formatter output.Formatter) *cobra.Command {
 	cmd.AddCommand(newEventsGetCommand(formatter))
 	cmd.AddCommand(newEventsUpdateCommand(formatter))
 	cmd.AddCommand(newEventsDeleteCommand(formatter))
+	cmd.AddCommand(newEventsMoveCommand(formatter))
+	cmd.AddCommand(newEventsCopyCommand(formatter))
+	cmd.AddCommand(newEventsDuplicateCommand(formatter))
+	cmd.AddCommand(newEventsQuickAddCommand(formatter))
+	cmd.AddCommand(newEventsRespondCommand(formatter))
+	cmd.AddCommand(newEventsInvitesCommand(formatter))
+	cmd.AddCommand(newEventsOutOfOfficeCommand(formatter))
+	cmd.AddCommand(newEventsFocusCommand(formatter))
+	cmd.AddCommand(newEventsWorkingLocationCommand(formatter))
 
 	return cmd
 }
 
 func newEventsCreateCommand(formatter output.Formatter) *cobra.Command {
 	var (
-		title       string
-		description string
-		location    string
-		start       string
-		end         string
-		attendees   string
-		recurrence  string
-		allDay      bool
+		title          string
+		description    string
+		location       string
+		start          string
+		end            string
+		attendees      string
+		recurrence     string
+		allDay         bool
+		meet           bool
+		props          []string
+		sharedProps    []string
+		idempotencyKey string
 	)
 
 	cmd := &cobra.Command{
@@ -50,7 +68,7 @@ func newEventsCreateCommand(formatter output.Formatter) *cobra.Command {
 		Long:    "Create a new event in your Google Calendar with support for attendees, recurrence, and all-day events",
 		Example: examples.EventsCreateExamples,
 		Run: func(cmd *cobra.Command, args []string) {
-			ctx := context.Background()
+			ctx := cmd.Context()
 
 			// Get calendar client
 			client, err := getCalendarClient(ctx)
@@ -83,6 +101,9 @@ func newEventsCreateCommand(formatter output.Formatter) *cobra.Command {
 				End:         endTime,
 				TimeZone:    config.GetString("calendar.default_timezone"),
 				AllDay:      allDay,
+				Meet:        meet,
+
+				IdempotencyKey: idempotencyKey,
 			}
 
 			// Parse attendees
@@ -98,6 +119,16 @@ func newEventsCreateCommand(formatter output.Formatter) *cobra.Command {
 				params.Recurrence = []string{recurrence}
 			}
 
+			// Parse extended properties
+			if params.Properties, err = calendar.ParseProperties("prop", props); err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+			if params.SharedProperties, err = calendar.ParseProperties("shared-prop", sharedProps); err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+
 			// Create event
 			event, err := client.CreateEvent(ctx, params)
 			if err != nil {
@@ -112,7 +143,7 @@ func newEventsCreateCommand(formatter output.Formatter) *cobra.Command {
 			})
 			output, err := formatter.Format(response)
 			if err != nil {
-				cmd.PrintErrf("Error formatting output: %v\n", err)
+				outputFormatError(cmd, err)
 				return
 			}
 			cmd.Println(output)
@@ -127,6 +158,10 @@ func newEventsCreateCommand(formatter output.Formatter) *cobra.Command {
 	cmd.Flags().StringVar(&attendees, "attendees", "", "Comma-separated email addresses")
 	cmd.Flags().StringVar(&recurrence, "recurrence", "", "Recurrence rule (RFC5545 format)")
 	cmd.Flags().BoolVar(&allDay, "all-day", false, "Create all-day event")
+	cmd.Flags().BoolVar(&meet, "meet", false, "Add a Google Meet video conference")
+	cmd.Flags().StringArrayVar(&props, "prop", nil, "Private extended property as key=value (repeatable)")
+	cmd.Flags().StringArrayVar(&sharedProps, "shared-prop", nil, "Shared extended property, visible to attendees, as key=value (repeatable)")
+	addIdempotencyKeyFlag(cmd, &idempotencyKey)
 
 	cmd.MarkFlagRequired("title")
 	cmd.MarkFlagRequired("start")
@@ -135,6 +170,13 @@ func newEventsCreateCommand(formatter output.Formatter) *cobra.Command {
 	return cmd
 }
 
+// addIdempotencyKeyFlag registers --idempotency-key on a command that
+// creates an event
+func addIdempotencyKeyFlag(cmd *cobra.Command, key *string) {
+	cmd.Flags().StringVar(key, "idempotency-key", "",
+		"Key identifying this event; re-running with the same key returns the existing event instead of a duplicate, or fails with CONFLICT if its summary or times differ")
+}
+
 func newEventsListCommand(formatter output.Formatter) *cobra.Command {
 	var (
 		from       string
@@ -142,6 +184,9 @@ func newEventsListCommand(formatter output.Formatter) *cobra.Command {
 		maxResults int64
 		query      string
 		orderBy    string
+		hasProps   []string
+		hasShared  []string
+		eventTypes string
 	)
 
 	cmd := &cobra.Command{
@@ -150,7 +195,7 @@ func newEventsListCommand(formatter output.Formatter) *cobra.Command {
 		Long:    "List events in a date range from your Google Calendar with optional filtering and sorting",
 		Example: examples.EventsListExamples,
 		Run: func(cmd *cobra.Command, args []string) {
-			ctx := context.Background()
+			ctx := cmd.Context()
 
 			// Get calendar client
 			client, err := getCalendarClient(ctx)
@@ -181,6 +226,16 @@ func newEventsListCommand(formatter output.Formatter) *cobra.Command {
 				MaxResults: maxResults,
 				Query:      query,
 				OrderBy:    orderBy,
+
+				Properties:       hasProps,
+				SharedProperties: hasShared,
+			}
+
+			// Parse event types
+			if eventTypes != "" {
+				for _, kind := range strings.Split(eventTypes, ",") {
+					params.EventTypes = append(params.EventTypes, strings.TrimSpace(kind))
+				}
 			}
 
 			// List events
@@ -197,7 +252,7 @@ func newEventsListCommand(formatter output.Formatter) *cobra.Command {
 			})
 			output, err := formatter.Format(response)
 			if err != nil {
-				cmd.PrintErrf("Error formatting output: %v\n", err)
+				outputFormatError(cmd, err)
 				return
 			}
 			cmd.Println(output)
@@ -209,6 +264,9 @@ func newEventsListCommand(formatter output.Formatter) *cobra.Command {
 	cmd.Flags().Int64Var(&maxResults, "max-results", 250, "Maximum events to return")
 	cmd.Flags().StringVar(&query, "query", "", "Search query string")
 	cmd.Flags().StringVar(&orderBy, "order-by", "startTime", "Sort order (startTime|updated)")
+	cmd.Flags().StringArrayVar(&hasProps, "has-prop", nil, "Only events with this private extended property, as key=value (repeatable)")
+	cmd.Flags().StringArrayVar(&hasShared, "has-shared-prop", nil, "Only events with this shared extended property, as key=value (repeatable)")
+	cmd.Flags().StringVar(&eventTypes, "event-type", "", "Comma-separated event types to list: "+strings.Join(calendar.EventTypes, ","))
 
 	cmd.MarkFlagRequired("from")
 	cmd.MarkFlagRequired("to")
@@ -224,7 +282,7 @@ func newEventsGetCommand(formatter output.Formatter) *cobra.Command {
 		Example: examples.EventsGetExamples,
 		Args:    cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			ctx := context.Background()
+			ctx := cmd.Context()
 			eventID := args[0]
 
 			// Get calendar client
@@ -247,7 +305,7 @@ func newEventsGetCommand(formatter output.Formatter) *cobra.Command {
 			})
 			output, err := formatter.Format(response)
 			if err != nil {
-				cmd.PrintErrf("Error formatting output: %v\n", err)
+				outputFormatError(cmd, err)
 				return
 			}
 			cmd.Println(output)
@@ -265,16 +323,22 @@ func newEventsUpdateCommand(formatter output.Formatter) *cobra.Command {
 		attendees   string
 		recurrence  string
 		allDay      bool
+		meet        bool
+		props       []string
+		sharedProps []string
+		clearFields string
+		ifMatch     string
+		force       bool
 	)
 
 	cmd := &cobra.Command{
 		Use:     "update <event-id>",
 		Short:   "Update a calendar event",
-		Long:    "Update an existing event in your Google Calendar with partial updates (only specified fields are changed)",
+		Long:    "Update an existing event in your Google Calendar with partial updates (only specified fields are changed; use --clear to remove a field)",
 		Example: examples.EventsUpdateExamples,
 		Args:    cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			ctx := context.Background()
+			ctx := cmd.Context()
 			eventID := args[0]
 
 			// Get calendar client
@@ -291,6 +355,9 @@ func newEventsUpdateCommand(formatter output.Formatter) *cobra.Command {
 				Location:    location,
 				TimeZone:    config.GetString("calendar.default_timezone"),
 				AllDay:      allDay,
+				Meet:        meet,
+				IfMatch:     ifMatch,
+				Force:       force,
 			}
 
 			// Parse start and end times if provided
@@ -327,6 +394,23 @@ func newEventsUpdateCommand(formatter output.Formatter) *cobra.Command {
 				params.Recurrence = []string{recurrence}
 			}
 
+			// Parse extended properties
+			if params.Properties, err = calendar.ParseProperties("prop", props); err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+			if params.SharedProperties, err = calendar.ParseProperties("shared-prop", sharedProps); err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+
+			// Parse fields to clear
+			if clearFields != "" {
+				for _, field := range strings.Split(clearFields, ",") {
+					params.Clear = append(params.Clear, strings.TrimSpace(field))
+				}
+			}
+
 			// Update event
 			event, err := client.UpdateEvent(ctx, eventID, params)
 			if err != nil {
@@ -341,7 +425,7 @@ func newEventsUpdateCommand(formatter output.Formatter) *cobra.Command {
 			})
 			output, err := formatter.Format(response)
 			if err != nil {
-				cmd.PrintErrf("Error formatting output: %v\n", err)
+				outputFormatError(cmd, err)
 				return
 			}
 			cmd.Println(output)
@@ -356,6 +440,13 @@ func newEventsUpdateCommand(formatter output.Formatter) *cobra.Command {
 	cmd.Flags().StringVar(&attendees, "attendees", "", "Comma-separated email addresses")
 	cmd.Flags().StringVar(&recurrence, "recurrence", "", "Recurrence rule (RFC5545 format)")
 	cmd.Flags().BoolVar(&allDay, "all-day", false, "Create all-day event")
+	cmd.Flags().BoolVar(&meet, "meet", false, "Add a Google Meet video conference if the event has none")
+	cmd.Flags().StringArrayVar(&props, "prop", nil, "Set a private extended property as key=value (repeatable); other properties are kept")
+	cmd.Flags().StringArrayVar(&sharedProps, "shared-prop", nil, "Set a shared extended property as key=value (repeatable); other properties are kept")
+	cmd.Flags().StringVar(&clearFields, "clear", "", "Comma-separated fields to remove: "+strings.Join(calendar.ClearableFields, ","))
+	cmd.Flags().StringVar(&ifMatch, "if-match", "", "Only update if the event still has this etag")
+	cmd.Flags().BoolVar(&force, "force", false, "Update even if the event changed since it was read")
+	cmd.MarkFlagsMutuallyExclusive("if-match", "force")
 
 	return cmd
 }
@@ -370,7 +461,7 @@ func newEventsDeleteCommand(formatter output.Formatter) *cobra.Command {
 		Example: examples.EventsDeleteExamples,
 		Args:    cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			ctx := context.Background()
+			ctx := cmd.Context()
 			eventID := args[0]
 
 			// Get calendar client
@@ -394,7 +485,7 @@ func newEventsDeleteCommand(formatter output.Formatter) *cobra.Command {
 			})
 			output, err := formatter.Format(response)
 			if err != nil {
-				cmd.PrintErrf("Error formatting output: %v\n", err)
+				outputFormatError(cmd, err)
 				return
 			}
 			cmd.Println(output)
@@ -406,28 +497,903 @@ func newEventsDeleteCommand(formatter output.Formatter) *cobra.Command {
 	return cmd
 }
 
+func newEventsMoveCommand(formatter output.Formatter) *cobra.Command {
+	var destination string
+
+	cmd := &cobra.Command{
+		Use:     "move <event-id>",
+		Short:   "Move an event to another calendar",
+		Long:    "Move an event from the current calendar (--calendar-id) to another calendar, keeping its ID",
+		Example: examples.EventsMoveExamples,
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := cmd.Context()
+
+			// Get calendar client
+			client, err := getCalendarClient(ctx)
+			if err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+
+			// Move event
+			event, err := client.MoveEvent(ctx, args[0], destination)
+			if err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+
+			// Output success
+			response := types.SuccessResponse("move", map[string]interface{}{
+				"event":      event,
+				"calendarId": destination,
+				"message":    "Event moved successfully",
+			})
+			output, err := formatter.Format(response)
+			if err != nil {
+				outputFormatError(cmd, err)
+				return
+			}
+			cmd.Println(output)
+		},
+	}
+
+	cmd.Flags().StringVar(&destination, "to", "", "Calendar ID to move the event to")
+	cmd.MarkFlagRequired("to")
+
+	return cmd
+}
+
+func newEventsCopyCommand(formatter output.Formatter) *cobra.Command {
+	var destinations string
+
+	cmd := &cobra.Command{
+		Use:     "copy <event-id>",
+		Short:   "Copy an event to other calendars",
+		Long:    "Copy an event from the current calendar (--calendar-id) to one or more calendars as new, independent events",
+		Example: examples.EventsCopyExamples,
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := cmd.Context()
+
+			// Get calendar client
+			client, err := getCalendarClient(ctx)
+			if err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+
+			// Parse destination calendars
+			var calendarIDs []string
+			for _, id := range strings.Split(destinations, ",") {
+				if id = strings.TrimSpace(id); id != "" {
+					calendarIDs = append(calendarIDs, id)
+				}
+			}
+
+			// Copy event
+			events, err := client.CopyEvent(ctx, args[0], calendarIDs)
+			if err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+
+			// Output success
+			response := types.SuccessResponse("copy", map[string]interface{}{
+				"events":  events,
+				"count":   len(events),
+				"message": fmt.Sprintf("Event copied to %d calendar(s)", len(events)),
+			})
+			output, err := formatter.Format(response)
+			if err != nil {
+				outputFormatError(cmd, err)
+				return
+			}
+			cmd.Println(output)
+		},
+	}
+
+	cmd.Flags().StringVar(&destinations, "to", "", "Comma-separated calendar IDs to copy the event to")
+	cmd.MarkFlagRequired("to")
+
+	return cmd
+}
+
+func newEventsDuplicateCommand(formatter output.Formatter) *cobra.Command {
+	var start string
+
+	cmd := &cobra.Command{
+		Use:     "duplicate <event-id>",
+		Short:   "Duplicate an event at a new time",
+		Long:    "Create a copy of an event in the same calendar at a new start time, keeping its duration and details",
+		Example: examples.EventsDuplicateExamples,
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := cmd.Context()
+
+			// Get calendar client
+			client, err := getCalendarClient(ctx)
+			if err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+
+			// Parse new start time; a date is enough for all-day events
+			startTime, err := parseTime(start)
+			if err != nil {
+				startTime, err = parseDate(start)
+			}
+			if err != nil {
+				outputError(cmd, formatter,
+					types.ErrInvalidInput("start", err.Error()))
+				return
+			}
+
+			// Duplicate event
+			event, err := client.DuplicateEvent(ctx, args[0], startTime)
+			if err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+
+			// Output success
+			response := types.SuccessResponse("duplicate", map[string]interface{}{
+				"event":   event,
+				"message": "Event duplicated successfully",
+			})
+			output, err := formatter.Format(response)
+			if err != nil {
+				outputFormatError(cmd, err)
+				return
+			}
+			cmd.Println(output)
+		},
+	}
+
+	cmd.Flags().StringVar(&start, "start", "", "Start time of the copy (RFC3339, YYYY-MM-DD HH:MM, or YYYY-MM-DD)")
+	cmd.MarkFlagRequired("start")
+
+	return cmd
+}
+
+func newEventsQuickAddCommand(formatter output.Formatter) *cobra.Command {
+	var local bool
+
+	cmd := &cobra.Command{
+		Use:   "quick-add <text>",
+		Short: "Create an event from a sentence",
+		Long: `Create an event from a sentence such as "Lunch with Sam tomorrow noon at Cafe Rio".
+By default Google parses the sentence; with --local it is parsed by gcal-cli, which
+also reads durations ("for 90 minutes") and attendee email addresses.`,
+		Example: examples.EventsQuickAddExamples,
+		Args:    cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := cmd.Context()
+			text := strings.Join(args, " ")
+
+			// Get calendar client
+			client, err := getCalendarClient(ctx)
+			if err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+
+			// Create event, parsing the text here or on the server
+			var result *calendar.QuickAddResult
+			if local {
+				tz, err := defaultLocation()
+				if err != nil {
+					outputError(cmd, formatter, err)
+					return
+				}
+				duration := time.Duration(config.GetInt("events.default_duration_minutes")) * time.Minute
+				result, err = client.QuickAddLocal(ctx, text, tz, duration)
+			} else {
+				result, err = client.QuickAdd(ctx, text)
+			}
+			if err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+
+			// Output success
+			response := types.SuccessResponse("quick-add", map[string]interface{}{
+				"text":    result.Text,
+				"local":   result.Local,
+				"parsed":  result.Parsed,
+				"event":   result.Event,
+				"message": "Event created successfully",
+			})
+			output, err := formatter.Format(response)
+			if err != nil {
+				outputFormatError(cmd, err)
+				return
+			}
+			cmd.Println(output)
+		},
+	}
+
+	cmd.Flags().BoolVar(&local, "local", false,
+		"Parse the text locally (title, day, time, duration, location, attendees) instead of on the server")
+
+	return cmd
+}
+
+func newEventsRespondCommand(formatter output.Formatter) *cobra.Command {
+	var (
+		comment string
+		scope   string
+	)
+
+	cmd := &cobra.Command{
+		Use:       "respond <event-id> <accepted|declined|tentative>",
+		Short:     "Respond to an event invitation",
+		Long:      "Accept, decline or tentatively accept an invitation as the authenticated user, changing only your own response",
+		Example:   examples.EventsRespondExamples,
+		Args:      cobra.ExactArgs(2),
+		ValidArgs: calendar.ResponseStatuses,
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := cmd.Context()
+
+			// Get calendar client
+			client, err := getCalendarClient(ctx)
+			if err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+
+			// Respond to event
+			event, err := client.RespondToEvent(ctx, args[0], calendar.RespondParams{
+				Response: args[1],
+				Comment:  comment,
+				Scope:    scope,
+			})
+			if err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+
+			// Output success
+			response := types.SuccessResponse("respond", map[string]interface{}{
+				"event":    event,
+				"response": args[1],
+				"message":  fmt.Sprintf("Invitation %s", args[1]),
+			})
+			output, err := formatter.Format(response)
+			if err != nil {
+				outputFormatError(cmd, err)
+				return
+			}
+			cmd.Println(output)
+		},
+	}
+
+	cmd.Flags().StringVar(&comment, "comment", "", "Note to the organizer")
+	cmd.Flags().StringVar(&scope, "scope", calendar.RespondScopeThis,
+		"For an instance of a recurring event: answer this instance or all of them (this|all)")
+
+	return cmd
+}
+
+func newEventsInvitesCommand(formatter output.Formatter) *cobra.Command {
+	var (
+		from       string
+		to         string
+		maxResults int64
+		query      string
+		organizer  string
+		respond    string
+		comment    string
+		scope      string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "invites",
+		Short: "List or answer pending invitations",
+		Long: `List events in a date range that you have not responded to yet. With --respond,
+answer every invitation matched by the filters (--query, --organizer) at once.`,
+		Example: examples.EventsInvitesExamples,
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := cmd.Context()
+
+			// Get calendar client
+			client, err := getCalendarClient(ctx)
+			if err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+
+			// Parse range, by default the next 30 days
+			now := time.Now()
+			fromTime := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+			if from != "" {
+				if fromTime, err = parseDate(from); err != nil {
+					outputError(cmd, formatter,
+						types.ErrInvalidInput("from", err.Error()))
+					return
+				}
+			}
+			toTime := fromTime.AddDate(0, 0, 30)
+			if to != "" {
+				if toTime, err = parseDate(to); err != nil {
+					outputError(cmd, formatter,
+						types.ErrInvalidInput("to", err.Error()))
+					return
+				}
+			}
+
+			// List invitations
+			invites, err := client.ListInvites(ctx, calendar.InvitesParams{
+				ListEventsParams: calendar.ListEventsParams{
+					From:       fromTime,
+					To:         toTime,
+					MaxResults: maxResults,
+					Query:      query,
+				},
+				Organizer: organizer,
+			})
+			if err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+
+			var response *types.Response
+			if respond == "" {
+				response = types.SuccessResponse("invites", map[string]interface{}{
+					"events": invites,
+					"count":  len(invites),
+				})
+			} else {
+				// Answer them all
+				results, err := client.RespondToInvites(ctx, invites, calendar.RespondParams{
+					Response: respond,
+					Comment:  comment,
+					Scope:    scope,
+				})
+				if err != nil {
+					outputError(cmd, formatter, err)
+					return
+				}
+				summary := calendar.GetBatchSummary(results)
+				response = types.SuccessResponse("invites", map[string]interface{}{
+					"results":  results,
+					"summary":  summary,
+					"response": respond,
+					"message":  fmt.Sprintf("%d of %d invitation(s) %s", summary["success"], summary["total"], respond),
+				})
+			}
+
+			output, err := formatter.Format(response)
+			if err != nil {
+				outputFormatError(cmd, err)
+				return
+			}
+			cmd.Println(output)
+		},
+	}
+
+	cmd.Flags().StringVar(&from, "from", "", "Start date (YYYY-MM-DD or RFC3339, default today)")
+	cmd.Flags().StringVar(&to, "to", "", "End date (YYYY-MM-DD or RFC3339, default 30 days after --from)")
+	cmd.Flags().Int64Var(&maxResults, "max-results", 250, "Maximum events to search")
+	cmd.Flags().StringVar(&query, "query", "", "Only invitations matching this search query")
+	cmd.Flags().StringVar(&organizer, "organizer", "", "Only invitations from this organizer's email")
+	cmd.Flags().StringVar(&respond, "respond", "", "Answer every matched invitation (accepted|declined|tentative)")
+	cmd.Flags().StringVar(&comment, "comment", "", "Note to the organizers, with --respond")
+	cmd.Flags().StringVar(&scope, "scope", calendar.RespondScopeThis,
+		"With --respond, answer recurring invitations per instance or for all instances (this|all)")
+
+	return cmd
+}
+
+func newEventsOutOfOfficeCommand(formatter output.Formatter) *cobra.Command {
+	var (
+		from           string
+		to             string
+		title          string
+		declineMode    string
+		declineMessage string
+		idempotencyKey string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "ooo",
+		Short: "Mark yourself out of office",
+		Long: `Create an out-of-office event on your primary calendar. Dates cover whole days
+(--to is inclusive); times give an exact range. Conflicting invitations are
+declined according to --decline-mode.`,
+		Example: examples.EventsOutOfOfficeExamples,
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := cmd.Context()
+
+			// Get calendar client
+			client, err := getCalendarClient(ctx)
+			if err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+
+			tz, err := defaultLocation()
+			if err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+
+			// Parse the range; a date for --to includes that whole day
+			startTime, _, err := parseDayOrTime(from, tz)
+			if err != nil {
+				outputError(cmd, formatter, types.ErrInvalidInput("from", err.Error()))
+				return
+			}
+			endTime, dateOnly, err := parseDayOrTime(to, tz)
+			if err != nil {
+				outputError(cmd, formatter, types.ErrInvalidInput("to", err.Error()))
+				return
+			}
+			if dateOnly {
+				endTime = endTime.AddDate(0, 0, 1)
+			}
+
+			// Create event
+			event, err := client.CreateEvent(ctx, calendar.CreateEventParams{
+				Summary:  title,
+				Start:    startTime,
+				End:      endTime,
+				TimeZone: config.GetString("calendar.default_timezone"),
+				OutOfOffice: &types.OutOfOffice{
+					AutoDeclineMode: declineMode,
+					DeclineMessage:  declineMessage,
+				},
+
+				IdempotencyKey: idempotencyKey,
+			})
+			if err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+
+			// Output success
+			response := types.SuccessResponse("ooo", map[string]interface{}{
+				"event":   event,
+				"message": "Out-of-office event created successfully",
+			})
+			output, err := formatter.Format(response)
+			if err != nil {
+				outputFormatError(cmd, err)
+				return
+			}
+			cmd.Println(output)
+		},
+	}
+
+	cmd.Flags().StringVar(&from, "from", "", "First day (YYYY-MM-DD) or start time")
+	cmd.Flags().StringVar(&to, "to", "", "Last day (YYYY-MM-DD, inclusive) or end time")
+	cmd.Flags().StringVar(&title, "title", "Out of office", "Event title")
+	cmd.Flags().StringVar(&declineMode, "decline-mode", "declineOnlyNewConflictingInvitations",
+		"Which conflicting invitations to decline: "+strings.Join(calendar.DeclineModes, "|"))
+	cmd.Flags().StringVar(&declineMessage, "decline-message", "", "Message sent with declined invitations")
+	addIdempotencyKeyFlag(cmd, &idempotencyKey)
+
+	cmd.MarkFlagRequired("from")
+	cmd.MarkFlagRequired("to")
+
+	return cmd
+}
+
+func newEventsFocusCommand(formatter output.Formatter) *cobra.Command {
+	var (
+		start          string
+		end            string
+		title          string
+		chatStatus     string
+		declineMode    string
+		declineMessage string
+		idempotencyKey string
+	)
+
+	cmd := &cobra.Command{
+		Use:     "focus",
+		Short:   "Block focus time",
+		Long:    "Create a focus time event on your primary calendar, optionally declining conflicting invitations and setting your Chat status",
+		Example: examples.EventsFocusExamples,
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := cmd.Context()
+
+			// Get calendar client
+			client, err := getCalendarClient(ctx)
+			if err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+
+			// Parse start and end times
+			startTime, err := parseTime(start)
+			if err != nil {
+				outputError(cmd, formatter, types.ErrInvalidInput("start", err.Error()))
+				return
+			}
+			endTime, err := parseTime(end)
+			if err != nil {
+				outputError(cmd, formatter, types.ErrInvalidInput("end", err.Error()))
+				return
+			}
+
+			// Create event
+			event, err := client.CreateEvent(ctx, calendar.CreateEventParams{
+				Summary:  title,
+				Start:    startTime,
+				End:      endTime,
+				TimeZone: config.GetString("calendar.default_timezone"),
+				FocusTime: &types.FocusTime{
+					AutoDeclineMode: declineMode,
+					DeclineMessage:  declineMessage,
+					ChatStatus:      chatStatus,
+				},
+
+				IdempotencyKey: idempotencyKey,
+			})
+			if err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+
+			// Output success
+			response := types.SuccessResponse("focus", map[string]interface{}{
+				"event":   event,
+				"message": "Focus time created successfully",
+			})
+			output, err := formatter.Format(response)
+			if err != nil {
+				outputFormatError(cmd, err)
+				return
+			}
+			cmd.Println(output)
+		},
+	}
+
+	cmd.Flags().StringVar(&start, "start", "", "Start time (RFC3339 or YYYY-MM-DD HH:MM)")
+	cmd.Flags().StringVar(&end, "end", "", "End time (RFC3339 or YYYY-MM-DD HH:MM)")
+	cmd.Flags().StringVar(&title, "title", "Focus time", "Event title")
+	cmd.Flags().StringVar(&chatStatus, "chat-status", "", "Chat status during the event: "+strings.Join(calendar.ChatStatuses, "|"))
+	cmd.Flags().StringVar(&declineMode, "decline-mode", "declineOnlyNewConflictingInvitations",
+		"Which conflicting invitations to decline: "+strings.Join(calendar.DeclineModes, "|"))
+	cmd.Flags().StringVar(&declineMessage, "decline-message", "", "Message sent with declined invitations")
+	addIdempotencyKeyFlag(cmd, &idempotencyKey)
+
+	cmd.MarkFlagRequired("start")
+	cmd.MarkFlagRequired("end")
+
+	return cmd
+}
+
+func newEventsWorkingLocationCommand(formatter output.Formatter) *cobra.Command {
+	var (
+		home           bool
+		office         bool
+		custom         bool
+		label          string
+		buildingID     string
+		floorID        string
+		deskID         string
+		date           string
+		start          string
+		end            string
+		idempotencyKey string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "working-location",
+		Short: "Set where you are working",
+		Long: `Create a working location event on your primary calendar: at home (--home), in an
+office (--office, optionally with --label and building, floor and desk IDs) or
+somewhere else (--custom --label). It covers a whole day (--date, default today)
+or a part of one (--start and --end).`,
+		Example: examples.EventsWorkingLocationExamples,
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := cmd.Context()
+
+			// Get calendar client
+			client, err := getCalendarClient(ctx)
+			if err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+
+			location := &types.WorkingLocation{
+				Label:      label,
+				BuildingID: buildingID,
+				FloorID:    floorID,
+				DeskID:     deskID,
+			}
+			title := label
+			switch {
+			case home:
+				location.Type = calendar.WorkingLocationHome
+				title = "Home"
+			case office:
+				location.Type = calendar.WorkingLocationOffice
+				if title == "" {
+					title = "Office"
+				}
+			case custom:
+				location.Type = calendar.WorkingLocationCustom
+				if label == "" {
+					outputError(cmd, formatter, types.ErrMissingRequired("label").
+						WithDetails("--custom needs a --label naming the place"))
+					return
+				}
+			}
+
+			params := calendar.CreateEventParams{
+				Summary:         title,
+				TimeZone:        config.GetString("calendar.default_timezone"),
+				WorkingLocation: location,
+
+				IdempotencyKey: idempotencyKey,
+			}
+
+			// Cover part of a day, or the whole of one
+			if start != "" {
+				if params.Start, err = parseTime(start); err != nil {
+					outputError(cmd, formatter, types.ErrInvalidInput("start", err.Error()))
+					return
+				}
+				if params.End, err = parseTime(end); err != nil {
+					outputError(cmd, formatter, types.ErrInvalidInput("end", err.Error()))
+					return
+				}
+			} else {
+				tz, err := defaultLocation()
+				if err != nil {
+					outputError(cmd, formatter, err)
+					return
+				}
+				day := time.Now().In(tz)
+				if date != "" {
+					if day, err = time.ParseInLocation("2006-01-02", date, tz); err != nil {
+						outputError(cmd, formatter,
+							types.ErrInvalidInput("date", fmt.Sprintf("invalid date format: %s (expected YYYY-MM-DD)", date)))
+						return
+					}
+				}
+				params.AllDay = true
+				params.Start = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+				params.End = params.Start.AddDate(0, 0, 1)
+			}
+
+			// Create event
+			event, err := client.CreateEvent(ctx, params)
+			if err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+
+			// Output success
+			response := types.SuccessResponse("working-location", map[string]interface{}{
+				"event":   event,
+				"message": "Working location set successfully",
+			})
+			output, err := formatter.Format(response)
+			if err != nil {
+				outputFormatError(cmd, err)
+				return
+			}
+			cmd.Println(output)
+		},
+	}
+
+	cmd.Flags().BoolVar(&home, "home", false, "Working from home")
+	cmd.Flags().BoolVar(&office, "office", false, "Working from an office")
+	cmd.Flags().BoolVar(&custom, "custom", false, "Working from another place, named by --label")
+	cmd.Flags().StringVar(&label, "label", "", "Name of the office or place")
+	cmd.Flags().StringVar(&buildingID, "building-id", "", "Office building ID (with --office)")
+	cmd.Flags().StringVar(&floorID, "floor-id", "", "Office floor ID (with --office)")
+	cmd.Flags().StringVar(&deskID, "desk-id", "", "Office desk ID (with --office)")
+	cmd.Flags().StringVar(&date, "date", "", "Day to cover (YYYY-MM-DD, default today)")
+	cmd.Flags().StringVar(&start, "start", "", "Start time, to cover part of a day (RFC3339 or YYYY-MM-DD HH:MM)")
+	cmd.Flags().StringVar(&end, "end", "", "End time, to cover part of a day (RFC3339 or YYYY-MM-DD HH:MM)")
+	addIdempotencyKeyFlag(cmd, &idempotencyKey)
+
+	cmd.MarkFlagsOneRequired("home", "office", "custom")
+	cmd.MarkFlagsMutuallyExclusive("home", "office", "custom")
+	cmd.MarkFlagsRequiredTogether("start", "end")
+	cmd.MarkFlagsMutuallyExclusive("date", "start")
+
+	return cmd
+}
+
 // Helper functions
 
+// newAuthManager creates an auth manager for the active profile
+func newAuthManager() (*auth.Manager, error) {
+	return newAuthManagerFor(config.ProfileConfig{
+		CredentialsPath: config.GetString("auth.credentials_path"),
+		TokensPath:      config.GetString("auth.tokens_path"),
+		AuthType:        config.GetString("auth.type"),
+		Impersonate:     config.GetString("auth.impersonate"),
+	})
+}
+
+// newAuthManagerFor creates an auth manager from a profile's settings. For
+// service accounts the credentials path is the JSON key file.
+func newAuthManagerFor(settings config.ProfileConfig) (*auth.Manager, error) {
+	switch settings.AuthType {
+	case "", "oauth":
+		if settings.Impersonate != "" {
+			return nil, types.ErrInvalidInput("impersonate",
+				"impersonation requires auth.type 'service_account'")
+		}
+		manager, err := auth.NewManager(settings.CredentialsPath, settings.TokensPath)
+		if err != nil {
+			return nil, err
+		}
+		if err := configureTokenStorage(manager.Storage); err != nil {
+			return nil, err
+		}
+		manager.RevokeURL = config.GetString("auth.revoke_url")
+		return manager, nil
+	case "service_account":
+		return auth.NewServiceAccountManager(settings.CredentialsPath, settings.Impersonate)
+	default:
+		return nil, types.ErrInvalidInput("auth.type",
+			fmt.Sprintf("unknown auth type '%s' (must be 'oauth' or 'service_account')", settings.AuthType))
+	}
+}
+
+// newBackend creates the configured calendar backend (google or fake)
+func newBackend(ctx context.Context) (calendar.Backend, error) {
+	switch backendType := config.GetString("backend.type"); backendType {
+	case "", "google":
+		endpoint := config.GetString("api.endpoint")
+
+		authMode := config.GetString("auth.mode")
+		if authMode == "none" {
+			// Local stand-ins accept requests without credentials
+			service, err := auth.NewUnauthenticatedService(ctx, endpoint)
+			if err != nil {
+				return nil, err
+			}
+			return limitBackend(calendar.NewGoogleBackend(service)), nil
+		}
+		if authMode != "" && authMode != "oauth" {
+			return nil, types.ErrInvalidInput("auth.mode",
+				fmt.Sprintf("unknown auth mode '%s' (must be 'oauth' or 'none')", authMode))
+		}
+
+		// Create auth manager
+		manager, err := newAuthManager()
+		if err != nil {
+			return nil, err
+		}
+		manager.Endpoint = endpoint
+
+		// Get Calendar service
+		service, err := manager.GetCalendarService(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		// Refuse operations the granted scopes cannot cover before sending them
+		scopes, err := manager.Scopes()
+		if err != nil {
+			return nil, err
+		}
+		return calendar.NewScopedBackend(limitBackend(calendar.NewGoogleBackend(service)), scopes), nil
+
+	case "fake":
+		seedPath := config.GetString("backend.fake_seed")
+		if seedPath == "" {
+			if config.GetBool("backend.fake_persist") {
+				return nil, types.ErrMissingRequired("fake-seed").
+					WithSuggestedAction("Provide --fake-seed <file> to persist fake backend changes")
+			}
+			return calendar.NewFakeBackend(), nil
+		}
+
+		fake, err := calendar.LoadFakeBackend(seedPath)
+		if err != nil {
+			return nil, err
+		}
+		if config.GetBool("backend.fake_persist") {
+			fake.PersistTo(seedPath)
+		}
+		return fake, nil
+
+	default:
+		return nil, types.ErrInvalidInput("backend",
+			fmt.Sprintf("unknown backend '%s' (must be 'google' or 'fake')", backendType))
+	}
+}
+
+// limitBackend paces Google API requests to api.rate_limit_buffer of the
+// per-user quota and bounds each one by api.timeout_seconds
+func limitBackend(backend calendar.Backend) calendar.Backend {
+	perSecond := calendar.GoogleRequestsPerSecond * config.GetFloat64("api.rate_limit_buffer")
+	limiter := calendar.NewRateLimiter(perSecond, int(perSecond))
+	timeout := time.Duration(config.GetInt("api.timeout_seconds")) * time.Second
+	return calendar.NewLimitedBackend(backend, limiter, timeout)
+}
+
+// configureRetries applies the api.retry_* settings to a client
+func configureRetries(client *calendar.Client) {
+	client.MaxRetries = max(config.GetInt("api.retry_attempts"), 0)
+	client.RetryDelay = time.Duration(config.GetInt("api.retry_delay_ms")) * time.Millisecond
+	client.MaxRetryDelay = time.Duration(config.GetInt("api.retry_max_delay_ms")) * time.Millisecond
+}
+
 // getCalendarClient creates an authenticated calendar client
 func getCalendarClient(ctx context.Context) (*calendar.Client, error) {
-	credentialsPath := config.GetString("auth.credentials_path")
-	tokenPath := config.GetString("auth.token_path")
-
-	// Create auth manager
-	manager, err := auth.NewManager(credentialsPath, tokenPath)
-	if err != nil {
-		return nil, err
+	calendarID := config.GetString("calendar.default_calendar_id")
+	eventCache := newEventCache()
+
+	// Offline clients never create a backend, so no credentials are needed
+	if config.GetBool("cache.offline") {
+		if eventCache == nil {
+			return nil, types.ErrConfigError("offline mode requires the event cache").
+				WithDetails("the cache is disabled or the fake backend is selected").
+				WithSuggestedAction("Run 'gcal-cli config set cache.enabled true' and use the google backend")
+		}
+		client := calendar.NewClientWithBackend(calendar.NewOfflineBackend(), calendarID)
+		client.Cache = eventCache
+		client.Offline = true
+		if config.GetBool("queue.on_failure") {
+			client.Queue = openWriteQueue()
+		}
+		return client, nil
 	}
 
-	// Get Calendar service
-	service, err := manager.GetCalendarService(ctx)
+	backend, err := newBackend(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	// Create calendar client
-	calendarID := config.GetString("calendar.default_calendar_id")
-	return calendar.NewClient(service, calendarID), nil
+	client := calendar.NewClientWithBackend(backend, calendarID)
+	configureRetries(client)
+	client.Cache = eventCache
+	if config.GetBool("queue.on_failure") {
+		client.Queue = openWriteQueue()
+	}
+	return client, nil
+}
+
+// newEventCache returns the event cache, or nil when caching is disabled or
+// the fake backend is selected (it is already local)
+func newEventCache() *cache.Cache {
+	if !config.GetBool("cache.enabled") || config.GetString("backend.type") == "fake" {
+		return nil
+	}
+
+	dir := eventCacheDir()
+	if dir == "" {
+		return nil
+	}
+
+	ttl := time.Duration(config.GetInt("cache.ttl_seconds")) * time.Second
+	return cache.New(dir, ttl)
+}
+
+// eventCacheDir returns the cache directory for the active profile and API
+// endpoint, so one account's or endpoint's results never answer reads meant
+// for another
+func eventCacheDir() string {
+	dir := config.GetString("cache.dir")
+	if dir == "" {
+		configDir, err := config.GetConfigDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(configDir, "cache")
+	}
+
+	if profile := config.ActiveProfile(); profile != config.DefaultProfile {
+		dir = filepath.Join(dir, "profile-"+profile)
+	}
+
+	if endpoint := config.GetString("api.endpoint"); endpoint != "" {
+		sum := sha256.Sum256([]byte(endpoint))
+		return filepath.Join(dir, "endpoint-"+hex.EncodeToString(sum[:4]))
+	}
+	return filepath.Join(dir, "google")
 }
 
 // parseTime parses a time string in various formats
@@ -481,15 +1447,64 @@ func parseDate(dateStr string) (time.Time, error) {
 	return time.Time{}, fmt.Errorf("invalid date format: %s (expected YYYY-MM-DD or RFC3339)", dateStr)
 }
 
-// outputError outputs an error response
+// defaultLocation returns the calendar.default_timezone location, or the
+// local one when it is not set
+func defaultLocation() (*time.Location, error) {
+	name := config.GetString("calendar.default_timezone")
+	if name == "" {
+		return time.Local, nil
+	}
+
+	tz, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, types.ErrConfigError("invalid calendar.default_timezone").WithWrappedError(err)
+	}
+	return tz, nil
+}
+
+// parseDayOrTime parses a date, as midnight in tz, or a time as parseTime
+// does. dateOnly reports which it was.
+func parseDayOrTime(value string, tz *time.Location) (t time.Time, dateOnly bool, err error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, tz); err == nil {
+		return t, true, nil
+	}
+	t, err = parseTime(value)
+	return t, false, err
+}
+
+// exitCode is the process exit code of the command that ran, set by
+// outputError from the error it reports
+var exitCode = types.ExitOK
+
+// ExitCode returns the exit code for the command that ran: ExitOK, or the
+// code for the class of error it reported
+func ExitCode() int {
+	return exitCode
+}
+
+// outputError outputs an error response and records the matching exit code.
+// With output.errors_to_stderr set, the response goes to stderr so stdout
+// only ever carries successful results.
 func outputError(cmd *cobra.Command, formatter output.Formatter, err error) {
-	appErr, ok := err.(*types.AppError)
-	if !ok {
-		appErr = types.ErrAPIError.
+	var appErr *types.AppError
+	if !errors.As(err, &appErr) {
+		appErr = types.NewAppError(types.ErrCodeAPIError, "API operation failed", true).
 			WithDetails("operation failed").
 			WithWrappedError(err)
 	}
+	exitCode = types.ExitCode(err)
+
 	response := types.ErrorResponse(appErr)
 	output, _ := formatter.Format(response)
+	if config.GetBool("output.errors_to_stderr") {
+		cmd.PrintErrln(output)
+		return
+	}
 	cmd.Println(output)
 }
+
+// outputFormatError reports a response that could not be formatted
+func outputFormatError(cmd *cobra.Command, err error) {
+	exitCode = types.ExitError
+	cmd.PrintErrf("Error formatting output: %v\n", err)
+}
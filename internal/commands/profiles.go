@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/btafoya/gcal-cli/pkg/config"
+	"github.com/btafoya/gcal-cli/pkg/examples"
+	"github.com/btafoya/gcal-cli/pkg/output"
+	"github.com/btafoya/gcal-cli/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// NewProfilesCommand creates the profiles command group
+func NewProfilesCommand(formatter output.Formatter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profiles",
+		Short: "Manage account profiles",
+		Long: `Manage named profiles, each with its own credentials, token, default calendar
+and timezone. Create a profile with 'gcal-cli auth login --profile <name>' and
+select it per command with --profile or GCAL_PROFILE.`,
+	}
+
+	cmd.AddCommand(newProfilesListCommand(formatter))
+	cmd.AddCommand(newProfilesUseCommand(formatter))
+	cmd.AddCommand(newProfilesDeleteCommand(formatter))
+
+	return cmd
+}
+
+func newProfilesListCommand(formatter output.Formatter) *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Short:   "List profiles",
+		Long:    "List the default profile and every named profile with their effective settings",
+		Example: examples.ProfilesListExamples,
+		Run: func(cmd *cobra.Command, args []string) {
+			names, err := config.ProfileNames()
+			if err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+
+			profiles := make([]map[string]interface{}, 0, len(names))
+			for _, name := range names {
+				settings := config.ResolveProfile(name)
+				_, statErr := os.Stat(settings.TokensPath)
+				profiles = append(profiles, map[string]interface{}{
+					"name":              name,
+					"active":            name == config.ActiveProfile(),
+					"credentialsPath":   settings.CredentialsPath,
+					"tokensPath":        settings.TokensPath,
+					"defaultCalendarId": settings.DefaultCalendarID,
+					"defaultTimezone":   settings.DefaultTimezone,
+					"authType":          settings.AuthType,
+					"impersonate":       settings.Impersonate,
+					"loggedIn":          statErr == nil,
+				})
+			}
+
+			response := types.SuccessResponse("profiles_list", map[string]interface{}{
+				"profiles": profiles,
+				"active":   config.ActiveProfile(),
+				"count":    len(profiles),
+			})
+			output, err := formatter.Format(response)
+			if err != nil {
+				outputFormatError(cmd, err)
+				return
+			}
+			cmd.Println(output)
+		},
+	}
+}
+
+func newProfilesUseCommand(formatter output.Formatter) *cobra.Command {
+	return &cobra.Command{
+		Use:     "use <name>",
+		Short:   "Select the profile used by default",
+		Long:    "Save the profile used when neither --profile nor GCAL_PROFILE is given ('default' selects the top-level settings)",
+		Example: examples.ProfilesUseExamples,
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			name := args[0]
+
+			if err := config.UseProfile(name); err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+
+			response := types.SuccessResponse("profiles_use", map[string]interface{}{
+				"message": "Default profile updated",
+				"profile": name,
+			})
+			output, err := formatter.Format(response)
+			if err != nil {
+				outputFormatError(cmd, err)
+				return
+			}
+			cmd.Println(output)
+		},
+	}
+}
+
+func newProfilesDeleteCommand(formatter output.Formatter) *cobra.Command {
+	return &cobra.Command{
+		Use:     "delete <name>",
+		Short:   "Delete a profile",
+		Long:    "Remove a profile from the configuration along with its profile directory (token and queued writes)",
+		Example: examples.ProfilesDeleteExamples,
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			name := args[0]
+			settings := config.ResolveProfile(name)
+
+			if err := config.DeleteProfile(name); err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+
+			// The profile directory is managed by gcal-cli; token files
+			// configured elsewhere are left alone
+			profileDir := config.ProfileDir(name)
+			if err := os.RemoveAll(profileDir); err != nil {
+				outputError(cmd, formatter, types.NewAppError(types.ErrCodeFileError, "file operation failed", false).
+					WithDetails("profile deleted but its directory could not be removed: "+profileDir).
+					WithWrappedError(err))
+				return
+			}
+			tokenRemoved := filepath.Dir(settings.TokensPath) == profileDir
+
+			response := types.SuccessResponse("profiles_delete", map[string]interface{}{
+				"message":      "Profile deleted",
+				"profile":      name,
+				"tokenRemoved": tokenRemoved,
+			})
+			output, err := formatter.Format(response)
+			if err != nil {
+				outputFormatError(cmd, err)
+				return
+			}
+			cmd.Println(output)
+		},
+	}
+}
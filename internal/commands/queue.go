@@ -0,0 +1,160 @@
+package commands
+
+import (
+	"path/filepath"
+
+	"github.com/btafoya/gcal-cli/pkg/calendar"
+	"github.com/btafoya/gcal-cli/pkg/config"
+	"github.com/btafoya/gcal-cli/pkg/examples"
+	"github.com/btafoya/gcal-cli/pkg/output"
+	"github.com/btafoya/gcal-cli/pkg/queue"
+	"github.com/btafoya/gcal-cli/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// NewQueueCommand creates the queue command group
+func NewQueueCommand(formatter output.Formatter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "queue",
+		Short: "Manage queued writes",
+		Long:  "List, replay, and drop creates, updates and deletes queued by --queue-on-failure",
+	}
+
+	cmd.AddCommand(newQueueListCommand(formatter))
+	cmd.AddCommand(newQueueReplayCommand(formatter))
+	cmd.AddCommand(newQueueDropCommand(formatter))
+
+	return cmd
+}
+
+func newQueueListCommand(formatter output.Formatter) *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Short:   "List queued writes",
+		Long:    "List queued writes in the order they will be replayed",
+		Example: examples.QueueListExamples,
+		Run: func(cmd *cobra.Command, args []string) {
+			entries, err := openWriteQueue().List()
+			if err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+
+			response := types.SuccessResponse("queue_list", map[string]interface{}{
+				"entries": entries,
+				"count":   len(entries),
+			})
+			output, err := formatter.Format(response)
+			if err != nil {
+				outputFormatError(cmd, err)
+				return
+			}
+			cmd.Println(output)
+		},
+	}
+}
+
+func newQueueReplayCommand(formatter output.Formatter) *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "replay",
+		Short: "Replay queued writes",
+		Long: `Replay queued writes in order. Writes that succeed are removed from the queue;
+failed writes and writes whose event changed since they were queued (CONFLICT)
+stay queued. Replay stops at the first network error.`,
+		Example: examples.QueueReplayExamples,
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := cmd.Context()
+
+			// Get calendar client
+			client, err := getCalendarClient(ctx)
+			if err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+
+			journal := openWriteQueue()
+			results, err := journal.Replay(ctx, client, force)
+			if err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+
+			remaining, err := journal.List()
+			if err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+
+			response := types.SuccessResponse("queue_replay", map[string]interface{}{
+				"results":   results,
+				"summary":   calendar.GetBatchSummary(results),
+				"remaining": len(remaining),
+			})
+			output, err := formatter.Format(response)
+			if err != nil {
+				outputFormatError(cmd, err)
+				return
+			}
+			cmd.Println(output)
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "replay updates and deletes even if the event changed since they were queued")
+
+	return cmd
+}
+
+func newQueueDropCommand(formatter output.Formatter) *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:     "drop [queue-id...]",
+		Short:   "Remove queued writes",
+		Long:    "Remove queued writes without replaying them",
+		Example: examples.QueueDropExamples,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) == 0 && !all {
+				outputError(cmd, formatter, types.ErrMissingRequired("queue-id").
+					WithSuggestedAction("Provide queue IDs from 'gcal-cli queue list' or use --all"))
+				return
+			}
+
+			removed, err := openWriteQueue().Drop(args...)
+			if err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+
+			response := types.SuccessResponse("queue_drop", map[string]interface{}{
+				"removed": removed,
+			})
+			output, err := formatter.Format(response)
+			if err != nil {
+				outputFormatError(cmd, err)
+				return
+			}
+			cmd.Println(output)
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "remove every queued write")
+
+	return cmd
+}
+
+// openWriteQueue opens the write queue journal. Named profiles keep their
+// own journal so writes are always replayed against the right account.
+func openWriteQueue() *queue.Journal {
+	if profile := config.ActiveProfile(); profile != config.DefaultProfile {
+		return queue.Open(filepath.Join(config.ProfileDir(profile), "queue.json"))
+	}
+
+	path := config.GetString("queue.path")
+	if path == "" {
+		configDir, _ := config.GetConfigDir()
+		path = filepath.Join(configDir, "queue.json")
+	}
+	return queue.Open(path)
+}
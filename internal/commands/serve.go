@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/btafoya/gcal-cli/pkg/config"
+	"github.com/btafoya/gcal-cli/pkg/examples"
+	"github.com/btafoya/gcal-cli/pkg/output"
+	"github.com/btafoya/gcal-cli/pkg/server"
+	"github.com/btafoya/gcal-cli/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// NewServeCommand creates the serve command
+func NewServeCommand(formatter output.Formatter) *cobra.Command {
+	var listen string
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run a local REST API server",
+		Long: `Run a long-lived HTTP server exposing calendar operations as REST endpoints
+(/events, /events/{id}, /freebusy, /calendars, /batch). Responses use the same
+JSON envelopes as the CLI. Requests must carry the bearer token configured in
+server.auth_token. The server shuts down gracefully on SIGINT or SIGTERM.`,
+		Example: examples.ServeExamples,
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			if listen == "" {
+				listen = config.GetString("server.listen")
+			}
+
+			// A single backend is shared by all requests so token refreshes are reused
+			srv := server.New(newBackend, server.Config{
+				Addr:            listen,
+				AuthToken:       config.GetString("server.auth_token"),
+				CalendarID:      config.GetString("calendar.default_calendar_id"),
+				ShutdownTimeout: time.Duration(config.GetInt("server.shutdown_timeout_seconds")) * time.Second,
+				ConfigureClient: configureRetries,
+			})
+
+			cmd.PrintErrf("Serving gcal-cli REST API on http://%s (Ctrl+C to stop)\n", listen)
+
+			if err := srv.ListenAndServe(ctx); err != nil {
+				outputError(cmd, formatter, err)
+				return
+			}
+
+			response := types.SuccessResponse("serve", map[string]interface{}{
+				"message": "Server stopped",
+				"listen":  listen,
+			})
+			output, err := formatter.Format(response)
+			if err != nil {
+				outputFormatError(cmd, err)
+				return
+			}
+			cmd.Println(output)
+		},
+	}
+
+	cmd.Flags().StringVar(&listen, "listen", "", "Address to listen on (default: server.listen, 127.0.0.1:8787)")
+
+	return cmd
+}
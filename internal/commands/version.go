@@ -31,7 +31,7 @@ func NewVersionCommand(formatter output.Formatter) *cobra.Command {
 			response := types.SuccessResponse("version", versionData)
 			output, err := formatter.Format(response)
 			if err != nil {
-				cmd.PrintErrf("Error formatting output: %v\n", err)
+				outputFormatError(cmd, err)
 				return
 			}
 			cmd.Println(output)
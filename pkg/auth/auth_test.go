@@ -1,15 +1,27 @@
 package auth
 
 import (
+	"bufio"
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
 	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/btafoya/gcal-cli/pkg/types"
 	"golang.org/x/oauth2"
 )
 
@@ -107,7 +119,7 @@ func TestStartAuthFlow(t *testing.T) {
 		t.Fatalf("NewOAuthConfig failed: %v", err)
 	}
 
-	authURL, state := config.StartAuthFlow()
+	authURL, state, verifier := config.StartAuthFlow()
 
 	if authURL == "" {
 		t.Error("Expected non-empty auth URL")
@@ -121,6 +133,22 @@ func TestStartAuthFlow(t *testing.T) {
 	if len(state) < 10 {
 		t.Errorf("State too short, expected at least 10 chars, got %d", len(state))
 	}
+
+	// Verify PKCE challenge
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("Failed to parse auth URL: %v", err)
+	}
+	query := parsed.Query()
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") != oauth2.S256ChallengeFromVerifier(verifier) {
+		t.Errorf("Expected S256 challenge for the verifier, got %v", query)
+	}
+
+	// Every flow gets a fresh state and verifier
+	_, state2, verifier2 := config.StartAuthFlow()
+	if state2 == state || verifier2 == verifier {
+		t.Error("Expected random state and verifier per flow")
+	}
 }
 
 // TestValidateToken tests token validation
@@ -211,6 +239,71 @@ func TestTokenStorage_LoadNonexistent(t *testing.T) {
 	}
 }
 
+// TestTokenStorage_Encrypted tests encrypted token storage
+func TestTokenStorage_Encrypted(t *testing.T) {
+	tokenPath := filepath.Join(t.TempDir(), "token.json")
+	testToken := createTestToken()
+
+	// A plaintext token is read by an encrypted storage, then migrated
+	if err := NewTokenStorage(tokenPath).SaveToken(testToken); err != nil {
+		t.Fatalf("SaveToken failed: %v", err)
+	}
+
+	prompts := 0
+	storage := NewTokenStorage(tokenPath)
+	storage.Encrypted = true
+	storage.Passphrase = func() (string, error) {
+		prompts++
+		return "correct horse battery staple", nil
+	}
+
+	loaded, err := storage.LoadToken()
+	if err != nil || loaded.RefreshToken != testToken.RefreshToken {
+		t.Fatalf("LoadToken of plaintext token = %v, %v", loaded, err)
+	}
+	if storage.IsEncrypted() {
+		t.Fatal("Expected token to stay plaintext until saved")
+	}
+
+	if err := storage.SaveToken(loaded); err != nil {
+		t.Fatalf("SaveToken failed: %v", err)
+	}
+	data, _ := os.ReadFile(tokenPath)
+	if !storage.IsEncrypted() || strings.Contains(string(data), testToken.RefreshToken) {
+		t.Fatalf("Expected ciphertext on disk, got %s", data)
+	}
+
+	// Permission checks apply to the ciphertext
+	if err := storage.ValidateTokenPermissions(); err != nil {
+		t.Errorf("ValidateTokenPermissions failed: %v", err)
+	}
+	os.Chmod(tokenPath, 0644)
+	if err := storage.ValidateTokenPermissions(); err == nil {
+		t.Error("Expected insecure permissions error for ciphertext")
+	}
+	os.Chmod(tokenPath, 0600)
+
+	loaded, err = storage.LoadToken()
+	if err != nil || loaded.AccessToken != testToken.AccessToken {
+		t.Fatalf("LoadToken of encrypted token = %v, %v", loaded, err)
+	}
+	if prompts != 1 {
+		t.Errorf("Expected passphrase to be asked for once, got %d", prompts)
+	}
+
+	// A plaintext storage still reads encrypted files with a passphrase
+	wrong := NewTokenStorage(tokenPath)
+	wrong.Passphrase = func() (string, error) { return "wrong passphrase", nil }
+	_, err = wrong.LoadToken()
+	if appErr, ok := err.(*types.AppError); !ok || appErr.Code != types.ErrCodeAuthFailed {
+		t.Errorf("Expected AUTH_FAILED for wrong passphrase, got %v", err)
+	}
+
+	if _, err := NewTokenStorage(tokenPath).LoadToken(); err == nil {
+		t.Error("Expected error when no passphrase is available")
+	}
+}
+
 // TestCallbackServer tests callback server operations
 func TestCallbackServer(t *testing.T) {
 	server := NewCallbackServer(0, "test-state") // port 0 = random port
@@ -331,6 +424,121 @@ func TestCallbackServer_Timeout(t *testing.T) {
 	}
 }
 
+// TestCallbackServer_PortInUse tests that a busy port is reported by Start
+func TestCallbackServer_PortInUse(t *testing.T) {
+	ctx := context.Background()
+
+	first := NewCallbackServer(0, "test-state")
+	if err := first.Start(ctx); err != nil {
+		t.Fatalf("Server.Start failed: %v", err)
+	}
+	defer first.Shutdown(ctx)
+
+	if first.Port == 0 {
+		t.Fatal("Expected the ephemeral port to be recorded")
+	}
+
+	second := NewCallbackServer(first.Port, "test-state")
+	err := second.Start(ctx)
+	if appErr, ok := err.(*types.AppError); !ok || appErr.Code != types.ErrCodeNetworkError {
+		t.Fatalf("Expected NETWORK_ERROR for busy port, got %v", err)
+	}
+}
+
+// TestManager_Login tests the browser flow with PKCE and port fallback
+func TestManager_Login(t *testing.T) {
+	ctx := context.Background()
+
+	// Occupy the default port
+	busy := NewCallbackServer(0, "")
+	if err := busy.Start(ctx); err != nil {
+		t.Fatalf("Server.Start failed: %v", err)
+	}
+	defer busy.Shutdown(ctx)
+	defaultPort := defaultCallbackPort
+	defaultCallbackPort = busy.Port
+	defer func() { defaultCallbackPort = defaultPort }()
+
+	var challenge string
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.PostForm.Get("code") != "test-code" || oauth2.S256ChallengeFromVerifier(r.PostForm.Get("code_verifier")) != challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": "invalid_grant"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token": "pkce-access-token", "refresh_token": "pkce-refresh-token", "token_type": "Bearer", "expires_in": 3600}`))
+	}))
+	defer tokenServer.Close()
+
+	manager, err := NewManager(createTestCredentials(t), filepath.Join(t.TempDir(), "tokens.json"))
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	manager.OAuth.Config.Endpoint.TokenURL = tokenServer.URL
+	manager.OAuth.Config.Endpoint.AuthStyle = oauth2.AuthStyleInParams
+
+	reader, writer := io.Pipe()
+	type result struct {
+		token *oauth2.Token
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		token, err := manager.Login(ctx, LoginOptions{NoBrowser: true, Out: writer})
+		writer.Close()
+		done <- result{token, err}
+	}()
+
+	// Read instructions until the authorization URL is printed
+	var authURL *url.URL
+	scanner := bufio.NewScanner(reader)
+	for authURL == nil && scanner.Scan() {
+		if strings.HasPrefix(scanner.Text(), "http") {
+			authURL, _ = url.Parse(scanner.Text())
+		}
+	}
+	go io.Copy(io.Discard, reader)
+	if authURL == nil {
+		t.Fatal("Expected the authorization URL to be printed")
+	}
+
+	query := authURL.Query()
+	challenge = query.Get("code_challenge")
+	if challenge == "" || query.Get("code_challenge_method") != "S256" {
+		t.Fatalf("Expected a PKCE challenge, got %v", query)
+	}
+	redirect, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || redirect.Port() == "" || redirect.Port() == fmt.Sprint(busy.Port) {
+		t.Fatalf("Expected redirect to a fallback port, got %s", query.Get("redirect_uri"))
+	}
+
+	// Simulate the browser returning from the consent screen
+	resp, err := http.Get(redirect.String() + "?code=test-code&state=" + url.QueryEscape(query.Get("state")))
+	if err != nil {
+		t.Fatalf("Callback request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	res := <-done
+	if res.err != nil {
+		t.Fatalf("Login() error = %v", res.err)
+	}
+	if res.token.AccessToken != "pkce-access-token" {
+		t.Errorf("Expected token from the exchange, got '%s'", res.token.AccessToken)
+	}
+	if !manager.Storage.TokenExists() {
+		t.Error("Expected token to be stored")
+	}
+
+	// A port given explicitly is never replaced
+	_, err = manager.Login(ctx, LoginOptions{Port: busy.Port, NoBrowser: true})
+	if appErr, ok := err.(*types.AppError); !ok || appErr.Code != types.ErrCodeNetworkError {
+		t.Errorf("Expected NETWORK_ERROR for a busy explicit port, got %v", err)
+	}
+}
+
 // TestGetUserInfo tests user info extraction
 func TestGetUserInfo(t *testing.T) {
 	// Test token without email
@@ -380,3 +588,405 @@ func TestParseCredentialsFile_Invalid(t *testing.T) {
 		t.Fatal("Expected error for invalid JSON, got nil")
 	}
 }
+
+// stubTransport answers token and API requests without network access
+type stubTransport struct {
+	requests []*http.Request
+}
+
+func (s *stubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.requests = append(s.requests, req)
+
+	recorder := httptest.NewRecorder()
+	recorder.Header().Set("Content-Type", "application/json")
+	if req.URL.Host == "oauth2.googleapis.com" {
+		recorder.WriteString(`{"access_token": "refreshed-access-token", "token_type": "Bearer", "expires_in": 3600}`)
+	} else {
+		recorder.WriteString(`{}`)
+	}
+
+	return recorder.Result(), nil
+}
+
+// TestOAuthConfig_Transport tests that token refreshes and API calls use the configured transport
+func TestOAuthConfig_Transport(t *testing.T) {
+	credPath := createTestCredentials(t)
+	oauth, err := NewOAuthConfig(credPath, filepath.Join(t.TempDir(), "tokens.json"))
+	if err != nil {
+		t.Fatalf("NewOAuthConfig() error = %v", err)
+	}
+
+	transport := &stubTransport{}
+	oauth.Transport = transport
+
+	ctx := context.Background()
+	refreshed, err := oauth.RefreshToken(ctx, createExpiredToken())
+	if err != nil {
+		t.Fatalf("RefreshToken() error = %v", err)
+	}
+	if refreshed.AccessToken != "refreshed-access-token" {
+		t.Errorf("Expected refreshed token from transport, got '%s'", refreshed.AccessToken)
+	}
+
+	resp, err := oauth.GetClient(ctx, refreshed).Get("https://www.googleapis.com/calendar/v3/users/me/calendarList")
+	if err != nil {
+		t.Fatalf("GetClient() request error = %v", err)
+	}
+	resp.Body.Close()
+
+	if len(transport.requests) != 2 {
+		t.Fatalf("Expected 2 requests through the transport, got %d", len(transport.requests))
+	}
+	if got := transport.requests[1].Header.Get("Authorization"); got != "Bearer refreshed-access-token" {
+		t.Errorf("Expected API request to carry the refreshed token, got '%s'", got)
+	}
+}
+
+// createTestServiceAccountKey creates a temporary service account key file
+func createTestServiceAccountKey(t *testing.T) string {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	data, err := json.Marshal(map[string]interface{}{
+		"type":           "service_account",
+		"project_id":     "test-project",
+		"private_key_id": "test-key-id",
+		"private_key":    string(keyPEM),
+		"client_email":   "agent@test-project.iam.gserviceaccount.com",
+		"client_id":      "1234567890",
+		"token_uri":      "https://oauth2.googleapis.com/token",
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	keyPath := filepath.Join(t.TempDir(), "service-account.json")
+	if err := os.WriteFile(keyPath, data, 0600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+
+	return keyPath
+}
+
+// TestServiceAccountManager tests service account authentication with impersonation
+func TestServiceAccountManager(t *testing.T) {
+	keyPath := createTestServiceAccountKey(t)
+
+	manager, err := NewServiceAccountManager(keyPath, "alice@example.com")
+	if err != nil {
+		t.Fatalf("NewServiceAccountManager() error = %v", err)
+	}
+	transport := &stubTransport{}
+	manager.ServiceAccount.Transport = transport
+
+	if got := manager.Identity(); got != "alice@example.com" {
+		t.Errorf("Identity() = %s, want the impersonated user", got)
+	}
+
+	ctx := context.Background()
+	authenticated, email, expiresAt, err := manager.CheckAuthStatus(ctx)
+	if err != nil {
+		t.Fatalf("CheckAuthStatus() error = %v", err)
+	}
+	if !authenticated || email != "alice@example.com" || expiresAt.IsZero() {
+		t.Errorf("CheckAuthStatus() = %v, %s, %v", authenticated, email, expiresAt)
+	}
+
+	// The signed assertion names the impersonated user as subject
+	if len(transport.requests) != 1 {
+		t.Fatalf("Expected 1 token request, got %d", len(transport.requests))
+	}
+	if err := transport.requests[0].ParseForm(); err != nil {
+		t.Fatalf("ParseForm() error = %v", err)
+	}
+	parts := strings.Split(transport.requests[0].PostForm.Get("assertion"), ".")
+	if len(parts) != 3 {
+		t.Fatalf("Expected a signed JWT assertion, got %d parts", len(parts))
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatalf("Failed to decode assertion: %v", err)
+	}
+	var claims struct {
+		Iss string `json:"iss"`
+		Sub string `json:"sub"`
+	}
+	json.Unmarshal(payload, &claims)
+	if claims.Iss != "agent@test-project.iam.gserviceaccount.com" || claims.Sub != "alice@example.com" {
+		t.Errorf("Unexpected assertion claims: %+v", claims)
+	}
+
+	// There is no stored token to remove
+	if err := manager.Logout(); err != nil {
+		t.Errorf("Logout() error = %v", err)
+	}
+}
+
+// TestNewServiceAccountConfig_InvalidKey tests that OAuth client files are rejected as keys
+func TestNewServiceAccountConfig_InvalidKey(t *testing.T) {
+	credPath := createTestCredentials(t)
+
+	_, err := NewServiceAccountConfig(credPath, "")
+	appErr, ok := err.(*types.AppError)
+	if !ok || appErr.Code != types.ErrCodeInvalidCreds {
+		t.Fatalf("Expected INVALID_CREDENTIALS, got %v", err)
+	}
+
+	if _, err := NewServiceAccountConfig(filepath.Join(t.TempDir(), "missing.json"), ""); err == nil {
+		t.Error("Expected error for missing key file")
+	}
+}
+
+// newDeviceServer serves the device authorization and token endpoints. The
+// token endpoint answers with the given error codes in turn, then a token.
+// Clients must use a fixed auth style, since auto-detection retries failed
+// token requests.
+func newDeviceServer(t *testing.T, tokenErrors ...string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		r.ParseForm()
+
+		switch r.URL.Path {
+		case "/device/code":
+			w.Write([]byte(`{"device_code": "dev-123", "user_code": "ABCD-EFGH", "verification_url": "https://www.google.com/device", "expires_in": 60, "interval": 1}`))
+		case "/token":
+			if r.PostForm.Get("device_code") != "dev-123" {
+				t.Errorf("Expected device code in token request, got %v", r.PostForm)
+			}
+			if len(tokenErrors) > 0 {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error": "` + tokenErrors[0] + `"}`))
+				tokenErrors = tokenErrors[1:]
+				return
+			}
+			w.Write([]byte(`{"access_token": "device-access-token", "refresh_token": "device-refresh-token", "token_type": "Bearer", "expires_in": 3600}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// TestManager_LoginDevice tests the device authorization flow
+func TestManager_LoginDevice(t *testing.T) {
+	credPath := createTestCredentials(t)
+	tokenPath := filepath.Join(t.TempDir(), "tokens.json")
+
+	manager, err := NewManager(credPath, tokenPath)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	server := newDeviceServer(t, "authorization_pending")
+	manager.OAuth.Config.Endpoint.DeviceAuthURL = server.URL + "/device/code"
+	manager.OAuth.Config.Endpoint.TokenURL = server.URL + "/token"
+	manager.OAuth.Config.Endpoint.AuthStyle = oauth2.AuthStyleInParams
+
+	var prompt strings.Builder
+	token, err := manager.LoginDevice(context.Background(), LoginOptions{Out: &prompt})
+	if err != nil {
+		t.Fatalf("LoginDevice() error = %v", err)
+	}
+	if token.AccessToken != "device-access-token" {
+		t.Errorf("Expected device access token, got '%s'", token.AccessToken)
+	}
+	if !strings.Contains(prompt.String(), "https://www.google.com/device") || !strings.Contains(prompt.String(), "ABCD-EFGH") {
+		t.Errorf("Expected verification URL and user code in prompt, got %q", prompt.String())
+	}
+
+	stored, err := manager.Storage.LoadToken()
+	if err != nil {
+		t.Fatalf("LoadToken() error = %v", err)
+	}
+	if stored.RefreshToken != "device-refresh-token" {
+		t.Errorf("Expected token to be stored, got %+v", stored)
+	}
+}
+
+// TestManager_LoginDevice_Denied tests that a denied device code fails the login
+func TestManager_LoginDevice_Denied(t *testing.T) {
+	credPath := createTestCredentials(t)
+	manager, err := NewManager(credPath, filepath.Join(t.TempDir(), "tokens.json"))
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	server := newDeviceServer(t, "access_denied")
+	manager.OAuth.Config.Endpoint.DeviceAuthURL = server.URL + "/device/code"
+	manager.OAuth.Config.Endpoint.TokenURL = server.URL + "/token"
+	manager.OAuth.Config.Endpoint.AuthStyle = oauth2.AuthStyleInParams
+
+	_, err = manager.LoginDevice(context.Background(), LoginOptions{})
+	appErr, ok := err.(*types.AppError)
+	if !ok || appErr.Code != types.ErrCodeAuthFailed || appErr.Message != "authorization was denied" {
+		t.Fatalf("Expected denied AUTH_FAILED, got %v", err)
+	}
+	if manager.Storage.TokenExists() {
+		t.Error("Expected no token to be stored")
+	}
+}
+
+// TestTokenStorage_Scopes tests that granted scopes survive storage and refreshes
+func TestTokenStorage_Scopes(t *testing.T) {
+	storage := NewTokenStorage(filepath.Join(t.TempDir(), "token.json"))
+
+	scopes, err := ScopesForLevel(ScopeLevelEvents)
+	if err != nil {
+		t.Fatalf("ScopesForLevel() error = %v", err)
+	}
+	if err := storage.SaveToken(withScopes(createTestToken(), scopes)); err != nil {
+		t.Fatalf("SaveToken failed: %v", err)
+	}
+
+	loaded, err := storage.LoadToken()
+	if err != nil {
+		t.Fatalf("LoadToken failed: %v", err)
+	}
+	if got := TokenScopes(loaded); len(got) != 2 || got[0] != scopes[0] || got[1] != scopes[1] {
+		t.Errorf("TokenScopes() = %v, want %v", got, scopes)
+	}
+
+	// Refresh responses without a scope keep the previous scopes
+	if got := TokenScopes(keepScopes(loaded, createTestToken())); len(got) != 2 {
+		t.Errorf("Expected scopes to carry over a refresh, got %v", got)
+	}
+
+	// Tokens stored before scopes were tracked have unknown scopes
+	legacy := NewTokenStorage(filepath.Join(t.TempDir(), "legacy.json"))
+	os.WriteFile(legacy.TokenPath, []byte(`{"access_token": "a", "refresh_token": "r"}`), 0600)
+	if loaded, err := legacy.LoadToken(); err != nil || TokenScopes(loaded) != nil {
+		t.Errorf("Expected legacy token without scopes, got %v, %v", loaded, err)
+	}
+
+	if _, err := ScopesForLevel("admin"); err == nil {
+		t.Error("Expected error for unknown scope level")
+	}
+}
+
+// TestManager_Revoke tests token revocation against a stand-in endpoint
+func TestManager_Revoke(t *testing.T) {
+	var revoked []string
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		revoked = append(revoked, r.PostForm.Get("token"))
+		w.WriteHeader(status)
+		if status == http.StatusBadRequest {
+			w.Write([]byte(`{"error": "invalid_token"}`))
+		}
+	}))
+	defer server.Close()
+
+	manager, err := NewManager(createTestCredentials(t), filepath.Join(t.TempDir(), "tokens.json"))
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	manager.RevokeURL = server.URL
+
+	ctx := context.Background()
+	if err := manager.Revoke(ctx); err == nil {
+		t.Error("Expected error when no token is stored")
+	}
+
+	manager.Storage.SaveToken(createTestToken())
+	if err := manager.Revoke(ctx); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if len(revoked) != 1 || revoked[0] != "test-refresh-token" {
+		t.Errorf("Expected the refresh token to be revoked, got %v", revoked)
+	}
+
+	// An already revoked grant is fine
+	status = http.StatusBadRequest
+	if err := manager.Revoke(ctx); err != nil {
+		t.Errorf("Revoke() of invalid token error = %v", err)
+	}
+
+	status = http.StatusInternalServerError
+	if err := manager.Revoke(ctx); err == nil {
+		t.Error("Expected error when revocation fails")
+	}
+}
+
+// TestManager_ConcurrentRefresh tests that processes sharing an expired token
+// refresh it only once
+func TestManager_ConcurrentRefresh(t *testing.T) {
+	var mu sync.Mutex
+	refreshes := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		refreshes++
+		n := refreshes
+		mu.Unlock()
+
+		// A slow token endpoint widens the window for a second refresh
+		time.Sleep(50 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token": "refreshed-%d", "token_type": "Bearer", "expires_in": 3600}`, n)
+	}))
+	defer server.Close()
+
+	credPath := createTestCredentials(t)
+	tokenPath := filepath.Join(t.TempDir(), "tokens.json")
+	if err := NewTokenStorage(tokenPath).SaveToken(createExpiredToken()); err != nil {
+		t.Fatalf("SaveToken() error = %v", err)
+	}
+
+	// Each manager opens the lock file itself, like a separate process would
+	const workers = 8
+	tokens := make([]*oauth2.Token, workers)
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		manager, err := NewManager(credPath, tokenPath)
+		if err != nil {
+			t.Fatalf("NewManager() error = %v", err)
+		}
+		manager.OAuth.Config.Endpoint.TokenURL = server.URL
+		manager.OAuth.Config.Endpoint.AuthStyle = oauth2.AuthStyleInParams
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tokens[i], errs[i] = manager.GetToken(context.Background())
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < workers; i++ {
+		if errs[i] != nil {
+			t.Fatalf("GetToken() error = %v", errs[i])
+		}
+		if tokens[i].AccessToken != "refreshed-1" {
+			t.Errorf("worker %d got %s, want refreshed-1", i, tokens[i].AccessToken)
+		}
+	}
+	if refreshes != 1 {
+		t.Errorf("Expected 1 refresh, got %d", refreshes)
+	}
+
+	stored, err := NewTokenStorage(tokenPath).LoadToken()
+	if err != nil {
+		t.Fatalf("LoadToken() error = %v", err)
+	}
+	if stored.AccessToken != "refreshed-1" || stored.RefreshToken != "test-refresh-token" {
+		t.Errorf("Unexpected stored token: %+v", stored)
+	}
+
+	// Atomic writes leave no temporary files behind
+	entries, _ := os.ReadDir(filepath.Dir(tokenPath))
+	for _, entry := range entries {
+		if strings.Contains(entry.Name(), ".tmp-") {
+			t.Errorf("Leftover temporary file %s", entry.Name())
+		}
+	}
+}
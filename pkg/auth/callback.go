@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"html/template"
+	"net"
 	"net/http"
 	"time"
 
@@ -17,6 +18,9 @@ const (
 	DefaultCallbackPort = 8080
 )
 
+// defaultCallbackPort is the port tried when none is given; tests change it
+var defaultCallbackPort = DefaultCallbackPort
+
 // CallbackServer handles OAuth2 callbacks
 type CallbackServer struct {
 	Port         int
@@ -38,19 +42,30 @@ func NewCallbackServer(port int, state string) *CallbackServer {
 	}
 }
 
-// Start starts the callback server
+// Start starts the callback server. It fails if the port is in use; port 0
+// picks a free ephemeral port, which is then stored in Port.
 func (cs *CallbackServer) Start(ctx context.Context) error {
 	mux := http.NewServeMux()
 	mux.HandleFunc(CallbackPath, cs.handleCallback)
 
+	// Listen before returning so a busy port is reported to the caller
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", cs.Port))
+	if err != nil {
+		return types.ErrNetworkError("callback server failed").
+			WithDetails(fmt.Sprintf("could not listen on port %d", cs.Port)).
+			WithWrappedError(err).
+			WithSuggestedAction("Choose another port with --port")
+	}
+	cs.Port = listener.Addr().(*net.TCPAddr).Port
+
 	cs.Server = &http.Server{
-		Addr:    fmt.Sprintf(":%d", cs.Port),
+		Addr:    listener.Addr().String(),
 		Handler: mux,
 	}
 
 	// Start server in goroutine
 	go func() {
-		if err := cs.Server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := cs.Server.Serve(listener); err != nil && err != http.ErrServerClosed {
 			cs.ErrorChan <- types.ErrNetworkError("callback server failed").
 				WithWrappedError(err)
 		}
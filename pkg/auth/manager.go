@@ -2,9 +2,17 @@ package auth
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
 	"os/exec"
 	"runtime"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"golang.org/x/oauth2"
@@ -17,6 +25,15 @@ import (
 type Manager struct {
 	OAuth   *OAuthConfig
 	Storage *TokenStorage
+
+	// ServiceAccount, if set, replaces the OAuth2 flow and stored token
+	ServiceAccount *ServiceAccountConfig
+
+	// Endpoint overrides the Calendar API base URL, e.g. for a local stand-in
+	Endpoint string
+
+	// RevokeURL overrides the OAuth2 token revocation endpoint
+	RevokeURL string
 }
 
 // NewManager creates a new authentication manager
@@ -34,25 +51,92 @@ func NewManager(credentialsPath, tokenPath string) (*Manager, error) {
 	}, nil
 }
 
-// Login performs the OAuth2 login flow
-func (m *Manager) Login(ctx context.Context) (*oauth2.Token, error) {
-	// Generate auth URL and state
-	authURL, state := m.OAuth.StartAuthFlow()
+// NewServiceAccountManager creates an authentication manager that signs in
+// with a service account key, impersonating subject if it is not empty
+func NewServiceAccountManager(keyPath, subject string) (*Manager, error) {
+	serviceAccount, err := NewServiceAccountConfig(keyPath, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Manager{
+		ServiceAccount: serviceAccount,
+	}, nil
+}
+
+// Identity returns the account API requests act as, if it is known without
+// a network call
+func (m *Manager) Identity() string {
+	if m.ServiceAccount != nil {
+		return m.ServiceAccount.Identity()
+	}
+	return ""
+}
+
+// LoginOptions configures the browser login flow
+type LoginOptions struct {
+	// Port for the local callback server. 0 means DefaultCallbackPort, or a
+	// free ephemeral port if that is busy; a port given explicitly is used
+	// as is, since OAuth clients may be registered with a fixed redirect URI.
+	Port int
+
+	// NoBrowser prints the authorization URL without opening a browser
+	NoBrowser bool
+
+	// Out receives the instructions for the user; nil discards them
+	Out io.Writer
+
+	// Scopes to request; empty means full calendar access
+	Scopes []string
+}
+
+// Login performs the OAuth2 login flow with PKCE. Service accounts have
+// nothing to store, so their key is only checked by obtaining a token.
+func (m *Manager) Login(ctx context.Context, opts LoginOptions) (*oauth2.Token, error) {
+	if m.ServiceAccount != nil {
+		return m.ServiceAccount.Token(ctx)
+	}
+
+	out := opts.out()
+	m.OAuth.requestScopes(opts.Scopes)
 
 	// Start callback server
-	server := NewCallbackServer(DefaultCallbackPort, state)
+	port := opts.Port
+	if port == 0 {
+		port = defaultCallbackPort
+	}
+	server := NewCallbackServer(port, "")
 	if err := server.Start(ctx); err != nil {
-		return nil, err
+		if opts.Port != 0 || !errors.Is(err, syscall.EADDRINUSE) {
+			return nil, err
+		}
+		fmt.Fprintf(out, "Port %d is in use, falling back to a free port\n", port)
+		server = NewCallbackServer(0, "")
+		if err := server.Start(ctx); err != nil {
+			return nil, err
+		}
 	}
 	defer server.Shutdown(ctx)
 
-	// Open browser to auth URL
-	fmt.Printf("Opening browser for authentication...\n")
-	fmt.Printf("If the browser doesn't open automatically, visit:\n%s\n\n", authURL)
+	// Loopback redirects may use any port, so the redirect follows the server
+	m.OAuth.Config.RedirectURL = server.GetCallbackURL()
+
+	// Generate auth URL, state and PKCE verifier; nobody can reach the
+	// callback before the URL is shown, so setting the state now is safe
+	authURL, state, verifier := m.OAuth.StartAuthFlow()
+	server.State = state
 
-	if err := openBrowser(authURL); err != nil {
-		fmt.Printf("Could not open browser automatically: %v\n", err)
-		fmt.Printf("Please open the URL manually in your browser.\n\n")
+	if opts.NoBrowser {
+		fmt.Fprintf(out, "To authenticate, open this URL in a browser on this machine (or forward port %d to it):\n%s\n\n", server.Port, authURL)
+	} else {
+		// Open browser to auth URL
+		fmt.Fprintf(out, "Opening browser for authentication...\n")
+		fmt.Fprintf(out, "If the browser doesn't open automatically, visit:\n%s\n\n", authURL)
+
+		if err := openBrowser(authURL); err != nil {
+			fmt.Fprintf(out, "Could not open browser automatically: %v\n", err)
+			fmt.Fprintf(out, "Please open the URL manually in your browser.\n\n")
+		}
 	}
 
 	// Wait for authorization code (timeout after 5 minutes)
@@ -62,7 +146,43 @@ func (m *Manager) Login(ctx context.Context) (*oauth2.Token, error) {
 	}
 
 	// Exchange code for token
-	token, err := m.OAuth.ExchangeCode(ctx, code)
+	token, err := m.OAuth.ExchangeCode(ctx, code, verifier)
+	if err != nil {
+		return nil, err
+	}
+
+	// Save token
+	if err := m.Storage.SaveToken(token); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// LoginDevice performs the OAuth2 device authorization flow, which needs no
+// browser or callback server on this machine. The verification URL and user
+// code are written to out.
+func (m *Manager) LoginDevice(ctx context.Context, opts LoginOptions) (*oauth2.Token, error) {
+	if m.ServiceAccount != nil {
+		return m.ServiceAccount.Token(ctx)
+	}
+
+	out := opts.out()
+	m.OAuth.requestScopes(opts.Scopes)
+
+	deviceAuth, err := m.OAuth.StartDeviceFlow(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	verificationURL := deviceAuth.VerificationURI
+	if deviceAuth.VerificationURIComplete != "" {
+		verificationURL = deviceAuth.VerificationURIComplete
+	}
+	fmt.Fprintf(out, "To authenticate, visit:\n%s\n\nand enter the code: %s\n\n", verificationURL, deviceAuth.UserCode)
+	fmt.Fprintf(out, "Waiting for approval...\n")
+
+	token, err := m.OAuth.PollDeviceToken(ctx, deviceAuth)
 	if err != nil {
 		return nil, err
 	}
@@ -75,13 +195,105 @@ func (m *Manager) Login(ctx context.Context) (*oauth2.Token, error) {
 	return token, nil
 }
 
+// out returns the writer for user instructions
+func (opts LoginOptions) out() io.Writer {
+	if opts.Out == nil {
+		return io.Discard
+	}
+	return opts.Out
+}
+
+// Scopes returns the scopes granted to the stored credentials, or nil if
+// they are unknown
+func (m *Manager) Scopes() ([]string, error) {
+	if m.ServiceAccount != nil {
+		return m.ServiceAccount.Config.Scopes, nil
+	}
+
+	token, err := m.Storage.LoadToken()
+	if err != nil {
+		return nil, err
+	}
+	return TokenScopes(token), nil
+}
+
+// Revoke revokes the stored grant at the OAuth2 revocation endpoint
+// (RevokeURL, or Google's if empty), so the token stops working everywhere.
+// A grant that is already invalid counts as revoked.
+func (m *Manager) Revoke(ctx context.Context) error {
+	if m.ServiceAccount != nil {
+		return types.ErrInvalidInput("revoke", "service account keys cannot be revoked with a token").
+			WithSuggestedAction("Delete the key in Google Cloud Console")
+	}
+
+	token, err := m.Storage.LoadToken()
+	if err != nil {
+		return err
+	}
+
+	// Revoking the refresh token also revokes its access tokens
+	value := token.RefreshToken
+	if value == "" {
+		value = token.AccessToken
+	}
+
+	revokeURL := m.RevokeURL
+	if revokeURL == "" {
+		revokeURL = DefaultRevokeURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, revokeURL,
+		strings.NewReader(url.Values{"token": {value}}.Encode()))
+	if err != nil {
+		return types.ErrConfigError("invalid revocation endpoint").
+			WithDetails(revokeURL).
+			WithWrappedError(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	client := http.DefaultClient
+	if m.OAuth.Transport != nil {
+		client = &http.Client{Transport: m.OAuth.Transport}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return types.ErrNetworkError("token revocation failed").
+			WithWrappedError(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var body struct {
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	if body.Error == "invalid_token" {
+		return nil
+	}
+
+	return types.ErrAuthFailed("token revocation failed").
+		WithDetails(fmt.Sprintf("HTTP %d: %s %s", resp.StatusCode, body.Error, body.ErrorDescription)).
+		WithSuggestedAction("Revoke access at https://myaccount.google.com/permissions")
+}
+
 // Logout removes stored authentication
 func (m *Manager) Logout() error {
+	if m.ServiceAccount != nil {
+		return nil
+	}
 	return m.Storage.DeleteToken()
 }
 
 // GetToken retrieves the stored token and refreshes it if necessary
 func (m *Manager) GetToken(ctx context.Context) (*oauth2.Token, error) {
+	if m.ServiceAccount != nil {
+		return m.ServiceAccount.Token(ctx)
+	}
+
 	// Load token
 	token, err := m.Storage.LoadToken()
 	if err != nil {
@@ -90,35 +302,110 @@ func (m *Manager) GetToken(ctx context.Context) (*oauth2.Token, error) {
 
 	// Check if token needs refresh
 	if !token.Valid() {
-		// Try to refresh
-		refreshedToken, err := m.OAuth.RefreshToken(ctx, token)
-		if err != nil {
-			return nil, err
-		}
+		return m.refreshStoredToken(ctx)
+	}
 
-		// Save refreshed token
-		if err := m.Storage.SaveToken(refreshedToken); err != nil {
-			return nil, err
-		}
+	return token, nil
+}
 
-		token = refreshedToken
+// refreshStoredToken refreshes the stored token while holding the token
+// lock. The token is read again once the lock is held, so if another process
+// refreshed it in the meantime its token is used instead of refreshing twice.
+func (m *Manager) refreshStoredToken(ctx context.Context) (*oauth2.Token, error) {
+	unlock, err := m.Storage.Lock()
+	if err != nil {
+		return nil, err
 	}
+	defer unlock()
 
-	return token, nil
+	token, err := m.Storage.LoadToken()
+	if err != nil {
+		return nil, err
+	}
+	if token.Valid() {
+		return token, nil
+	}
+
+	refreshedToken, err := m.OAuth.RefreshToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.Storage.SaveToken(refreshedToken); err != nil {
+		return nil, err
+	}
+
+	return refreshedToken, nil
+}
+
+// TokenSource returns a token source that refreshes the stored token when it
+// expires and persists every refreshed token, so long-running processes can
+// share a single Manager
+func (m *Manager) TokenSource(ctx context.Context) (oauth2.TokenSource, error) {
+	if m.ServiceAccount != nil {
+		return m.ServiceAccount.TokenSource(ctx), nil
+	}
+
+	token, err := m.GetToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &persistingTokenSource{
+		ctx:     ctx,
+		manager: m,
+		last:    token,
+	}, nil
 }
 
 // GetCalendarService returns an authenticated Google Calendar service
 func (m *Manager) GetCalendarService(ctx context.Context) (*calendar.Service, error) {
-	token, err := m.GetToken(ctx)
+	tokenSource, err := m.TokenSource(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	client := m.OAuth.GetClient(ctx, token)
+	// API requests use the configured transport, if any
+	client := oauth2.NewClient(m.httpContext(ctx), tokenSource)
+
+	return newCalendarService(ctx, m.Endpoint, option.WithHTTPClient(client))
+}
+
+// httpContext returns a context carrying the transport of the active
+// credentials, if any
+func (m *Manager) httpContext(ctx context.Context) context.Context {
+	if m.ServiceAccount != nil {
+		return m.ServiceAccount.httpContext(ctx)
+	}
+	return m.OAuth.httpContext(ctx)
+}
+
+// NewUnauthenticatedService returns a Calendar service that sends no
+// credentials. It is meant for local stand-ins such as an httptest server or
+// an emulator, so an endpoint is required.
+func NewUnauthenticatedService(ctx context.Context, endpoint string) (*calendar.Service, error) {
+	if endpoint == "" {
+		return nil, types.ErrConfigError("auth mode 'none' requires an API endpoint").
+			WithDetails("api.endpoint").
+			WithSuggestedAction("Set api.endpoint or GCAL_API_ENDPOINT to the local server URL")
+	}
+
+	return newCalendarService(ctx, endpoint, option.WithoutAuthentication())
+}
 
-	service, err := calendar.NewService(ctx, option.WithHTTPClient(client))
+// newCalendarService creates a Calendar service, optionally against a custom endpoint
+func newCalendarService(ctx context.Context, endpoint string, opts ...option.ClientOption) (*calendar.Service, error) {
+	if endpoint != "" {
+		// The endpoint is a base path, so relative API paths need the trailing slash
+		if !strings.HasSuffix(endpoint, "/") {
+			endpoint += "/"
+		}
+		opts = append(opts, option.WithEndpoint(endpoint))
+	}
+
+	service, err := calendar.NewService(ctx, opts...)
 	if err != nil {
-		return nil, types.ErrAPIError.
+		return nil, types.NewAppError(types.ErrCodeAPIError, "API operation failed", true).
 			WithDetails("failed to create calendar service").
 			WithWrappedError(err)
 	}
@@ -128,6 +415,14 @@ func (m *Manager) GetCalendarService(ctx context.Context) (*calendar.Service, er
 
 // CheckAuthStatus checks the current authentication status
 func (m *Manager) CheckAuthStatus(ctx context.Context) (authenticated bool, email string, expiresAt time.Time, err error) {
+	if m.ServiceAccount != nil {
+		token, err := m.ServiceAccount.Token(ctx)
+		if err != nil {
+			return false, m.Identity(), time.Time{}, err
+		}
+		return true, m.Identity(), token.Expiry, nil
+	}
+
 	// Check if token exists
 	if !m.Storage.TokenExists() {
 		return false, "", time.Time{}, nil
@@ -149,16 +444,11 @@ func (m *Manager) CheckAuthStatus(ctx context.Context) (authenticated bool, emai
 		// Token exists but is invalid/expired
 		if appErr, ok := err.(*types.AppError); ok && appErr.Code == types.ErrCodeTokenExpired {
 			// Try to refresh
-			refreshedToken, refreshErr := m.OAuth.RefreshToken(ctx, token)
+			refreshedToken, refreshErr := m.refreshStoredToken(ctx)
 			if refreshErr != nil {
 				return true, "", token.Expiry, refreshErr
 			}
 
-			// Save refreshed token
-			if saveErr := m.Storage.SaveToken(refreshedToken); saveErr != nil {
-				return true, "", token.Expiry, saveErr
-			}
-
 			token = refreshedToken
 		} else {
 			return true, "", token.Expiry, err
@@ -183,6 +473,33 @@ func (m *Manager) CheckAuthStatus(ctx context.Context) (authenticated bool, emai
 	return true, email, token.Expiry, nil
 }
 
+// persistingTokenSource hands out the stored token and refreshes it through
+// the manager, so refreshed tokens are saved under the token lock
+type persistingTokenSource struct {
+	mu      sync.Mutex
+	ctx     context.Context
+	manager *Manager
+	last    *oauth2.Token
+}
+
+// Token returns a valid token, refreshing and saving it if it expired
+func (s *persistingTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.last.Valid() {
+		return s.last, nil
+	}
+
+	token, err := s.manager.refreshStoredToken(s.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.last = token
+	return token, nil
+}
+
 // openBrowser opens the specified URL in the default browser
 func openBrowser(url string) error {
 	var cmd *exec.Cmd
@@ -3,7 +3,7 @@ package auth
 import (
 	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"net/http"
 	"os"
 	"time"
@@ -19,6 +19,10 @@ type OAuthConfig struct {
 	Config          *oauth2.Config
 	CredentialsPath string
 	TokenPath       string
+
+	// Transport, if set, carries all token and API requests. It is used to
+	// record and replay HTTP traffic in tests.
+	Transport http.RoundTripper
 }
 
 // NewOAuthConfig creates a new OAuth configuration from credentials file
@@ -48,39 +52,112 @@ func NewOAuthConfig(credentialsPath, tokenPath string) (*OAuthConfig, error) {
 	}, nil
 }
 
-// GetAuthURL generates the OAuth2 authorization URL
-func (o *OAuthConfig) GetAuthURL(state string) string {
-	return o.Config.AuthCodeURL(state, oauth2.AccessTypeOffline)
+// GetAuthURL generates the OAuth2 authorization URL with a PKCE (S256)
+// challenge derived from verifier
+func (o *OAuthConfig) GetAuthURL(state, verifier string) string {
+	return o.Config.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.S256ChallengeOption(verifier))
 }
 
-// ExchangeCode exchanges an authorization code for tokens
-func (o *OAuthConfig) ExchangeCode(ctx context.Context, code string) (*oauth2.Token, error) {
-	token, err := o.Config.Exchange(ctx, code)
+// ExchangeCode exchanges an authorization code for tokens, proving possession
+// of the PKCE verifier the authorization URL was generated with
+func (o *OAuthConfig) ExchangeCode(ctx context.Context, code, verifier string) (*oauth2.Token, error) {
+	token, err := o.Config.Exchange(o.httpContext(ctx), code, oauth2.VerifierOption(verifier))
 	if err != nil {
 		return nil, types.ErrAuthFailed("failed to exchange authorization code").
 			WithWrappedError(err).
 			WithSuggestedAction("Try the authentication flow again")
 	}
 
-	return token, nil
+	return o.grantedScopes(token), nil
+}
+
+// grantedScopes records the requested scopes on a new token if the token
+// endpoint did not say which scopes it granted
+func (o *OAuthConfig) grantedScopes(token *oauth2.Token) *oauth2.Token {
+	if TokenScopes(token) != nil {
+		return token
+	}
+	return withScopes(token, o.Config.Scopes)
+}
+
+// requestScopes sets the scopes to request in the next authorization; none
+// keeps the scopes the config was created with
+func (o *OAuthConfig) requestScopes(scopes []string) {
+	if len(scopes) > 0 {
+		o.Config.Scopes = scopes
+	}
 }
 
 // GetClient returns an authenticated HTTP client
 func (o *OAuthConfig) GetClient(ctx context.Context, token *oauth2.Token) *http.Client {
-	return o.Config.Client(ctx, token)
+	return o.Config.Client(o.httpContext(ctx), token)
 }
 
 // GetTokenSource returns a token source that automatically refreshes tokens
 func (o *OAuthConfig) GetTokenSource(ctx context.Context, token *oauth2.Token) oauth2.TokenSource {
-	return o.Config.TokenSource(ctx, token)
+	return o.Config.TokenSource(o.httpContext(ctx), token)
 }
 
-// StartAuthFlow initiates the OAuth2 flow and returns the auth URL and state
-func (o *OAuthConfig) StartAuthFlow() (authURL, state string) {
+// httpContext returns a context whose HTTP client uses the configured
+// transport; the oauth2 package reads it for token and API requests
+func (o *OAuthConfig) httpContext(ctx context.Context) context.Context {
+	if o.Transport == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: o.Transport})
+}
+
+// StartAuthFlow initiates the OAuth2 flow and returns the auth URL, the state
+// and the PKCE verifier to pass to ExchangeCode
+func (o *OAuthConfig) StartAuthFlow() (authURL, state, verifier string) {
 	// Generate a random state for CSRF protection
-	state = fmt.Sprintf("state-%d", time.Now().Unix())
-	authURL = o.GetAuthURL(state)
-	return authURL, state
+	state = "state-" + oauth2.GenerateVerifier()
+	verifier = oauth2.GenerateVerifier()
+	authURL = o.GetAuthURL(state, verifier)
+	return authURL, state, verifier
+}
+
+// StartDeviceFlow requests a device and user code for the OAuth 2.0 device
+// authorization grant (RFC 8628)
+func (o *OAuthConfig) StartDeviceFlow(ctx context.Context) (*oauth2.DeviceAuthResponse, error) {
+	// Client files downloaded from Google do not name the device endpoint
+	if o.Config.Endpoint.DeviceAuthURL == "" {
+		o.Config.Endpoint.DeviceAuthURL = google.Endpoint.DeviceAuthURL
+	}
+
+	deviceAuth, err := o.Config.DeviceAuth(o.httpContext(ctx))
+	if err != nil {
+		return nil, types.ErrAuthFailed("failed to start device authorization").
+			WithWrappedError(err).
+			WithSuggestedAction("Use OAuth2 credentials of type 'TVs and Limited Input devices'")
+	}
+
+	return deviceAuth, nil
+}
+
+// PollDeviceToken polls the token endpoint until the user approves or denies
+// the device code or it expires. The polling interval is the one the server
+// asked for, increased by 5 seconds each time it answers slow_down.
+func (o *OAuthConfig) PollDeviceToken(ctx context.Context, deviceAuth *oauth2.DeviceAuthResponse) (*oauth2.Token, error) {
+	token, err := o.Config.DeviceAccessToken(o.httpContext(ctx), deviceAuth)
+	if err == nil {
+		return o.grantedScopes(token), nil
+	}
+
+	if retrieveErr, ok := err.(*oauth2.RetrieveError); ok && retrieveErr.ErrorCode == "access_denied" {
+		return nil, types.ErrAuthFailed("authorization was denied").
+			WithWrappedError(err).
+			WithSuggestedAction("Run 'gcal-cli auth login --device' again and approve access")
+	}
+	if retrieveErr, ok := err.(*oauth2.RetrieveError); (ok && retrieveErr.ErrorCode == "expired_token") || errors.Is(err, context.DeadlineExceeded) {
+		return nil, types.ErrAuthFailed("device code expired before it was approved").
+			WithWrappedError(err).
+			WithSuggestedAction("Run 'gcal-cli auth login --device' again")
+	}
+
+	return nil, types.ErrAuthFailed("failed to complete device authorization").
+		WithWrappedError(err).
+		WithSuggestedAction("Try the authentication flow again")
 }
 
 // ValidateToken checks if a token is valid and not expired
@@ -106,7 +183,7 @@ func (o *OAuthConfig) RefreshToken(ctx context.Context, token *oauth2.Token) (*o
 			WithSuggestedAction("Re-authenticate with 'gcal-cli auth login'")
 	}
 
-	tokenSource := o.Config.TokenSource(ctx, token)
+	tokenSource := o.GetTokenSource(ctx, token)
 	newToken, err := tokenSource.Token()
 	if err != nil {
 		return nil, types.ErrAuthFailed("failed to refresh token").
@@ -114,7 +191,7 @@ func (o *OAuthConfig) RefreshToken(ctx context.Context, token *oauth2.Token) (*o
 			WithSuggestedAction("Re-authenticate with 'gcal-cli auth login'")
 	}
 
-	return newToken, nil
+	return keepScopes(token, newToken), nil
 }
 
 // GetUserInfo retrieves user email from token (if available in token claims)
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/btafoya/gcal-cli/pkg/types"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/calendar/v3"
+)
+
+// Scope levels accepted by 'auth login --scopes'
+const (
+	ScopeLevelReadonly = "readonly"
+	ScopeLevelEvents   = "events"
+	ScopeLevelFull     = "full"
+)
+
+// DefaultRevokeURL is Google's OAuth2 token revocation endpoint
+const DefaultRevokeURL = "https://oauth2.googleapis.com/revoke"
+
+// ScopesForLevel returns the OAuth2 scopes requested for a scope level:
+// readonly reads calendars and events, events also writes events, and full
+// also manages calendars and sharing
+func ScopesForLevel(level string) ([]string, error) {
+	switch level {
+	case ScopeLevelReadonly:
+		return []string{calendar.CalendarReadonlyScope}, nil
+	case ScopeLevelEvents:
+		return []string{calendar.CalendarEventsScope, calendar.CalendarReadonlyScope}, nil
+	case "", ScopeLevelFull:
+		return []string{calendar.CalendarScope}, nil
+	default:
+		return nil, types.ErrInvalidInput("scopes",
+			fmt.Sprintf("unknown scope level '%s' (must be 'readonly', 'events' or 'full')", level))
+	}
+}
+
+// TokenScopes returns the scopes granted to a token, or nil if the token
+// does not record them (tokens stored before scopes were tracked)
+func TokenScopes(token *oauth2.Token) []string {
+	if token == nil {
+		return nil
+	}
+	scope, _ := token.Extra("scope").(string)
+	if scope == "" {
+		return nil
+	}
+	return strings.Fields(scope)
+}
+
+// withScopes returns token with its granted scopes set
+func withScopes(token *oauth2.Token, scopes []string) *oauth2.Token {
+	if len(scopes) == 0 {
+		return token
+	}
+	return token.WithExtra(map[string]interface{}{"scope": strings.Join(scopes, " ")})
+}
+
+// keepScopes carries the scopes of a token over to its refreshed successor
+// when the token endpoint does not repeat them
+func keepScopes(previous, refreshed *oauth2.Token) *oauth2.Token {
+	if TokenScopes(refreshed) != nil {
+		return refreshed
+	}
+	return withScopes(refreshed, TokenScopes(previous))
+}
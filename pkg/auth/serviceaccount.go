@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"os"
+
+	"github.com/btafoya/gcal-cli/pkg/types"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"golang.org/x/oauth2/jwt"
+	"google.golang.org/api/calendar/v3"
+)
+
+// ServiceAccountConfig represents a service account key, optionally acting
+// on behalf of a Workspace user through domain-wide delegation
+type ServiceAccountConfig struct {
+	Config  *jwt.Config
+	KeyPath string
+
+	// Transport, if set, carries all token requests
+	Transport http.RoundTripper
+}
+
+// NewServiceAccountConfig creates a service account configuration from a JSON
+// key file. If subject is not empty, tokens are issued for that user.
+func NewServiceAccountConfig(keyPath, subject string) (*ServiceAccountConfig, error) {
+	keyData, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, types.ErrConfigError("could not read service account key file").
+			WithDetails(keyPath).
+			WithWrappedError(err).
+			WithSuggestedAction("Create a JSON key for the service account in Google Cloud Console")
+	}
+
+	config, err := google.JWTConfigFromJSON(keyData, calendar.CalendarScope)
+	if err != nil {
+		return nil, types.NewAppError(types.ErrCodeInvalidCreds, "Invalid credentials", true).
+			WithDetails("failed to parse service account key JSON").
+			WithWrappedError(err).
+			WithSuggestedAction("Ensure the file is a service account key, not an OAuth2 client configuration")
+	}
+	config.Subject = subject
+
+	return &ServiceAccountConfig{
+		Config:  config,
+		KeyPath: keyPath,
+	}, nil
+}
+
+// Identity returns the account the API requests act as: the impersonated
+// user, or the service account itself
+func (s *ServiceAccountConfig) Identity() string {
+	if s.Config.Subject != "" {
+		return s.Config.Subject
+	}
+	return s.Config.Email
+}
+
+// TokenSource returns a token source that signs a new assertion whenever the
+// current token expires
+func (s *ServiceAccountConfig) TokenSource(ctx context.Context) oauth2.TokenSource {
+	return s.Config.TokenSource(s.httpContext(ctx))
+}
+
+// Token obtains an access token, which verifies the key and, when
+// impersonating, that the service account is allowed to act as the subject
+func (s *ServiceAccountConfig) Token(ctx context.Context) (*oauth2.Token, error) {
+	token, err := s.TokenSource(ctx).Token()
+	if err != nil {
+		action := "Check that the service account key is valid and has not been revoked"
+		if s.Config.Subject != "" {
+			action = "Check that domain-wide delegation is enabled for the service account with the Calendar scope"
+		}
+		return nil, types.ErrAuthFailed("failed to obtain service account token").
+			WithDetails(s.Identity()).
+			WithWrappedError(err).
+			WithSuggestedAction(action)
+	}
+
+	return token, nil
+}
+
+// httpContext returns a context whose HTTP client uses the configured transport
+func (s *ServiceAccountConfig) httpContext(ctx context.Context) context.Context {
+	if s.Transport == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: s.Transport})
+}
@@ -4,14 +4,33 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"golang.org/x/oauth2"
+	"github.com/btafoya/gcal-cli/pkg/filelock"
 	"github.com/btafoya/gcal-cli/pkg/types"
 )
 
 // TokenStorage handles secure storage and retrieval of OAuth2 tokens
 type TokenStorage struct {
 	TokenPath string
+
+	// Encrypted stores tokens as AES-GCM ciphertext under a key derived from
+	// the passphrase. Encrypted files are always recognized when loading.
+	Encrypted bool
+
+	// Passphrase supplies the passphrase for encrypted tokens. It is called
+	// at most once per storage.
+	Passphrase func() (string, error)
+
+	passphrase string
+}
+
+// storedToken is the on-disk form of a token. oauth2.Token does not marshal
+// the granted scopes, so they are stored alongside.
+type storedToken struct {
+	*oauth2.Token
+	Scope string `json:"scope,omitempty"`
 }
 
 // NewTokenStorage creates a new token storage instance
@@ -32,16 +51,30 @@ func (ts *TokenStorage) SaveToken(token *oauth2.Token) error {
 	}
 
 	// Marshal token to JSON
-	data, err := json.MarshalIndent(token, "", "  ")
+	data, err := json.MarshalIndent(storedToken{
+		Token: token,
+		Scope: strings.Join(TokenScopes(token), " "),
+	}, "", "  ")
 	if err != nil {
 		return types.ErrFileError.
 			WithDetails("could not marshal token to JSON").
 			WithWrappedError(err)
 	}
 
-	// Write token to file with restrictive permissions (0600 = rw-------)
-	if err := os.WriteFile(ts.TokenPath, data, 0600); err != nil {
-		return types.ErrFileError.
+	if ts.Encrypted {
+		passphrase, err := ts.getPassphrase()
+		if err != nil {
+			return err
+		}
+		if data, err = encryptToken(data, passphrase); err != nil {
+			return err
+		}
+	}
+
+	// Write to a temporary file and rename it over the token, so readers in
+	// other processes never see a partially written file
+	if err := writeFileAtomic(ts.TokenPath, data); err != nil {
+		return types.NewAppError(types.ErrCodeFileError, "file operation failed", false).
 			WithDetails("could not write token file: " + ts.TokenPath).
 			WithWrappedError(err)
 	}
@@ -49,6 +82,41 @@ func (ts *TokenStorage) SaveToken(token *oauth2.Token) error {
 	return nil
 }
 
+// Lock takes an exclusive lock shared by every process using this token
+// file. Holders may load, refresh and save the token without another
+// process doing the same in between.
+func (ts *TokenStorage) Lock() (unlock func(), err error) {
+	lock, err := filelock.Acquire(ts.TokenPath + ".lock")
+	if err != nil {
+		return nil, err
+	}
+	return func() { lock.Unlock() }, nil
+}
+
+// writeFileAtomic replaces path with data. CreateTemp makes the file
+// readable only by the owner (0600).
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
 // LoadToken loads a token from disk
 func (ts *TokenStorage) LoadToken() (*oauth2.Token, error) {
 	// Check if token file exists
@@ -65,16 +133,55 @@ func (ts *TokenStorage) LoadToken() (*oauth2.Token, error) {
 			WithWrappedError(err)
 	}
 
+	if isEncryptedToken(data) {
+		passphrase, err := ts.getPassphrase()
+		if err != nil {
+			return nil, err
+		}
+		if data, err = decryptToken(data, passphrase); err != nil {
+			return nil, err
+		}
+	}
+
 	// Unmarshal token
-	var token oauth2.Token
-	if err := json.Unmarshal(data, &token); err != nil {
+	stored := storedToken{Token: &oauth2.Token{}}
+	if err := json.Unmarshal(data, &stored); err != nil {
 		return nil, types.ErrFileError.
 			WithDetails("could not parse token file").
 			WithWrappedError(err).
 			WithSuggestedAction("Token file may be corrupted. Try re-authenticating with 'gcal-cli auth login'")
 	}
 
-	return &token, nil
+	return withScopes(stored.Token, strings.Fields(stored.Scope)), nil
+}
+
+// IsEncrypted reports whether the stored token file is encrypted
+func (ts *TokenStorage) IsEncrypted() bool {
+	data, err := os.ReadFile(ts.TokenPath)
+	return err == nil && isEncryptedToken(data)
+}
+
+// getPassphrase returns the passphrase for encrypted tokens
+func (ts *TokenStorage) getPassphrase() (string, error) {
+	if ts.passphrase != "" {
+		return ts.passphrase, nil
+	}
+	if ts.Passphrase == nil {
+		return "", types.ErrConfigError("token passphrase required").
+			WithDetails(ts.TokenPath).
+			WithSuggestedAction("Set GCAL_TOKEN_PASSPHRASE to decrypt the token")
+	}
+
+	passphrase, err := ts.Passphrase()
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", types.ErrInvalidInput("passphrase", "token passphrase must not be empty")
+	}
+
+	ts.passphrase = passphrase
+	return passphrase, nil
 }
 
 // DeleteToken removes the stored token
@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+
+	"github.com/btafoya/gcal-cli/pkg/types"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Key derivation parameters for encrypted tokens (scrypt, 2^15 iterations)
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	tokenKeySize = 32 // AES-256
+)
+
+// encryptedToken is the on-disk form of an encrypted token. The KDF
+// parameters are stored so they can be raised without breaking old files.
+type encryptedToken struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// isEncryptedToken reports whether data holds an encrypted token
+func isEncryptedToken(data []byte) bool {
+	var envelope struct {
+		KDF        string `json:"kdf"`
+		Ciphertext []byte `json:"ciphertext"`
+	}
+	return json.Unmarshal(data, &envelope) == nil && envelope.KDF != "" && len(envelope.Ciphertext) > 0
+}
+
+// encryptToken seals plaintext with AES-GCM under a key derived from passphrase
+func encryptToken(plaintext []byte, passphrase string) ([]byte, error) {
+	envelope := encryptedToken{Version: 1, KDF: "scrypt", N: scryptN, R: scryptR, P: scryptP}
+
+	envelope.Salt = make([]byte, 16)
+	if _, err := rand.Read(envelope.Salt); err != nil {
+		return nil, tokenCryptoError("could not generate salt", err)
+	}
+
+	gcm, err := tokenCipher(passphrase, envelope)
+	if err != nil {
+		return nil, err
+	}
+
+	envelope.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(envelope.Nonce); err != nil {
+		return nil, tokenCryptoError("could not generate nonce", err)
+	}
+	envelope.Ciphertext = gcm.Seal(nil, envelope.Nonce, plaintext, nil)
+
+	return json.MarshalIndent(envelope, "", "  ")
+}
+
+// decryptToken opens an encrypted token
+func decryptToken(data []byte, passphrase string) ([]byte, error) {
+	var envelope encryptedToken
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, tokenCryptoError("could not parse encrypted token", err)
+	}
+	if envelope.KDF != "scrypt" {
+		return nil, tokenCryptoError("unsupported key derivation '"+envelope.KDF+"'", nil)
+	}
+
+	gcm, err := tokenCipher(passphrase, envelope)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := gcm.Open(nil, envelope.Nonce, envelope.Ciphertext, nil)
+	if err != nil {
+		return nil, types.ErrAuthFailed("could not decrypt token").
+			WithDetails("wrong passphrase or corrupted token file").
+			WithSuggestedAction("Check GCAL_TOKEN_PASSPHRASE, or re-authenticate with 'gcal-cli auth login'")
+	}
+
+	return plaintext, nil
+}
+
+// tokenCipher derives the AES-GCM cipher for an envelope's KDF parameters
+func tokenCipher(passphrase string, envelope encryptedToken) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), envelope.Salt, envelope.N, envelope.R, envelope.P, tokenKeySize)
+	if err != nil {
+		return nil, tokenCryptoError("could not derive encryption key", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, tokenCryptoError("could not create cipher", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, tokenCryptoError("could not create cipher", err)
+	}
+
+	return gcm, nil
+}
+
+// tokenCryptoError creates the error for a failed encryption step
+func tokenCryptoError(details string, err error) *types.AppError {
+	appErr := types.NewAppError(types.ErrCodeFileError, "file operation failed", false).
+		WithDetails(details)
+	if err != nil {
+		appErr = appErr.WithWrappedError(err)
+	}
+	return appErr
+}
@@ -0,0 +1,445 @@
+// Package cache stores calendar read results on disk so repeated event
+// queries can be answered without a network round trip, and so the CLI can
+// answer reads while offline.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/btafoya/gcal-cli/pkg/types"
+)
+
+// Entry kinds
+const (
+	KindList  = "list"
+	KindEvent = "event"
+)
+
+// ListKey identifies a cached event listing
+type ListKey struct {
+	CalendarID string
+	From       time.Time
+	To         time.Time
+	Query      string
+	OrderBy    string
+	MaxResults int64
+	// Properties are the extended property and event type filters, e.g.
+	// "private:source=ci" or "eventType:focusTime"
+	Properties []string
+}
+
+// Result is a cached read
+type Result struct {
+	Events   []*types.Event
+	StoredAt time.Time
+	Stale    bool // older than the cache TTL
+}
+
+// Status summarizes the cache contents
+type Status struct {
+	Dir          string     `json:"dir"`
+	TTLSeconds   int        `json:"ttlSeconds"`
+	Entries      int        `json:"entries"`
+	ListEntries  int        `json:"listEntries"`
+	EventEntries int        `json:"eventEntries"`
+	StaleEntries int        `json:"staleEntries"`
+	SizeBytes    int64      `json:"sizeBytes"`
+	Oldest       *time.Time `json:"oldest,omitempty"`
+	Newest       *time.Time `json:"newest,omitempty"`
+}
+
+// entry is the on-disk format of a cached read
+type entry struct {
+	Kind       string         `json:"kind"`
+	CalendarID string         `json:"calendarId"`
+	EventID    string         `json:"eventId,omitempty"`
+	From       time.Time      `json:"from"`
+	To         time.Time      `json:"to"`
+	Query      string         `json:"query,omitempty"`
+	OrderBy    string         `json:"orderBy,omitempty"`
+	MaxResults int64          `json:"maxResults,omitempty"`
+	Properties []string       `json:"properties,omitempty"`
+	StoredAt   time.Time      `json:"storedAt"`
+	Events     []*types.Event `json:"events"`
+}
+
+// Cache is a directory of cached event reads. Each listing and each single
+// event is stored in its own JSON file; entries older than the TTL are
+// reported as stale rather than removed, so offline reads can still use them.
+type Cache struct {
+	dir string
+	ttl time.Duration
+	now func() time.Time
+}
+
+// New creates a cache rooted at dir. The directory is created on first write.
+func New(dir string, ttl time.Duration) *Cache {
+	return &Cache{
+		dir: dir,
+		ttl: ttl,
+		now: time.Now,
+	}
+}
+
+// Dir returns the cache directory
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// TTL returns how long entries are considered fresh
+func (c *Cache) TTL() time.Duration {
+	return c.ttl
+}
+
+// GetList returns a cached listing, fresh or stale
+func (c *Cache) GetList(key ListKey) (*Result, bool) {
+	e, err := c.read(c.path(KindList, listKey(key)))
+	if err != nil {
+		return nil, false
+	}
+
+	return c.result(e, e.Events), true
+}
+
+// PutList stores the events returned for a listing
+func (c *Cache) PutList(key ListKey, events []*types.Event) error {
+	return c.write(c.path(KindList, listKey(key)), &entry{
+		Kind:       KindList,
+		CalendarID: key.CalendarID,
+		From:       key.From.UTC(),
+		To:         key.To.UTC(),
+		Query:      key.Query,
+		OrderBy:    key.OrderBy,
+		MaxResults: key.MaxResults,
+		Properties: key.Properties,
+		StoredAt:   c.now().UTC(),
+		Events:     events,
+	})
+}
+
+// GetEvent returns a cached event. When the event was not fetched on its own,
+// the most recent listing that contains it is used instead.
+func (c *Cache) GetEvent(calendarID, eventID string) (*Result, bool) {
+	if e, err := c.read(c.path(KindEvent, eventKey(calendarID, eventID))); err == nil && len(e.Events) == 1 {
+		return c.result(e, e.Events), true
+	}
+
+	var best *Result
+	c.scan(func(_ string, e *entry) {
+		if e.Kind != KindList || e.CalendarID != calendarID {
+			return
+		}
+		if best != nil && !e.StoredAt.After(best.StoredAt) {
+			return
+		}
+		for _, event := range e.Events {
+			if event != nil && event.ID == eventID {
+				best = c.result(e, []*types.Event{event})
+				return
+			}
+		}
+	})
+
+	return best, best != nil
+}
+
+// PutEvent stores a single event
+func (c *Cache) PutEvent(calendarID string, event *types.Event) error {
+	if event == nil || event.ID == "" {
+		return nil
+	}
+
+	return c.write(c.path(KindEvent, eventKey(calendarID, event.ID)), &entry{
+		Kind:       KindEvent,
+		CalendarID: calendarID,
+		EventID:    event.ID,
+		StoredAt:   c.now().UTC(),
+		Events:     []*types.Event{event},
+	})
+}
+
+// Invalidate removes entries a write to eventID may have changed: the cached
+// event itself, listings that contain it or one of its recurring instances,
+// and listings whose window overlaps any of the changed events
+func (c *Cache) Invalidate(calendarID, eventID string, changed ...*types.Event) error {
+	var windows [][2]time.Time
+	for _, event := range changed {
+		if start, end, ok := eventBounds(event); ok {
+			windows = append(windows, [2]time.Time{start, end})
+		}
+	}
+
+	var firstErr error
+	remove := func(path string) {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) && firstErr == nil {
+			firstErr = fileError("could not remove cache entry", err)
+		}
+	}
+
+	c.scan(func(path string, e *entry) {
+		if e.CalendarID != calendarID {
+			return
+		}
+
+		if e.Kind == KindEvent {
+			if eventID != "" && (e.EventID == eventID || isInstanceOf(e.EventID, eventID)) {
+				remove(path)
+			}
+			return
+		}
+
+		for _, event := range e.Events {
+			if event != nil && eventID != "" && (event.ID == eventID || isInstanceOf(event.ID, eventID)) {
+				remove(path)
+				return
+			}
+		}
+		for _, w := range windows {
+			if w[0].Before(e.To) && w[1].After(e.From) {
+				remove(path)
+				return
+			}
+		}
+	})
+
+	return firstErr
+}
+
+// Clear removes cached entries for calendarID, or all entries when it is
+// empty, and returns how many were removed
+func (c *Cache) Clear(calendarID string) (int, error) {
+	files, err := c.files()
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, path := range files {
+		if calendarID != "" {
+			e, err := c.read(path)
+			if err == nil && e.CalendarID != calendarID {
+				continue
+			}
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return removed, fileError("could not remove cache entry", err)
+		}
+		removed++
+	}
+
+	return removed, nil
+}
+
+// Status reports the number, age and size of cached entries
+func (c *Cache) Status() (*Status, error) {
+	status := &Status{
+		Dir:        c.dir,
+		TTLSeconds: int(c.ttl / time.Second),
+	}
+
+	files, err := c.files()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range files {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		status.SizeBytes += info.Size()
+
+		e, err := c.read(path)
+		if err != nil {
+			continue
+		}
+
+		status.Entries++
+		switch e.Kind {
+		case KindList:
+			status.ListEntries++
+		case KindEvent:
+			status.EventEntries++
+		}
+		if c.isStale(e) {
+			status.StaleEntries++
+		}
+
+		storedAt := e.StoredAt
+		if status.Oldest == nil || storedAt.Before(*status.Oldest) {
+			status.Oldest = &storedAt
+		}
+		if status.Newest == nil || storedAt.After(*status.Newest) {
+			status.Newest = &storedAt
+		}
+	}
+
+	return status, nil
+}
+
+// result builds a Result for a stored entry
+func (c *Cache) result(e *entry, events []*types.Event) *Result {
+	return &Result{
+		Events:   events,
+		StoredAt: e.StoredAt,
+		Stale:    c.isStale(e),
+	}
+}
+
+// isStale reports whether an entry is older than the TTL
+func (c *Cache) isStale(e *entry) bool {
+	return c.now().Sub(e.StoredAt) > c.ttl
+}
+
+// path returns the file for a cache key
+func (c *Cache) path(kind, key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, kind+"-"+hex.EncodeToString(sum[:16])+".json")
+}
+
+// files lists the entry files in the cache directory
+func (c *Cache) files() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(c.dir, "*.json"))
+	if err != nil {
+		return nil, fileError("could not list cache directory", err)
+	}
+	return files, nil
+}
+
+// scan calls fn for every readable entry; unreadable files are skipped
+func (c *Cache) scan(fn func(path string, e *entry)) {
+	files, err := c.files()
+	if err != nil {
+		return
+	}
+
+	for _, path := range files {
+		if e, err := c.read(path); err == nil {
+			fn(path, e)
+		}
+	}
+}
+
+// read loads an entry from disk
+func (c *Cache) read(path string) (*entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+
+	return &e, nil
+}
+
+// write stores an entry, replacing any previous file atomically
+func (c *Cache) write(path string, e *entry) error {
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return fileError("could not create cache directory", err)
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fileError("could not encode cache entry", err)
+	}
+
+	tmp, err := os.CreateTemp(c.dir, ".entry-*")
+	if err != nil {
+		return fileError("could not write cache entry", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fileError("could not write cache entry", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fileError("could not write cache entry", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fileError("could not write cache entry", err)
+	}
+
+	return nil
+}
+
+// listKey builds the lookup key for a listing
+func listKey(key ListKey) string {
+	s := fmt.Sprintf("%s\x00%s\x00%s\x00%s\x00%s\x00%d",
+		key.CalendarID,
+		key.From.UTC().Format(time.RFC3339),
+		key.To.UTC().Format(time.RFC3339),
+		key.Query,
+		key.OrderBy,
+		key.MaxResults)
+	if len(key.Properties) > 0 {
+		s += "\x00" + strings.Join(key.Properties, "\x01")
+	}
+	return s
+}
+
+// eventKey builds the lookup key for a single event
+func eventKey(calendarID, eventID string) string {
+	return calendarID + "\x00" + eventID
+}
+
+// isInstanceOf reports whether id is an expanded instance of a recurring
+// event, whose IDs take the form <eventID>_<start>
+func isInstanceOf(id, eventID string) bool {
+	return strings.HasPrefix(id, eventID+"_")
+}
+
+// eventBounds returns the time span an event occupies. All-day dates are
+// widened by a day on each side so they match listings in any time zone.
+func eventBounds(event *types.Event) (time.Time, time.Time, bool) {
+	if event == nil {
+		return time.Time{}, time.Time{}, false
+	}
+
+	start, ok := parseEventTime(event.Start)
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	end, ok := parseEventTime(event.End)
+	if !ok {
+		end = start
+	}
+
+	if event.Start.DateTime == "" {
+		start = start.Add(-24 * time.Hour)
+		end = end.Add(24 * time.Hour)
+	}
+
+	return start, end, true
+}
+
+// parseEventTime parses a timed or all-day event time
+func parseEventTime(t types.EventTime) (time.Time, bool) {
+	if t.DateTime != "" {
+		parsed, err := time.Parse(time.RFC3339, t.DateTime)
+		return parsed, err == nil
+	}
+	if t.Date != "" {
+		parsed, err := time.Parse("2006-01-02", t.Date)
+		return parsed, err == nil
+	}
+	return time.Time{}, false
+}
+
+// fileError wraps a filesystem error
+func fileError(details string, err error) error {
+	return types.NewAppError(types.ErrCodeFileError, "file operation failed", false).
+		WithDetails(details).
+		WithWrappedError(err)
+}
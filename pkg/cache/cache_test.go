@@ -0,0 +1,219 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/btafoya/gcal-cli/pkg/types"
+)
+
+func newTestCache(t *testing.T, now *time.Time) *Cache {
+	t.Helper()
+	c := New(t.TempDir(), 5*time.Minute)
+	c.now = func() time.Time { return *now }
+	return c
+}
+
+func timedEvent(id, start, end string) *types.Event {
+	return &types.Event{
+		ID:    id,
+		Start: types.EventTime{DateTime: start},
+		End:   types.EventTime{DateTime: end},
+	}
+}
+
+var day = ListKey{
+	CalendarID: "primary",
+	From:       time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+	To:         time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC),
+	OrderBy:    "startTime",
+	MaxResults: 250,
+}
+
+// TestListRoundTrip tests storing listings and TTL expiry
+func TestListRoundTrip(t *testing.T) {
+	now := time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC)
+	c := newTestCache(t, &now)
+
+	if _, ok := c.GetList(day); ok {
+		t.Fatal("Expected miss on empty cache")
+	}
+
+	events := []*types.Event{timedEvent("a", "2024-01-15T09:00:00Z", "2024-01-15T10:00:00Z")}
+	events[0].ETag = `"3181161784712000"`
+	if err := c.PutList(day, events); err != nil {
+		t.Fatalf("PutList() error = %v", err)
+	}
+
+	result, ok := c.GetList(day)
+	if !ok || result.Stale || len(result.Events) != 1 {
+		t.Fatalf("Expected fresh hit with 1 event, got %+v (ok=%v)", result, ok)
+	}
+	if result.Events[0].ETag != `"3181161784712000"` {
+		t.Errorf("Expected etag to be kept, got %q", result.Events[0].ETag)
+	}
+
+	// A different query is a different entry
+	other := day
+	other.Query = "standup"
+	if _, ok := c.GetList(other); ok {
+		t.Error("Expected miss for a different query")
+	}
+
+	now = now.Add(10 * time.Minute)
+	if result, ok := c.GetList(day); !ok || !result.Stale {
+		t.Errorf("Expected stale hit after TTL, got %+v (ok=%v)", result, ok)
+	}
+}
+
+// TestGetEvent_FromListing tests that single-event reads fall back to listings
+func TestGetEvent_FromListing(t *testing.T) {
+	now := time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC)
+	c := newTestCache(t, &now)
+
+	c.PutList(day, []*types.Event{timedEvent("a", "2024-01-15T09:00:00Z", "2024-01-15T10:00:00Z")})
+
+	result, ok := c.GetEvent("primary", "a")
+	if !ok || result.Events[0].ID != "a" {
+		t.Fatalf("Expected event from listing, got %+v (ok=%v)", result, ok)
+	}
+	if _, ok := c.GetEvent("other@example.com", "a"); ok {
+		t.Error("Expected miss for another calendar")
+	}
+
+	// Directly cached events take precedence
+	updated := timedEvent("a", "2024-01-15T11:00:00Z", "2024-01-15T12:00:00Z")
+	c.PutEvent("primary", updated)
+	if result, _ := c.GetEvent("primary", "a"); result.Events[0].Start.DateTime != "2024-01-15T11:00:00Z" {
+		t.Errorf("Expected directly cached event, got %+v", result.Events[0])
+	}
+}
+
+// TestInvalidate tests which entries a write removes
+func TestInvalidate(t *testing.T) {
+	now := time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC)
+	c := newTestCache(t, &now)
+
+	nextDay := day
+	nextDay.From = day.From.AddDate(0, 0, 1)
+	nextDay.To = day.To.AddDate(0, 0, 1)
+
+	reset := func() {
+		c.Clear("")
+		c.PutList(day, []*types.Event{timedEvent("a", "2024-01-15T09:00:00Z", "2024-01-15T10:00:00Z")})
+		c.PutList(nextDay, []*types.Event{timedEvent("r_20240116T090000Z", "2024-01-16T09:00:00Z", "2024-01-16T10:00:00Z")})
+		c.PutEvent("primary", timedEvent("a", "2024-01-15T09:00:00Z", "2024-01-15T10:00:00Z"))
+	}
+
+	tests := []struct {
+		name        string
+		eventID     string
+		changed     []*types.Event
+		wantDay     bool
+		wantNextDay bool
+	}{
+		{
+			name:        "delete removes listings containing the event",
+			eventID:     "a",
+			wantDay:     false,
+			wantNextDay: true,
+		},
+		{
+			name:        "create removes overlapping listings",
+			eventID:     "new",
+			changed:     []*types.Event{timedEvent("new", "2024-01-16T13:00:00Z", "2024-01-16T14:00:00Z")},
+			wantDay:     true,
+			wantNextDay: false,
+		},
+		{
+			name:        "recurring master matches its instances",
+			eventID:     "r",
+			wantDay:     true,
+			wantNextDay: false,
+		},
+		{
+			name:    "all-day events match nearby windows",
+			eventID: "allday",
+			changed: []*types.Event{{
+				ID:    "allday",
+				Start: types.EventTime{Date: "2024-01-16"},
+				End:   types.EventTime{Date: "2024-01-17"},
+			}},
+			wantDay:     false,
+			wantNextDay: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reset()
+			if err := c.Invalidate("primary", tt.eventID, tt.changed...); err != nil {
+				t.Fatalf("Invalidate() error = %v", err)
+			}
+
+			if _, ok := c.GetList(day); ok != tt.wantDay {
+				t.Errorf("Day listing cached = %v, want %v", ok, tt.wantDay)
+			}
+			if _, ok := c.GetList(nextDay); ok != tt.wantNextDay {
+				t.Errorf("Next day listing cached = %v, want %v", ok, tt.wantNextDay)
+			}
+		})
+	}
+
+	// Other calendars are untouched
+	reset()
+	c.Invalidate("other@example.com", "a")
+	if _, ok := c.GetList(day); !ok {
+		t.Error("Expected listing for another calendar's write to be kept")
+	}
+}
+
+// TestClearAndStatus tests clearing by calendar and cache statistics
+func TestClearAndStatus(t *testing.T) {
+	now := time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC)
+	c := newTestCache(t, &now)
+
+	status, err := c.Status()
+	if err != nil {
+		t.Fatalf("Status() on missing directory error = %v", err)
+	}
+	if status.Entries != 0 {
+		t.Errorf("Expected empty status, got %+v", status)
+	}
+
+	other := day
+	other.CalendarID = "team@example.com"
+	c.PutList(day, nil)
+	now = now.Add(10 * time.Minute)
+	c.PutList(other, nil)
+	c.PutEvent("primary", timedEvent("a", "2024-01-15T09:00:00Z", "2024-01-15T10:00:00Z"))
+
+	// Corrupt files are ignored by reads but still cleared
+	os.WriteFile(filepath.Join(c.Dir(), "list-corrupt.json"), []byte("{"), 0600)
+
+	status, err = c.Status()
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if status.Entries != 3 || status.ListEntries != 2 || status.EventEntries != 1 || status.StaleEntries != 1 {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+	if status.Oldest == nil || status.Newest == nil || !status.Oldest.Before(*status.Newest) {
+		t.Errorf("Expected oldest before newest, got %v / %v", status.Oldest, status.Newest)
+	}
+
+	removed, err := c.Clear("team@example.com")
+	if err != nil || removed != 2 {
+		t.Errorf("Clear(calendar) = %d, %v; want 2 (entry and corrupt file)", removed, err)
+	}
+	if _, ok := c.GetList(day); !ok {
+		t.Error("Expected primary listing to survive")
+	}
+
+	removed, err = c.Clear("")
+	if err != nil || removed != 2 {
+		t.Errorf("Clear(all) = %d, %v; want 2", removed, err)
+	}
+}
@@ -34,8 +34,8 @@ func (c *Client) ManageAttendees(ctx context.Context, eventID string, operation
 		}
 	}
 
-	// Get current event
-	event, err := c.GetEvent(ctx, eventID)
+	// Get current event, bypassing the cache
+	event, err := c.fetchEvent(ctx, eventID)
 	if err != nil {
 		return nil, err
 	}
@@ -73,6 +73,9 @@ func (c *Client) ManageAttendees(ctx context.Context, eventID string, operation
 	updateParams := CreateEventParams{
 		Attendees: attendeeEmails,
 	}
+	if len(attendeeEmails) == 0 {
+		updateParams.Clear = []string{"attendees"}
+	}
 
 	return c.UpdateEvent(ctx, eventID, updateParams)
 }
@@ -109,6 +112,9 @@ func (c *Client) ReplaceAttendees(ctx context.Context, eventID string, emails []
 	updateParams := CreateEventParams{
 		Attendees: emails,
 	}
+	if len(emails) == 0 {
+		updateParams.Clear = []string{"attendees"}
+	}
 
 	return c.UpdateEvent(ctx, eventID, updateParams)
 }
@@ -0,0 +1,229 @@
+package calendar
+
+import (
+	"context"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// Backend is the storage layer behind Client. The Google backend talks to
+// the Calendar API, while FakeBackend keeps everything in memory for tests
+// and offline demos. Implementations return *googleapi.Error values for API
+// failures so retry and error translation behave the same for both.
+type Backend interface {
+	// Events
+	InsertEvent(ctx context.Context, calendarID string, event *calendar.Event, opts WriteOptions) (*calendar.Event, error)
+	GetEvent(ctx context.Context, calendarID, eventID string) (*calendar.Event, error)
+	ListEvents(ctx context.Context, calendarID string, opts ListOptions) (*calendar.Events, error)
+	UpdateEvent(ctx context.Context, calendarID, eventID string, event *calendar.Event, opts WriteOptions) (*calendar.Event, error)
+	// PatchEvent changes only the fields set in patch (or listed in its
+	// ForceSendFields and NullFields), leaving the rest of the event intact
+	PatchEvent(ctx context.Context, calendarID, eventID string, patch *calendar.Event, opts WriteOptions) (*calendar.Event, error)
+	DeleteEvent(ctx context.Context, calendarID, eventID string) error
+	// MoveEvent moves an event to another calendar, keeping its ID
+	MoveEvent(ctx context.Context, calendarID, eventID, destinationID string, opts WriteOptions) (*calendar.Event, error)
+	ListInstances(ctx context.Context, calendarID, eventID string, opts ListOptions) (*calendar.Events, error)
+	// QuickAddEvent creates an event from a sentence such as "Lunch with Sam
+	// tomorrow noon", which the server interprets
+	QuickAddEvent(ctx context.Context, calendarID, text string, opts WriteOptions) (*calendar.Event, error)
+
+	// Free/busy
+	QueryFreeBusy(ctx context.Context, request *calendar.FreeBusyRequest) (*calendar.FreeBusyResponse, error)
+
+	// Access control
+	ListACL(ctx context.Context, calendarID string) (*calendar.Acl, error)
+	InsertACL(ctx context.Context, calendarID string, rule *calendar.AclRule) (*calendar.AclRule, error)
+	DeleteACL(ctx context.Context, calendarID, ruleID string) error
+
+	// Calendars
+	ListCalendars(ctx context.Context) (*calendar.CalendarList, error)
+	GetCalendar(ctx context.Context, calendarID string) (*calendar.Calendar, error)
+}
+
+// ListOptions contains query options for listing events and instances
+type ListOptions struct {
+	TimeMin      string // RFC3339 lower bound (exclusive of events ending before it)
+	TimeMax      string // RFC3339 upper bound (exclusive of events starting after it)
+	MaxResults   int64
+	Query        string
+	OrderBy      string
+	SingleEvents bool
+	PageToken    string
+	// PrivateExtendedProperty and SharedExtendedProperty are key=value
+	// constraints an event's extended properties must all meet
+	PrivateExtendedProperty []string
+	SharedExtendedProperty  []string
+	EventTypes              []string // only events of these types
+	ShowDeleted             bool     // include cancelled events
+}
+
+// WriteOptions contains options for event writes
+type WriteOptions struct {
+	SendUpdates string // all, externalOnly or none
+	IfMatch     string // etag the event must still have; empty writes unconditionally
+	// ConferenceDataVersion 1 lets the write create or change conference
+	// data; with 0 the event's conferenceData is ignored
+	ConferenceDataVersion int64
+}
+
+// googleBackend implements Backend with the Google Calendar API
+type googleBackend struct {
+	service *calendar.Service
+}
+
+// NewGoogleBackend creates a backend for the Google Calendar API service.
+// It returns nil if service is nil.
+func NewGoogleBackend(service *calendar.Service) Backend {
+	if service == nil {
+		return nil
+	}
+	return &googleBackend{service: service}
+}
+
+func (b *googleBackend) InsertEvent(ctx context.Context, calendarID string, event *calendar.Event, opts WriteOptions) (*calendar.Event, error) {
+	call := b.service.Events.Insert(calendarID, event).Context(ctx)
+	if opts.SendUpdates != "" {
+		call = call.SendUpdates(opts.SendUpdates)
+	}
+	if opts.ConferenceDataVersion > 0 {
+		call = call.ConferenceDataVersion(opts.ConferenceDataVersion)
+	}
+	return call.Do()
+}
+
+func (b *googleBackend) GetEvent(ctx context.Context, calendarID, eventID string) (*calendar.Event, error) {
+	return b.service.Events.Get(calendarID, eventID).Context(ctx).Do()
+}
+
+func (b *googleBackend) ListEvents(ctx context.Context, calendarID string, opts ListOptions) (*calendar.Events, error) {
+	call := b.service.Events.List(calendarID).
+		Context(ctx).
+		SingleEvents(opts.SingleEvents)
+
+	if opts.TimeMin != "" {
+		call = call.TimeMin(opts.TimeMin)
+	}
+	if opts.TimeMax != "" {
+		call = call.TimeMax(opts.TimeMax)
+	}
+	if opts.MaxResults > 0 {
+		call = call.MaxResults(opts.MaxResults)
+	}
+	if opts.Query != "" {
+		call = call.Q(opts.Query)
+	}
+	if opts.OrderBy != "" {
+		call = call.OrderBy(opts.OrderBy)
+	}
+	if opts.PageToken != "" {
+		call = call.PageToken(opts.PageToken)
+	}
+	if len(opts.PrivateExtendedProperty) > 0 {
+		call = call.PrivateExtendedProperty(opts.PrivateExtendedProperty...)
+	}
+	if len(opts.SharedExtendedProperty) > 0 {
+		call = call.SharedExtendedProperty(opts.SharedExtendedProperty...)
+	}
+	if len(opts.EventTypes) > 0 {
+		call = call.EventTypes(opts.EventTypes...)
+	}
+	if opts.ShowDeleted {
+		call = call.ShowDeleted(true)
+	}
+
+	return call.Do()
+}
+
+func (b *googleBackend) UpdateEvent(ctx context.Context, calendarID, eventID string, event *calendar.Event, opts WriteOptions) (*calendar.Event, error) {
+	call := b.service.Events.Update(calendarID, eventID, event).Context(ctx)
+	if opts.SendUpdates != "" {
+		call = call.SendUpdates(opts.SendUpdates)
+	}
+	if opts.ConferenceDataVersion > 0 {
+		call = call.ConferenceDataVersion(opts.ConferenceDataVersion)
+	}
+	if opts.IfMatch != "" {
+		call.Header().Set("If-Match", opts.IfMatch)
+	}
+	return call.Do()
+}
+
+func (b *googleBackend) PatchEvent(ctx context.Context, calendarID, eventID string, patch *calendar.Event, opts WriteOptions) (*calendar.Event, error) {
+	call := b.service.Events.Patch(calendarID, eventID, patch).Context(ctx)
+	if opts.SendUpdates != "" {
+		call = call.SendUpdates(opts.SendUpdates)
+	}
+	if opts.ConferenceDataVersion > 0 {
+		call = call.ConferenceDataVersion(opts.ConferenceDataVersion)
+	}
+	if opts.IfMatch != "" {
+		call.Header().Set("If-Match", opts.IfMatch)
+	}
+	return call.Do()
+}
+
+func (b *googleBackend) DeleteEvent(ctx context.Context, calendarID, eventID string) error {
+	return b.service.Events.Delete(calendarID, eventID).Context(ctx).Do()
+}
+
+func (b *googleBackend) MoveEvent(ctx context.Context, calendarID, eventID, destinationID string, opts WriteOptions) (*calendar.Event, error) {
+	call := b.service.Events.Move(calendarID, eventID, destinationID).Context(ctx)
+	if opts.SendUpdates != "" {
+		call = call.SendUpdates(opts.SendUpdates)
+	}
+	return call.Do()
+}
+
+func (b *googleBackend) ListInstances(ctx context.Context, calendarID, eventID string, opts ListOptions) (*calendar.Events, error) {
+	call := b.service.Events.Instances(calendarID, eventID).Context(ctx)
+
+	if opts.TimeMin != "" {
+		call = call.TimeMin(opts.TimeMin)
+	}
+	if opts.TimeMax != "" {
+		call = call.TimeMax(opts.TimeMax)
+	}
+	if opts.MaxResults > 0 {
+		call = call.MaxResults(opts.MaxResults)
+	}
+	if opts.PageToken != "" {
+		call = call.PageToken(opts.PageToken)
+	}
+	if opts.ShowDeleted {
+		call = call.ShowDeleted(true)
+	}
+
+	return call.Do()
+}
+
+func (b *googleBackend) QuickAddEvent(ctx context.Context, calendarID, text string, opts WriteOptions) (*calendar.Event, error) {
+	call := b.service.Events.QuickAdd(calendarID, text).Context(ctx)
+	if opts.SendUpdates != "" {
+		call = call.SendUpdates(opts.SendUpdates)
+	}
+	return call.Do()
+}
+
+func (b *googleBackend) QueryFreeBusy(ctx context.Context, request *calendar.FreeBusyRequest) (*calendar.FreeBusyResponse, error) {
+	return b.service.Freebusy.Query(request).Context(ctx).Do()
+}
+
+func (b *googleBackend) ListACL(ctx context.Context, calendarID string) (*calendar.Acl, error) {
+	return b.service.Acl.List(calendarID).Context(ctx).Do()
+}
+
+func (b *googleBackend) InsertACL(ctx context.Context, calendarID string, rule *calendar.AclRule) (*calendar.AclRule, error) {
+	return b.service.Acl.Insert(calendarID, rule).Context(ctx).Do()
+}
+
+func (b *googleBackend) DeleteACL(ctx context.Context, calendarID, ruleID string) error {
+	return b.service.Acl.Delete(calendarID, ruleID).Context(ctx).Do()
+}
+
+func (b *googleBackend) ListCalendars(ctx context.Context) (*calendar.CalendarList, error) {
+	return b.service.CalendarList.List().Context(ctx).Do()
+}
+
+func (b *googleBackend) GetCalendar(ctx context.Context, calendarID string) (*calendar.Calendar, error) {
+	return b.service.Calendars.Get(calendarID).Context(ctx).Do()
+}
@@ -22,6 +22,10 @@ type BatchCreateParams struct {
 	Events      []CreateEventParams
 	ContinueOnError bool
 	MaxConcurrent   int
+
+	// IdempotencyKey, if set, gives every event without its own key the key
+	// "<IdempotencyKey>/<index>", so a repeated batch creates nothing twice
+	IdempotencyKey string
 }
 
 // BatchCreateEvents creates multiple events concurrently
@@ -41,6 +45,10 @@ func (c *Client) BatchCreateEvents(ctx context.Context, params BatchCreateParams
 	var mu sync.Mutex
 
 	for i, eventParams := range params.Events {
+		if params.IdempotencyKey != "" && eventParams.ID == "" && eventParams.IdempotencyKey == "" {
+			eventParams.IdempotencyKey = fmt.Sprintf("%s/%d", params.IdempotencyKey, i)
+		}
+
 		wg.Add(1)
 		go func(index int, ep CreateEventParams) {
 			defer wg.Done()
@@ -57,7 +65,7 @@ func (c *Client) BatchCreateEvents(ctx context.Context, params BatchCreateParams
 			if err != nil {
 				appErr, ok := err.(*types.AppError)
 				if !ok {
-					appErr = types.ErrAPIError.
+					appErr = types.NewAppError(types.ErrCodeAPIError, "API operation failed", true).
 						WithDetails(fmt.Sprintf("failed to create event at index %d", index)).
 						WithWrappedError(err)
 				}
@@ -84,7 +92,7 @@ func (c *Client) BatchCreateEvents(ctx context.Context, params BatchCreateParams
 	if !params.ContinueOnError {
 		for _, result := range results {
 			if !result.Success {
-				return results, types.ErrAPIError.
+				return results, types.NewAppError(types.ErrCodeAPIError, "API operation failed", true).
 					WithDetails("batch create failed: one or more events failed to create")
 			}
 		}
@@ -134,7 +142,7 @@ func (c *Client) BatchUpdateEvents(ctx context.Context, params BatchUpdateParams
 			if err != nil {
 				appErr, ok := err.(*types.AppError)
 				if !ok {
-					appErr = types.ErrAPIError.
+					appErr = types.NewAppError(types.ErrCodeAPIError, "API operation failed", true).
 						WithDetails(fmt.Sprintf("failed to update event %s", id)).
 						WithWrappedError(err)
 				}
@@ -163,7 +171,7 @@ func (c *Client) BatchUpdateEvents(ctx context.Context, params BatchUpdateParams
 	if !params.ContinueOnError {
 		for _, result := range results {
 			if !result.Success {
-				return results, types.ErrAPIError.
+				return results, types.NewAppError(types.ErrCodeAPIError, "API operation failed", true).
 					WithDetails("batch update failed: one or more events failed to update")
 			}
 		}
@@ -212,7 +220,7 @@ func (c *Client) BatchDeleteEvents(ctx context.Context, params BatchDeleteParams
 			if err != nil {
 				appErr, ok := err.(*types.AppError)
 				if !ok {
-					appErr = types.ErrAPIError.
+					appErr = types.NewAppError(types.ErrCodeAPIError, "API operation failed", true).
 						WithDetails(fmt.Sprintf("failed to delete event %s", id)).
 						WithWrappedError(err)
 				}
@@ -239,7 +247,7 @@ func (c *Client) BatchDeleteEvents(ctx context.Context, params BatchDeleteParams
 	if !params.ContinueOnError {
 		for _, result := range results {
 			if !result.Success {
-				return results, types.ErrAPIError.
+				return results, types.NewAppError(types.ErrCodeAPIError, "API operation failed", true).
 					WithDetails("batch delete failed: one or more events failed to delete")
 			}
 		}
@@ -2,6 +2,9 @@ package calendar
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -361,6 +364,21 @@ func TestIsRetryable(t *testing.T) {
 			err:        &googleapi.Error{Code: 404},
 			retryable: false,
 		},
+		{
+			name:      "rate limit reported as forbidden",
+			err:       &googleapi.Error{Code: 403, Errors: []googleapi.ErrorItem{{Reason: "userRateLimitExceeded"}}},
+			retryable: true,
+		},
+		{
+			name:      "forbidden",
+			err:       &googleapi.Error{Code: 403, Errors: []googleapi.ErrorItem{{Reason: "forbidden"}}},
+			retryable: false,
+		},
+		{
+			name:      "request timeout",
+			err:       fmt.Errorf("list events: %w", context.DeadlineExceeded),
+			retryable: true,
+		},
 		{
 			name:       "non-api error",
 			err:        context.Canceled,
@@ -380,16 +398,25 @@ func TestIsRetryable(t *testing.T) {
 
 // TestHandleAPIError tests API error conversion
 func TestHandleAPIError(t *testing.T) {
+	event := apiResource{"event", "abc123"}
+
 	tests := []struct {
-		name          string
-		err           error
-		expectedCode  string
+		name            string
+		err             error
+		resource        apiResource
+		expectedCode    string
+		expectedMessage string
 	}{
 		{
 			name:         "bad request",
 			err:          &googleapi.Error{Code: 400, Message: "Bad request"},
 			expectedCode: types.ErrCodeInvalidInput,
 		},
+		{
+			name:         "empty time range",
+			err:          apiError(400, "timeRangeEmpty", "The specified time range is empty."),
+			expectedCode: types.ErrCodeInvalidTimeRange,
+		},
 		{
 			name:         "unauthorized",
 			err:          &googleapi.Error{Code: 401, Message: "Unauthorized"},
@@ -401,14 +428,108 @@ func TestHandleAPIError(t *testing.T) {
 			expectedCode: types.ErrCodePermissionDenied,
 		},
 		{
-			name:         "not found",
-			err:          &googleapi.Error{Code: 404, Message: "Not found"},
-			expectedCode: types.ErrCodeNotFound,
+			name:         "rate limit reported as forbidden",
+			err:          &googleapi.Error{Code: 403, Message: "Rate Limit Exceeded", Errors: []googleapi.ErrorItem{{Reason: "rateLimitExceeded"}}},
+			expectedCode: types.ErrCodeRateLimit,
 		},
 		{
-			name:         "conflict",
-			err:          &googleapi.Error{Code: 409, Message: "Conflict"},
-			expectedCode: types.ErrCodeInvalidInput,
+			name:         "user rate limit",
+			err:          apiError(403, "userRateLimitExceeded", "User Rate Limit Exceeded"),
+			expectedCode: types.ErrCodeRateLimit,
+		},
+		{
+			name:            "quota exceeded",
+			err:             apiError(403, "quotaExceeded", "Calendar usage limits exceeded."),
+			expectedCode:    types.ErrCodeQuotaExceeded,
+			expectedMessage: "API quota exceeded",
+		},
+		{
+			name:            "daily limit exceeded",
+			err:             apiError(403, "dailyLimitExceeded", "Daily Limit Exceeded"),
+			expectedCode:    types.ErrCodeQuotaExceeded,
+			expectedMessage: "API quota exceeded",
+		},
+		{
+			name:            "calendar usage limits",
+			err:             apiError(403, "calendarUsageLimitsExceeded", "Calendar usage limits exceeded."),
+			expectedCode:    types.ErrCodeQuotaExceeded,
+			expectedMessage: "calendar usage limit exceeded",
+		},
+		{
+			name:            "not the organizer",
+			err:             apiError(403, "forbiddenForNonOrganizer", "Shared properties can only be changed by the organizer of the event."),
+			resource:        event,
+			expectedCode:    types.ErrCodePermissionDenied,
+			expectedMessage: "only the organizer can make this change",
+		},
+		{
+			name:            "required access level",
+			err:             apiError(403, "requiredAccessLevel", "You need to have writer access to this calendar."),
+			resource:        apiResource{"calendar", "team@example.com"},
+			expectedCode:    types.ErrCodePermissionDenied,
+			expectedMessage: "insufficient access to the calendar",
+		},
+		{
+			name:            "service account",
+			err:             apiError(403, "forbiddenForServiceAccounts", "Service accounts cannot invite attendees without Domain-Wide Delegation of Authority."),
+			expectedCode:    types.ErrCodePermissionDenied,
+			expectedMessage: "not allowed for service accounts",
+		},
+		{
+			name: "insufficient scopes in details",
+			err: &googleapi.Error{Code: 403, Message: "Request had insufficient authentication scopes.",
+				Details: []interface{}{map[string]interface{}{"reason": "ACCESS_TOKEN_SCOPE_INSUFFICIENT"}}},
+			expectedCode:    types.ErrCodePermissionDenied,
+			expectedMessage: "insufficient permissions",
+		},
+		{
+			name:            "event not found",
+			err:             apiError(404, "notFound", "Not Found"),
+			resource:        event,
+			expectedCode:    types.ErrCodeNotFound,
+			expectedMessage: "event not found",
+		},
+		{
+			name:            "calendar not found",
+			err:             apiError(404, "notFound", "Not Found"),
+			resource:        apiResource{"calendar", "missing@example.com"},
+			expectedCode:    types.ErrCodeNotFound,
+			expectedMessage: "calendar not found",
+		},
+		{
+			name:            "sharing rule not found",
+			err:             apiError(404, "notFound", "Not Found"),
+			resource:        apiResource{"sharing rule", "user:bob@example.com"},
+			expectedCode:    types.ErrCodeNotFound,
+			expectedMessage: "sharing rule not found",
+		},
+		{
+			name:            "deleted",
+			err:             apiError(410, "deleted", "Resource has been deleted"),
+			resource:        event,
+			expectedCode:    types.ErrCodeNotFound,
+			expectedMessage: "event has been deleted",
+		},
+		{
+			name:            "conflict",
+			err:             &googleapi.Error{Code: 409, Message: "Conflict"},
+			resource:        event,
+			expectedCode:    types.ErrCodeConflict,
+			expectedMessage: "conflict with existing event",
+		},
+		{
+			name:            "duplicate",
+			err:             apiError(409, "duplicate", "The requested identifier already exists."),
+			resource:        event,
+			expectedCode:    types.ErrCodeConflict,
+			expectedMessage: "event already exists",
+		},
+		{
+			name:            "precondition failed",
+			err:             apiError(412, "conditionNotMet", "Precondition Failed"),
+			resource:        event,
+			expectedCode:    types.ErrCodePreconditionFailed,
+			expectedMessage: "event changed since it was read",
 		},
 		{
 			name:         "rate limit",
@@ -424,7 +545,7 @@ func TestHandleAPIError(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			appErr := handleAPIError(tt.err, "test operation")
+			appErr := handleAPIError(tt.err, "test operation", tt.resource)
 			if appErr == nil {
 				t.Fatal("Expected error, got nil")
 			}
@@ -437,14 +558,189 @@ func TestHandleAPIError(t *testing.T) {
 			if typedErr.Code != tt.expectedCode {
 				t.Errorf("Expected error code %s, got %s", tt.expectedCode, typedErr.Code)
 			}
+			if tt.expectedMessage != "" && typedErr.Message != tt.expectedMessage {
+				t.Errorf("Expected message %q, got %q", tt.expectedMessage, typedErr.Message)
+			}
+			if !errors.Is(appErr, tt.err) {
+				t.Error("Expected the API error to be wrapped")
+			}
+		})
+	}
+}
+
+// TestHandleAPIError_SuggestedAction tests that reasons get tailored suggestions
+func TestHandleAPIError_SuggestedAction(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		resource apiResource
+		want     string
+	}{
+		{"event not found", apiError(404, "notFound", "Not Found"), apiResource{"event", "abc123"}, "gcal-cli events list"},
+		{"calendar not found", apiError(404, "notFound", "Not Found"), apiResource{"calendar", "x@example.com"}, "gcal-cli calendars list"},
+		{"insufficient scopes", apiError(403, "insufficientPermissions", "Insufficient Permission"), apiResource{}, "--scopes full"},
+		{"service account", apiError(403, "forbiddenForServiceAccounts", "Forbidden"), apiResource{}, "auth.impersonate"},
+		{"quota", apiError(403, "quotaExceeded", "Quota exceeded"), apiResource{}, "quota"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appErr := handleAPIError(tt.err, "test operation", tt.resource).(*types.AppError)
+			if !strings.Contains(appErr.SuggestedAction, tt.want) {
+				t.Errorf("SuggestedAction = %q, want it to mention %q", appErr.SuggestedAction, tt.want)
+			}
 		})
 	}
 }
 
+// apiError builds an API error with a reason, as Google returns them
+func apiError(code int, reason, message string) *googleapi.Error {
+	return &googleapi.Error{
+		Code:    code,
+		Message: message,
+		Errors:  []googleapi.ErrorItem{{Reason: reason, Message: message}},
+	}
+}
+
 // TestHandleAPIError_Nil tests nil error handling
 func TestHandleAPIError_Nil(t *testing.T) {
-	err := handleAPIError(nil, "test operation")
+	err := handleAPIError(nil, "test operation", apiResource{})
 	if err != nil {
 		t.Errorf("Expected nil error, got %v", err)
 	}
 }
+
+// newFakeClient creates a client backed by an in-memory fake calendar
+func newFakeClient(t *testing.T) *Client {
+	t.Helper()
+	client := NewClientWithBackend(NewFakeBackend(), "primary")
+	client.RetryDelay = time.Millisecond
+	return client
+}
+
+// TestUpdateEvent_MergesExisting tests that unset fields keep their existing values
+func TestUpdateEvent_MergesExisting(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeClient(t)
+
+	created, err := client.CreateEvent(ctx, CreateEventParams{
+		Summary:     "Planning",
+		Description: "Quarterly planning",
+		Location:    "Room 1",
+		Start:       time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC),
+		End:         time.Date(2024, 1, 15, 11, 0, 0, 0, time.UTC),
+		TimeZone:    "UTC",
+		Attendees:   []string{"a@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("CreateEvent() error = %v", err)
+	}
+
+	updated, err := client.UpdateEvent(ctx, created.ID, CreateEventParams{Location: "Room 2"})
+	if err != nil {
+		t.Fatalf("UpdateEvent() error = %v", err)
+	}
+
+	if updated.Location != "Room 2" {
+		t.Errorf("Expected location 'Room 2', got '%s'", updated.Location)
+	}
+	if updated.Summary != "Planning" || updated.Description != "Quarterly planning" {
+		t.Errorf("Expected summary and description to be kept, got '%s' / '%s'", updated.Summary, updated.Description)
+	}
+	if updated.Start.DateTime != created.Start.DateTime || updated.End.DateTime != created.End.DateTime {
+		t.Errorf("Expected times to be kept, got %s - %s", updated.Start.DateTime, updated.End.DateTime)
+	}
+	if len(updated.Attendees) != 1 || updated.Attendees[0].Email != "a@example.com" {
+		t.Errorf("Expected attendees to be kept, got %+v", updated.Attendees)
+	}
+}
+
+// TestUpdateEvent_NotFound tests error translation for missing events
+func TestUpdateEvent_NotFound(t *testing.T) {
+	client := newFakeClient(t)
+
+	_, err := client.UpdateEvent(context.Background(), "missing", CreateEventParams{Summary: "x"})
+	appErr, ok := err.(*types.AppError)
+	if !ok || appErr.Code != types.ErrCodeNotFound {
+		t.Errorf("Expected NOT_FOUND error, got %v", err)
+	}
+}
+
+// TestBatchOperations tests concurrent batch create and delete with partial failure
+func TestBatchOperations(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeClient(t)
+
+	events := make([]CreateEventParams, 10)
+	for i := range events {
+		start := time.Date(2024, 1, 15, 8+i, 0, 0, 0, time.UTC)
+		events[i] = CreateEventParams{Summary: "Batch", Start: start, End: start.Add(30 * time.Minute)}
+	}
+
+	created, err := client.BatchCreateEvents(ctx, BatchCreateParams{Events: events, MaxConcurrent: 3})
+	if err != nil {
+		t.Fatalf("BatchCreateEvents() error = %v", err)
+	}
+	if summary := GetBatchSummary(created); summary["success"] != 10 {
+		t.Fatalf("Expected 10 successful creates, got %v", summary)
+	}
+
+	ids := []string{created[0].EventID, "missing", created[1].EventID}
+	deleted, err := client.BatchDeleteEvents(ctx, BatchDeleteParams{EventIDs: ids, ContinueOnError: true})
+	if err != nil {
+		t.Fatalf("BatchDeleteEvents() error = %v", err)
+	}
+	if summary := GetBatchSummary(deleted); summary["success"] != 2 || summary["failed"] != 1 {
+		t.Errorf("Expected 2 deleted and 1 failed, got %v", summary)
+	}
+	if deleted[1].Success || deleted[1].Error.Code != types.ErrCodeNotFound {
+		t.Errorf("Expected NOT_FOUND for missing event, got %+v", deleted[1])
+	}
+
+	// Without ContinueOnError the batch fails, with its own error value
+	if _, err := client.BatchDeleteEvents(ctx, BatchDeleteParams{EventIDs: []string{"missing"}}); err == nil {
+		t.Error("Expected BatchDeleteEvents() to fail")
+	}
+	if types.ErrAPIError.Details != "" {
+		t.Errorf("Batch failure modified the shared ErrAPIError: %q", types.ErrAPIError.Details)
+	}
+
+	remaining, err := client.ListEvents(ctx, ListEventsParams{
+		From: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("ListEvents() error = %v", err)
+	}
+	if len(remaining) != 8 {
+		t.Errorf("Expected 8 remaining events, got %d", len(remaining))
+	}
+}
+
+// TestListInstances tests listing occurrences of a recurring event
+func TestListInstances(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeClient(t)
+
+	created, err := client.CreateEvent(ctx, CreateEventParams{
+		Summary:    "Standup",
+		Start:      time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC),
+		End:        time.Date(2024, 1, 15, 9, 15, 0, 0, time.UTC),
+		Recurrence: []string{"RRULE:FREQ=DAILY;INTERVAL=2"},
+	})
+	if err != nil {
+		t.Fatalf("CreateEvent() error = %v", err)
+	}
+
+	instances, err := client.ListInstances(ctx, created.ID,
+		time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 22, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("ListInstances() error = %v", err)
+	}
+
+	// 15th, 17th, 19th and 21st
+	if len(instances) != 4 {
+		t.Errorf("Expected 4 instances, got %d", len(instances))
+	}
+}
@@ -21,14 +21,12 @@ func (c *Client) ListCalendars(ctx context.Context) ([]*CalendarInfo, error) {
 	var calendarList *calendar.CalendarList
 	err := c.withRetry(ctx, "list calendars", func() error {
 		var err error
-		calendarList, err = c.Service.CalendarList.List().
-			Context(ctx).
-			Do()
+		calendarList, err = c.Backend.ListCalendars(ctx)
 		return err
 	})
 
 	if err != nil {
-		return nil, handleAPIError(err, "list calendars")
+		return nil, handleAPIError(err, "list calendars", apiResource{kind: "calendar"})
 	}
 
 	// Convert to our type
@@ -56,14 +54,12 @@ func (c *Client) GetCalendar(ctx context.Context, calendarID string) (*CalendarI
 	var cal *calendar.Calendar
 	err := c.withRetry(ctx, "get calendar", func() error {
 		var err error
-		cal, err = c.Service.Calendars.Get(calendarID).
-			Context(ctx).
-			Do()
+		cal, err = c.Backend.GetCalendar(ctx, calendarID)
 		return err
 	})
 
 	if err != nil {
-		return nil, handleAPIError(err, "get calendar")
+		return nil, handleAPIError(err, "get calendar", apiResource{"calendar", calendarID})
 	}
 
 	return &CalendarInfo{
@@ -2,52 +2,97 @@ package calendar
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
 	"time"
 
 	"google.golang.org/api/calendar/v3"
 	"google.golang.org/api/googleapi"
+	"github.com/btafoya/gcal-cli/pkg/cache"
 	"github.com/btafoya/gcal-cli/pkg/types"
 )
 
-// Client wraps the Google Calendar API client with retry logic
+// Client wraps a calendar backend with retry logic
 type Client struct {
-	Service    *calendar.Service
+	Backend    Backend
 	CalendarID string
 	MaxRetries int
 	RetryDelay time.Duration
+	// MaxRetryDelay caps the backoff between attempts; zero means no cap
+	MaxRetryDelay time.Duration
+
+	// Cache, when set, answers event reads within its TTL and is
+	// invalidated by writes made through the client
+	Cache *cache.Cache
+	// Offline answers reads from the cache only, including stale entries
+	Offline bool
+	// Queue, when set, records creates, updates and deletes that fail with
+	// network errors so they can be replayed later
+	Queue WriteQueue
 }
 
-// NewClient creates a new calendar client
+// NewClient creates a new calendar client for the Google Calendar API
 func NewClient(service *calendar.Service, calendarID string) *Client {
+	return NewClientWithBackend(NewGoogleBackend(service), calendarID)
+}
+
+// NewClientWithBackend creates a new calendar client for any backend
+func NewClientWithBackend(backend Backend, calendarID string) *Client {
 	return &Client{
-		Service:    service,
-		CalendarID: calendarID,
-		MaxRetries: 3,
-		RetryDelay: 1 * time.Second,
+		Backend:       backend,
+		CalendarID:    calendarID,
+		MaxRetries:    3,
+		RetryDelay:    1 * time.Second,
+		MaxRetryDelay: 10 * time.Second,
 	}
 }
 
 // withRetry executes a function with exponential backoff retry logic
 func (c *Client) withRetry(ctx context.Context, operation string, fn func() error) error {
 	var lastErr error
+	attempts := 0
 
 	for attempt := 0; attempt <= c.MaxRetries; attempt++ {
 		if attempt > 0 {
-			// Exponential backoff
-			delay := c.RetryDelay * time.Duration(1<<uint(attempt-1))
+			// A wait that outlasts the deadline cannot end in a success
+			delay := c.retryDelay(attempt, lastErr)
+			if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+				return types.ErrRateLimit().
+					WithDetails(fmt.Sprintf("%s: waiting %s to retry would pass the deadline (%v)",
+						operation, delay.Round(time.Millisecond), lastErr))
+			}
+
 			select {
 			case <-ctx.Done():
-				return types.ErrNetworkError("operation cancelled")
+				return types.ErrCancelled().
+					WithDetails(fmt.Sprintf("%s interrupted", operation)).
+					WithWrappedError(ctx.Err())
 			case <-time.After(delay):
 			}
 		}
 
+		attempts++
 		err := fn()
 		if err == nil {
 			return nil
 		}
 
+		// An interrupted command stops at once, without queueing the write
+		if ctx.Err() == context.Canceled {
+			return types.ErrCancelled().
+				WithDetails(fmt.Sprintf("%s interrupted", operation)).
+				WithWrappedError(ctx.Err())
+		}
+
+		// Errors that are already structured, such as those from the
+		// offline backend, are returned as is
+		if appErr, ok := err.(*types.AppError); ok {
+			return appErr
+		}
+
 		lastErr = err
 
 		// Check if error is retryable
@@ -56,12 +101,56 @@ func (c *Client) withRetry(ctx context.Context, operation string, fn func() erro
 		}
 	}
 
-	// Wrap the final error
-	return types.ErrAPIError.
-		WithDetails(fmt.Sprintf("%s failed after %d attempts", operation, c.MaxRetries+1)).
+	// Wrap the final error in a fresh AppError; the shared ErrAPIError must not
+	// be mutated from concurrent callers
+	return types.NewAppError(types.ErrCodeAPIError, "API operation failed", true).
+		WithDetails(fmt.Sprintf("%s failed after %d attempts", operation, attempts)).
 		WithWrappedError(lastErr)
 }
 
+// retryDelay returns how long to wait before a retry: the server's
+// Retry-After if it sent one, otherwise a random delay up to the exponential
+// backoff ("full jitter"), so concurrent callers do not retry in lockstep.
+// Either is capped at MaxRetryDelay.
+func (c *Client) retryDelay(attempt int, err error) time.Duration {
+	if delay := retryAfter(err); delay > 0 {
+		if c.MaxRetryDelay > 0 && delay > c.MaxRetryDelay {
+			return c.MaxRetryDelay
+		}
+		return delay
+	}
+
+	backoff := c.RetryDelay * time.Duration(1<<uint(attempt-1))
+	if c.MaxRetryDelay > 0 && (backoff > c.MaxRetryDelay || backoff <= 0) {
+		backoff = c.MaxRetryDelay
+	}
+	if backoff <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(backoff) + 1))
+}
+
+// retryAfter returns the delay requested by a Retry-After header, given
+// either in seconds or as an HTTP date
+func retryAfter(err error) time.Duration {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) || apiErr.Header == nil {
+		return 0
+	}
+
+	value := apiErr.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date)
+	}
+	return 0
+}
+
 // isRetryable determines if an error should be retried
 func isRetryable(err error) bool {
 	if apiErr, ok := err.(*googleapi.Error); ok {
@@ -69,26 +158,73 @@ func isRetryable(err error) bool {
 		switch apiErr.Code {
 		case 429: // Too Many Requests
 			return true
+		case 403: // Google reports some rate limits as Forbidden
+			return isRateLimitError(apiErr)
 		case 500, 502, 503, 504: // Server errors
 			return true
 		}
 	}
+
+	// A request that ran past api.timeout_seconds is tried again
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+// isRateLimitError reports whether a 403 error is a rate limit rather than a
+// lack of permission
+func isRateLimitError(apiErr *googleapi.Error) bool {
+	for _, item := range apiErr.Errors {
+		switch item.Reason {
+		case "rateLimitExceeded", "userRateLimitExceeded":
+			return true
+		}
+	}
 	return false
 }
 
-// handleAPIError converts Google API errors to application errors
-func handleAPIError(err error, operation string) error {
+// apiResource identifies what a request was about, so errors can name it
+type apiResource struct {
+	kind string // "event", "calendar" or "sharing rule"
+	id   string
+}
+
+// String describes the resource, e.g. "event abc123"
+func (r apiResource) String() string {
+	if r.id == "" {
+		return r.kind
+	}
+	return fmt.Sprintf("%s %s", r.kind, r.id)
+}
+
+// handleAPIError converts Google API errors to application errors, using the
+// error reason where Google gives one and the resource to say what failed
+func handleAPIError(err error, operation string, resource apiResource) error {
 	if err == nil {
 		return nil
 	}
 
-	apiErr, ok := err.(*googleapi.Error)
-	if !ok {
-		return types.ErrAPIError.
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		appErr, isAppErr := err.(*types.AppError)
+		if isAppErr && appErr.Code != types.ErrCodeAPIError {
+			return appErr
+		}
+		if isNetworkError(err) {
+			return types.ErrNetworkError("network communication failed").
+				WithDetails(fmt.Sprintf("%s failed", operation)).
+				WithWrappedError(err)
+		}
+		if isAppErr {
+			return appErr
+		}
+		return types.NewAppError(types.ErrCodeAPIError, "API operation failed", true).
 			WithDetails(fmt.Sprintf("%s failed", operation)).
 			WithWrappedError(err)
 	}
 
+	if appErr := reasonError(apiErr, resource); appErr != nil {
+		return appErr.WithWrappedError(err)
+	}
+
 	switch apiErr.Code {
 	case 400:
 		return types.ErrInvalidInput("request", apiErr.Message).
@@ -103,15 +239,17 @@ func handleAPIError(err error, operation string) error {
 			WithDetails(apiErr.Message).
 			WithWrappedError(err).
 			WithSuggestedAction("Check calendar sharing settings")
-	case 404:
-		return types.ErrNotFound("event", "").
-			WithDetails(apiErr.Message).
+	case 404, 410:
+		return notFoundError(resource, apiErr).
 			WithWrappedError(err)
 	case 409:
-		return types.NewAppError(types.ErrCodeInvalidInput,
-			"conflict with existing event", true).
+		return types.NewAppError(types.ErrCodeConflict,
+			fmt.Sprintf("conflict with existing %s", resource.kind), false).
 			WithDetails(apiErr.Message).
 			WithWrappedError(err)
+	case 412:
+		return preconditionError(resource).
+			WithWrappedError(err)
 	case 429:
 		return types.ErrRateLimit().
 			WithWrappedError(err)
@@ -122,8 +260,108 @@ func handleAPIError(err error, operation string) error {
 			WithWrappedError(err).
 			WithSuggestedAction("Try again in a few moments")
 	default:
-		return types.ErrAPIError.
+		return types.NewAppError(types.ErrCodeAPIError, "API operation failed", true).
 			WithDetails(fmt.Sprintf("API error %d: %s", apiErr.Code, apiErr.Message)).
 			WithWrappedError(err)
 	}
 }
+
+// reasonError maps the reasons Google attaches to errors to application
+// errors. It returns nil for reasons the status code describes well enough.
+func reasonError(apiErr *googleapi.Error, resource apiResource) *types.AppError {
+	switch apiErrorReason(apiErr) {
+	case "rateLimitExceeded", "userRateLimitExceeded":
+		return types.ErrRateLimit().
+			WithDetails(apiErr.Message)
+	case "quotaExceeded", "dailyLimitExceeded":
+		return types.NewAppError(types.ErrCodeQuotaExceeded, "API quota exceeded", true).
+			WithDetails(apiErr.Message).
+			WithSuggestedAction("Wait for the daily quota to reset at midnight Pacific Time, or raise it in Google Cloud Console")
+	case "calendarUsageLimitsExceeded":
+		return types.NewAppError(types.ErrCodeQuotaExceeded, "calendar usage limit exceeded", true).
+			WithDetails(apiErr.Message).
+			WithSuggestedAction("Wait a few hours before creating more events or inviting more guests")
+	case "forbiddenForNonOrganizer":
+		return types.NewAppError(types.ErrCodePermissionDenied,
+			"only the organizer can make this change", false).
+			WithDetails(fmt.Sprintf("%s: %s", resource, apiErr.Message)).
+			WithSuggestedAction("Ask the organizer to make the change; guests can only change their own response")
+	case "requiredAccessLevel":
+		return types.NewAppError(types.ErrCodePermissionDenied,
+			"insufficient access to the calendar", false).
+			WithDetails(fmt.Sprintf("%s: %s", resource, apiErr.Message)).
+			WithSuggestedAction("Ask the calendar owner to give you 'Make changes to events' access")
+	case "forbiddenForServiceAccounts":
+		return types.NewAppError(types.ErrCodePermissionDenied,
+			"not allowed for service accounts", false).
+			WithDetails(apiErr.Message).
+			WithSuggestedAction("Set auth.impersonate to act as a user through domain-wide delegation")
+	case "insufficientPermissions", "ACCESS_TOKEN_SCOPE_INSUFFICIENT":
+		return types.NewAppError(types.ErrCodePermissionDenied,
+			"insufficient permissions", false).
+			WithDetails(apiErr.Message).
+			WithSuggestedAction("Run 'gcal-cli auth login --scopes full' to grant access")
+	case "duplicate":
+		return types.NewAppError(types.ErrCodeConflict,
+			fmt.Sprintf("%s already exists", resource.kind), false).
+			WithDetails(fmt.Sprintf("%s: %s", resource, apiErr.Message)).
+			WithSuggestedAction(fmt.Sprintf("Use the existing %s, or create it with a different ID", resource.kind))
+	case "conditionNotMet":
+		return preconditionError(resource)
+	case "timeRangeEmpty":
+		return types.NewAppError(types.ErrCodeInvalidTimeRange,
+			"end time must be after start time", true).
+			WithDetails(apiErr.Message)
+	case "deleted":
+		return notFoundError(resource, apiErr)
+	}
+	return nil
+}
+
+// apiErrorReason returns the reason of an API error: the first error item's,
+// or for newer endpoints the reason in its ErrorInfo details
+func apiErrorReason(apiErr *googleapi.Error) string {
+	for _, item := range apiErr.Errors {
+		if item.Reason != "" {
+			return item.Reason
+		}
+	}
+	for _, detail := range apiErr.Details {
+		if info, ok := detail.(map[string]interface{}); ok {
+			if reason, ok := info["reason"].(string); ok && reason != "" {
+				return reason
+			}
+		}
+	}
+	return ""
+}
+
+// notFoundError reports a missing or deleted resource with a hint on where
+// to find valid IDs
+func notFoundError(resource apiResource, apiErr *googleapi.Error) *types.AppError {
+	appErr := types.ErrNotFound(resource.kind, resource.id)
+	if resource.id == "" {
+		appErr = appErr.WithDetails(apiErr.Message)
+	}
+	if apiErr.Code == 410 || apiErrorReason(apiErr) == "deleted" {
+		appErr.Message = fmt.Sprintf("%s has been deleted", resource.kind)
+	}
+
+	switch resource.kind {
+	case "event":
+		return appErr.WithSuggestedAction("Check the event ID with 'gcal-cli events list'")
+	case "calendar":
+		return appErr.WithSuggestedAction("Check the calendar ID with 'gcal-cli calendars list'")
+	default:
+		return appErr
+	}
+}
+
+// preconditionError reports a write rejected because the resource changed
+// since it was read
+func preconditionError(resource apiResource) *types.AppError {
+	return types.NewAppError(types.ErrCodePreconditionFailed,
+		fmt.Sprintf("%s changed since it was read", resource.kind), true).
+		WithDetails(resource.String()).
+		WithSuggestedAction(fmt.Sprintf("Fetch the %s again, then retry the change", resource.kind))
+}
@@ -0,0 +1,64 @@
+package calendar
+
+import (
+	"github.com/btafoya/gcal-cli/pkg/types"
+	"google.golang.org/api/calendar/v3"
+)
+
+// meetSolutionType is the conference solution key for Google Meet
+const meetSolutionType = "hangoutsMeet"
+
+// newMeetRequest returns conference data asking the server to create a
+// Google Meet conference. The request ID is random, so every request asks
+// for a new conference; retries of the same request reuse it.
+func newMeetRequest() *calendar.ConferenceData {
+	return &calendar.ConferenceData{
+		CreateRequest: &calendar.CreateConferenceRequest{
+			RequestId:             newEventID(""),
+			ConferenceSolutionKey: &calendar.ConferenceSolutionKey{Type: meetSolutionType},
+		},
+	}
+}
+
+// writeOptionsFor returns the write options an event needs: conference data
+// is only applied with conferenceDataVersion 1
+func writeOptionsFor(event *calendar.Event) WriteOptions {
+	var opts WriteOptions
+	if event.ConferenceData != nil {
+		opts.ConferenceDataVersion = 1
+	}
+	return opts
+}
+
+// convertConference converts Google Calendar conference data
+func convertConference(data *calendar.ConferenceData) *types.Conference {
+	if data == nil {
+		return nil
+	}
+
+	conference := &types.Conference{ID: data.ConferenceId}
+	if data.ConferenceSolution != nil {
+		conference.Solution = data.ConferenceSolution.Name
+	}
+	if data.CreateRequest != nil && data.CreateRequest.Status != nil {
+		conference.Status = data.CreateRequest.Status.StatusCode
+	}
+
+	for _, entry := range data.EntryPoints {
+		pin := entry.Pin
+		if pin == "" {
+			pin = entry.AccessCode
+		}
+		if pin == "" {
+			pin = entry.Passcode
+		}
+		conference.EntryPoints = append(conference.EntryPoints, types.EntryPoint{
+			Type:  entry.EntryPointType,
+			URI:   entry.Uri,
+			Label: entry.Label,
+			PIN:   pin,
+		})
+	}
+
+	return conference
+}
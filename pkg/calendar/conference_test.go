@@ -0,0 +1,82 @@
+package calendar
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestCreateEvent_Meet tests creating an event with a Google Meet conference
+func TestCreateEvent_Meet(t *testing.T) {
+	fake := NewFakeBackend()
+	client := NewClientWithBackend(fake, "primary")
+	start := time.Date(2024, 1, 18, 14, 0, 0, 0, time.UTC)
+
+	event, err := client.CreateEvent(context.Background(), CreateEventParams{
+		Summary: "Design Sync",
+		Start:   start,
+		End:     start.Add(30 * time.Minute),
+		Meet:    true,
+	})
+	if err != nil {
+		t.Fatalf("CreateEvent() error = %v", err)
+	}
+
+	if event.Conference == nil || event.Conference.Solution != "Google Meet" || event.Conference.Status != "success" {
+		t.Fatalf("Conference = %+v, want a created Google Meet conference", event.Conference)
+	}
+	var video, phone bool
+	for _, entry := range event.Conference.EntryPoints {
+		video = video || entry.Type == "video" && entry.URI != ""
+		phone = phone || entry.Type == "phone" && entry.PIN != ""
+	}
+	if !video || !phone {
+		t.Errorf("Entry points = %+v, want a video link and a phone number with PIN", event.Conference.EntryPoints)
+	}
+
+	plain, err := client.CreateEvent(context.Background(), CreateEventParams{
+		Summary: "No Video",
+		Start:   start,
+		End:     start.Add(30 * time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("CreateEvent() error = %v", err)
+	}
+	if plain.Conference != nil {
+		t.Errorf("Conference = %+v without --meet, want none", plain.Conference)
+	}
+}
+
+// TestUpdateEvent_Meet tests adding a Meet conference to an existing event,
+// and that asking again keeps it
+func TestUpdateEvent_Meet(t *testing.T) {
+	fake := NewFakeBackend()
+	client := NewClientWithBackend(fake, "primary")
+	start := time.Date(2024, 1, 18, 14, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	event, err := client.CreateEvent(ctx, CreateEventParams{
+		Summary: "Design Sync",
+		Start:   start,
+		End:     start.Add(30 * time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("CreateEvent() error = %v", err)
+	}
+
+	updated, err := client.UpdateEvent(ctx, event.ID, CreateEventParams{Meet: true})
+	if err != nil {
+		t.Fatalf("UpdateEvent() error = %v", err)
+	}
+	if updated.Conference == nil || updated.Conference.ID == "" {
+		t.Fatalf("Conference = %+v, want one", updated.Conference)
+	}
+
+	again, err := client.UpdateEvent(ctx, event.ID, CreateEventParams{Summary: "Design Sync v2", Meet: true})
+	if err != nil {
+		t.Fatalf("UpdateEvent() error = %v", err)
+	}
+	if again.Conference == nil || again.Conference.ID != updated.Conference.ID {
+		t.Errorf("Conference = %+v, want the existing %s kept", again.Conference, updated.Conference.ID)
+	}
+}
@@ -0,0 +1,38 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/btafoya/gcal-cli/pkg/types"
+)
+
+// isPreconditionFailed reports whether a write was rejected because the
+// resource no longer had the expected etag
+func isPreconditionFailed(err error) bool {
+	var appErr *types.AppError
+	return errors.As(err, &appErr) && appErr.Code == types.ErrCodePreconditionFailed
+}
+
+// eventChanged completes a PRECONDITION_FAILED error for an update with the
+// event's current version. If that cannot be fetched, err is returned as is.
+func (c *Client) eventChanged(ctx context.Context, eventID, etag string, err error) error {
+	c.invalidateCache(c.CalendarID, eventID)
+
+	current, fetchErr := c.fetchEvent(ctx, eventID)
+	if fetchErr != nil {
+		return err
+	}
+	return eventChangedError(eventID, etag, current).WithWrappedError(err)
+}
+
+// eventChangedError reports that an event no longer has the etag a write
+// expected, including the current version so the caller can merge and retry
+func eventChangedError(eventID, etag string, current *types.Event) *types.AppError {
+	return types.NewAppError(types.ErrCodePreconditionFailed,
+		"event changed since it was read", true).
+		WithDetails(fmt.Sprintf("event %s: expected etag %s, current etag %s", eventID, etag, current.ETag)).
+		WithSuggestedAction(fmt.Sprintf("Review the current version, then update again with --if-match %s, or use --force to overwrite it", current.ETag)).
+		WithCurrent(current)
+}
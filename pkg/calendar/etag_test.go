@@ -0,0 +1,124 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/btafoya/gcal-cli/pkg/types"
+	"google.golang.org/api/calendar/v3"
+)
+
+// racingBackend changes an event right after it is read, like another
+// agent editing it between the read and the write of an update
+type racingBackend struct {
+	*FakeBackend
+	summary string
+}
+
+func (b *racingBackend) GetEvent(ctx context.Context, calendarID, eventID string) (*calendar.Event, error) {
+	event, err := b.FakeBackend.GetEvent(ctx, calendarID, eventID)
+	if err != nil || b.summary == "" {
+		return event, err
+	}
+
+	changed := *event
+	changed.Summary = b.summary
+	b.summary = ""
+	if _, err := b.FakeBackend.UpdateEvent(ctx, calendarID, eventID, &changed, WriteOptions{}); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// createRacingEvent creates an event through a racing backend
+func createRacingEvent(t *testing.T) (*Client, *racingBackend, *types.Event) {
+	t.Helper()
+	backend := &racingBackend{FakeBackend: NewFakeBackend()}
+	client := NewClientWithBackend(backend, "primary")
+	client.RetryDelay = time.Millisecond
+
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	event, err := client.CreateEvent(context.Background(), CreateEventParams{
+		Summary: "Planning", Start: start, End: start.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("CreateEvent() error = %v", err)
+	}
+	return client, backend, event
+}
+
+// TestUpdateEvent_ConcurrentChange tests that an update does not overwrite a
+// change made after the event was read
+func TestUpdateEvent_ConcurrentChange(t *testing.T) {
+	ctx := context.Background()
+	client, backend, event := createRacingEvent(t)
+	backend.summary = "Changed elsewhere"
+
+	_, err := client.UpdateEvent(ctx, event.ID, CreateEventParams{Location: "Room 2"})
+
+	var appErr *types.AppError
+	if !errors.As(err, &appErr) || appErr.Code != types.ErrCodePreconditionFailed {
+		t.Fatalf("UpdateEvent() error = %v, want PRECONDITION_FAILED", err)
+	}
+	current, ok := appErr.Current.(*types.Event)
+	if !ok || current.Summary != "Changed elsewhere" {
+		t.Fatalf("Current = %+v, want the changed event", appErr.Current)
+	}
+
+	stored, err := client.GetEvent(ctx, event.ID)
+	if err != nil {
+		t.Fatalf("GetEvent() error = %v", err)
+	}
+	if stored.Summary != "Changed elsewhere" || stored.Location != "" {
+		t.Errorf("Concurrent change was overwritten: %+v", stored)
+	}
+
+	// Retrying against the current version succeeds
+	updated, err := client.UpdateEvent(ctx, event.ID, CreateEventParams{Location: "Room 2", IfMatch: current.ETag})
+	if err != nil {
+		t.Fatalf("UpdateEvent() with current etag error = %v", err)
+	}
+	if updated.Summary != "Changed elsewhere" || updated.Location != "Room 2" {
+		t.Errorf("Unexpected event: %+v", updated)
+	}
+}
+
+// TestUpdateEvent_Force tests that --force writes without checking the etag
+func TestUpdateEvent_Force(t *testing.T) {
+	client, backend, event := createRacingEvent(t)
+	backend.summary = "Changed elsewhere"
+
+	updated, err := client.UpdateEvent(context.Background(), event.ID, CreateEventParams{Summary: "Retro", Force: true})
+	if err != nil {
+		t.Fatalf("UpdateEvent() error = %v", err)
+	}
+	if updated.Summary != "Retro" {
+		t.Errorf("Unexpected event: %+v", updated)
+	}
+}
+
+// TestUpdateEvent_IfMatch tests updates conditional on a caller's etag
+func TestUpdateEvent_IfMatch(t *testing.T) {
+	ctx := context.Background()
+	client, _, event := createRacingEvent(t)
+
+	_, err := client.UpdateEvent(ctx, event.ID, CreateEventParams{Summary: "Stale", IfMatch: `"stale"`})
+
+	var appErr *types.AppError
+	if !errors.As(err, &appErr) || appErr.Code != types.ErrCodePreconditionFailed {
+		t.Fatalf("UpdateEvent() error = %v, want PRECONDITION_FAILED", err)
+	}
+	if current, ok := appErr.Current.(*types.Event); !ok || current.ETag != event.ETag {
+		t.Errorf("Current = %+v, want the stored event", appErr.Current)
+	}
+
+	updated, err := client.UpdateEvent(ctx, event.ID, CreateEventParams{Summary: "Fresh", IfMatch: event.ETag})
+	if err != nil {
+		t.Fatalf("UpdateEvent() error = %v", err)
+	}
+	if updated.Summary != "Fresh" || updated.ETag == event.ETag {
+		t.Errorf("Unexpected event: %+v", updated)
+	}
+}
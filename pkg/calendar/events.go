@@ -6,20 +6,44 @@ import (
 	"time"
 
 	"google.golang.org/api/calendar/v3"
+	"github.com/btafoya/gcal-cli/pkg/cache"
 	"github.com/btafoya/gcal-cli/pkg/types"
 )
 
 // CreateEventParams contains parameters for creating an event
 type CreateEventParams struct {
-	Summary     string
-	Description string
-	Location    string
-	Start       time.Time
-	End         time.Time
-	TimeZone    string
-	Attendees   []string
-	Recurrence  []string
-	AllDay      bool
+	Summary     string    `json:"summary,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Location    string    `json:"location,omitempty"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	TimeZone    string    `json:"timeZone,omitempty"`
+	Attendees   []string  `json:"attendees,omitempty"`
+	Recurrence  []string  `json:"recurrence,omitempty"`
+	AllDay      bool      `json:"allDay,omitempty"`
+	// Meet adds a Google Meet conference; updates keep an existing one
+	Meet bool `json:"meet,omitempty"`
+	// Properties and SharedProperties are private and shared extended
+	// properties; updates add or change the given keys and keep the others
+	Properties       map[string]string `json:"properties,omitempty"`
+	SharedProperties map[string]string `json:"sharedProperties,omitempty"`
+	// OutOfOffice, FocusTime or WorkingLocation makes the event of that type
+	// instead of a default event; at most one can be set
+	OutOfOffice     *types.OutOfOffice     `json:"outOfOffice,omitempty"`
+	FocusTime       *types.FocusTime       `json:"focusTime,omitempty"`
+	WorkingLocation *types.WorkingLocation `json:"workingLocation,omitempty"`
+
+	// ID is the event ID to create the event with. When empty, it is derived
+	// from IdempotencyKey, or random, so retries never create duplicates.
+	ID             string `json:"id,omitempty"`
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+
+	// IfMatch, for updates, is the etag the event must still have. Without
+	// it, the etag read before merging is used; Force skips the check.
+	IfMatch string `json:"ifMatch,omitempty"`
+	Force   bool   `json:"force,omitempty"`
+	// Clear lists fields an update removes from the event (ClearableFields)
+	Clear []string `json:"clear,omitempty"`
 }
 
 // ListEventsParams contains parameters for listing events
@@ -29,6 +53,12 @@ type ListEventsParams struct {
 	MaxResults int64
 	Query      string
 	OrderBy    string
+	// Properties and SharedProperties keep only events whose private or
+	// shared extended properties include every key=value given
+	Properties       []string
+	SharedProperties []string
+	// EventTypes keeps only events of these types; empty lists all types
+	EventTypes []string
 }
 
 // CreateEvent creates a new calendar event
@@ -38,8 +68,32 @@ func (c *Client) CreateEvent(ctx context.Context, params CreateEventParams) (*ty
 		return nil, err
 	}
 
-	// Build Google Calendar event
+	// Fix the event ID before the first attempt, so retries and replays of
+	// a queued create refer to the same event
+	if params.ID == "" {
+		params.ID = newEventID(params.IdempotencyKey)
+	}
+
+	// Create event with retry logic
+	event := buildEvent(params)
+	created, err := c.insertEvent(ctx, c.CalendarID, event, writeOptionsFor(event))
+	if err != nil {
+		return nil, c.queueOnFailure(handleAPIError(err, "create event", apiResource{"event", event.Id}), PendingWrite{
+			Operation:  WriteCreate,
+			CalendarID: c.CalendarID,
+			Params:     params,
+		})
+	}
+
+	c.invalidateCache(c.CalendarID, created.Id, created)
+
+	return convertEvent(created), nil
+}
+
+// buildEvent converts create parameters to a Google Calendar event
+func buildEvent(params CreateEventParams) *calendar.Event {
 	event := &calendar.Event{
+		Id:          params.ID,
 		Summary:     params.Summary,
 		Description: params.Description,
 		Location:    params.Location,
@@ -83,21 +137,14 @@ func (c *Client) CreateEvent(ctx context.Context, params CreateEventParams) (*ty
 		event.Recurrence = params.Recurrence
 	}
 
-	// Create event with retry logic
-	var created *calendar.Event
-	err := c.withRetry(ctx, "create event", func() error {
-		var err error
-		created, err = c.Service.Events.Insert(c.CalendarID, event).
-			Context(ctx).
-			Do()
-		return err
-	})
-
-	if err != nil {
-		return nil, handleAPIError(err, "create event")
+	if params.Meet {
+		event.ConferenceData = newMeetRequest()
 	}
 
-	return convertEvent(created), nil
+	event.ExtendedProperties = newExtendedProperties(params.Properties, params.SharedProperties)
+	applyEventType(event, params)
+
+	return event
 }
 
 // ListEvents lists events in a date range
@@ -113,33 +160,61 @@ func (c *Client) ListEvents(ctx context.Context, params ListEventsParams) ([]*ty
 	}
 
 	// Build list request
-	call := c.Service.Events.List(c.CalendarID).
-		Context(ctx).
-		TimeMin(params.From.Format(time.RFC3339)).
-		TimeMax(params.To.Format(time.RFC3339)).
-		MaxResults(params.MaxResults).
-		SingleEvents(true)
+	opts := ListOptions{
+		TimeMin:      params.From.Format(time.RFC3339),
+		TimeMax:      params.To.Format(time.RFC3339),
+		MaxResults:   params.MaxResults,
+		Query:        params.Query,
+		OrderBy:      params.OrderBy,
+		SingleEvents: true,
 
-	if params.Query != "" {
-		call = call.Q(params.Query)
+		PrivateExtendedProperty: params.Properties,
+		SharedExtendedProperty:  params.SharedProperties,
+		EventTypes:              params.EventTypes,
 	}
 
-	if params.OrderBy != "" {
-		call = call.OrderBy(params.OrderBy)
-	} else {
-		call = call.OrderBy("startTime")
+	if opts.OrderBy == "" {
+		opts.OrderBy = "startTime"
+	}
+
+	// Serve from the cache when possible
+	key := cache.ListKey{
+		CalendarID: c.CalendarID,
+		From:       params.From,
+		To:         params.To,
+		Query:      opts.Query,
+		OrderBy:    opts.OrderBy,
+		MaxResults: opts.MaxResults,
+	}
+	for _, filter := range params.Properties {
+		key.Properties = append(key.Properties, "private:"+filter)
+	}
+	for _, filter := range params.SharedProperties {
+		key.Properties = append(key.Properties, "shared:"+filter)
+	}
+	for _, kind := range params.EventTypes {
+		key.Properties = append(key.Properties, "eventType:"+kind)
+	}
+	if c.Cache != nil {
+		if result, ok := c.Cache.GetList(key); ok && (c.Offline || !result.Stale) {
+			return result.Events, nil
+		}
+	}
+	if c.Offline {
+		return nil, types.ErrCacheMiss("event list").
+			WithDetails(fmt.Sprintf("calendar: %s, from: %s, to: %s", c.CalendarID, opts.TimeMin, opts.TimeMax))
 	}
 
 	// Execute with retry logic
 	var eventsList *calendar.Events
 	err := c.withRetry(ctx, "list events", func() error {
 		var err error
-		eventsList, err = call.Do()
+		eventsList, err = c.Backend.ListEvents(ctx, c.CalendarID, opts)
 		return err
 	})
 
 	if err != nil {
-		return nil, handleAPIError(err, "list events")
+		return nil, handleAPIError(err, "list events", apiResource{"calendar", c.CalendarID})
 	}
 
 	// Convert events
@@ -148,6 +223,10 @@ func (c *Client) ListEvents(ctx context.Context, params ListEventsParams) ([]*ty
 		events[i] = convertEvent(item)
 	}
 
+	if c.Cache != nil {
+		c.Cache.PutList(key, events)
+	}
+
 	return events, nil
 }
 
@@ -157,129 +236,128 @@ func (c *Client) GetEvent(ctx context.Context, eventID string) (*types.Event, er
 		return nil, types.ErrMissingRequired("event-id")
 	}
 
+	// Serve from the cache when possible
+	if c.Cache != nil {
+		if result, ok := c.Cache.GetEvent(c.CalendarID, eventID); ok && (c.Offline || !result.Stale) {
+			return result.Events[0], nil
+		}
+	}
+	if c.Offline {
+		return nil, types.ErrCacheMiss("event").
+			WithDetails(fmt.Sprintf("ID: %s", eventID))
+	}
+
+	event, err := c.fetchEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Cache != nil {
+		c.Cache.PutEvent(c.CalendarID, event)
+	}
+
+	return event, nil
+}
+
+// fetchEvent retrieves a single event from the backend, bypassing the cache
+func (c *Client) fetchEvent(ctx context.Context, eventID string) (*types.Event, error) {
 	var event *calendar.Event
 	err := c.withRetry(ctx, "get event", func() error {
 		var err error
-		event, err = c.Service.Events.Get(c.CalendarID, eventID).
-			Context(ctx).
-			Do()
+		event, err = c.Backend.GetEvent(ctx, c.CalendarID, eventID)
 		return err
 	})
 
 	if err != nil {
-		return nil, handleAPIError(err, "get event")
+		return nil, handleAPIError(err, "get event", apiResource{"event", eventID})
 	}
 
 	return convertEvent(event), nil
 }
 
+// getSourceEvent reads the full event in its API form, bypassing the cache,
+// which only keeps the fields the CLI shows
+func (c *Client) getSourceEvent(ctx context.Context, calendarID, eventID string) (*calendar.Event, error) {
+	var event *calendar.Event
+	err := c.withRetry(ctx, "get event", func() error {
+		var err error
+		event, err = c.Backend.GetEvent(ctx, calendarID, eventID)
+		return err
+	})
+	if err != nil {
+		return nil, handleAPIError(err, "get event", apiResource{"event", eventID})
+	}
+	return event, nil
+}
+
 // UpdateEvent updates an existing event
 func (c *Client) UpdateEvent(ctx context.Context, eventID string, params CreateEventParams) (*types.Event, error) {
 	if eventID == "" {
 		return nil, types.ErrMissingRequired("event-id")
 	}
-
-	// Get existing event first
-	existing, err := c.GetEvent(ctx, eventID)
-	if err != nil {
+	if err := validateClearFields(params); err != nil {
 		return nil, err
 	}
-
-	// Build updated event (merge with existing)
-	event := &calendar.Event{
-		Summary:     params.Summary,
-		Description: params.Description,
-		Location:    params.Location,
+	if err := validateProperties("prop", params.Properties); err != nil {
+		return nil, err
 	}
-
-	// Use existing values if not provided
-	if params.Summary == "" {
-		event.Summary = existing.Summary
+	if err := validateProperties("shared-prop", params.SharedProperties); err != nil {
+		return nil, err
 	}
-	if params.Description == "" {
-		event.Description = existing.Description
+	if err := validateEventType(params); err != nil {
+		return nil, err
 	}
-	if params.Location == "" {
-		event.Location = existing.Location
+
+	write := PendingWrite{
+		Operation:  WriteUpdate,
+		CalendarID: c.CalendarID,
+		EventID:    eventID,
+		Params:     params,
 	}
 
-	// Set start and end times
-	if !params.Start.IsZero() && !params.End.IsZero() {
-		if params.AllDay {
-			event.Start = &calendar.EventDateTime{
-				Date: params.Start.Format("2006-01-02"),
-			}
-			event.End = &calendar.EventDateTime{
-				Date: params.End.Format("2006-01-02"),
-			}
-		} else {
-			tz := params.TimeZone
-			if tz == "" {
-				tz = existing.Start.TimeZone
-			}
-			event.Start = &calendar.EventDateTime{
-				DateTime: params.Start.Format(time.RFC3339),
-				TimeZone: tz,
-			}
-			event.End = &calendar.EventDateTime{
-				DateTime: params.End.Format(time.RFC3339),
-				TimeZone: tz,
-			}
-		}
-	} else {
-		// Keep existing times
-		event.Start = &calendar.EventDateTime{
-			DateTime: existing.Start.DateTime,
-			TimeZone: existing.Start.TimeZone,
-			Date:     existing.Start.Date,
-		}
-		event.End = &calendar.EventDateTime{
-			DateTime: existing.End.DateTime,
-			TimeZone: existing.End.TimeZone,
-			Date:     existing.End.Date,
-		}
+	// Get existing event first; its etag must not come from a cached copy.
+	// The API form is kept so the patch can carry attendees over whole.
+	source, err := c.getSourceEvent(ctx, c.CalendarID, eventID)
+	if err != nil {
+		return nil, c.queueOnFailure(err, write)
 	}
+	existing := convertEvent(source)
+	write.ETag = existing.ETag
 
-	// Update attendees if provided
-	if len(params.Attendees) > 0 {
-		event.Attendees = make([]*calendar.EventAttendee, len(params.Attendees))
-		for i, email := range params.Attendees {
-			event.Attendees[i] = &calendar.EventAttendee{
-				Email: email,
-			}
-		}
-	} else {
-		// Keep existing attendees
-		event.Attendees = make([]*calendar.EventAttendee, len(existing.Attendees))
-		for i, att := range existing.Attendees {
-			event.Attendees[i] = &calendar.EventAttendee{
-				Email:          att.Email,
-				ResponseStatus: att.ResponseStatus,
-			}
-		}
+	if kind := eventType(params); kind != EventTypeDefault && existing.EventType != kind {
+		return nil, types.ErrInvalidInput("eventType",
+			fmt.Sprintf("event %s is a %s event; its type cannot be changed to %s", eventID, existing.EventType, kind))
 	}
 
-	// Update recurrence if provided
-	if len(params.Recurrence) > 0 {
-		event.Recurrence = params.Recurrence
-	} else if len(existing.Recurrence) > 0 {
-		event.Recurrence = existing.Recurrence
+	// Only write over the version that was read, so concurrent edits are
+	// not silently lost
+	patch := buildEventPatch(params, source)
+	opts := writeOptionsFor(patch)
+	if !params.Force {
+		if params.IfMatch != "" && params.IfMatch != existing.ETag {
+			return nil, eventChangedError(eventID, params.IfMatch, existing)
+		}
+		opts.IfMatch = existing.ETag
 	}
 
 	// Update with retry logic
 	var updated *calendar.Event
 	err = c.withRetry(ctx, "update event", func() error {
 		var err error
-		updated, err = c.Service.Events.Update(c.CalendarID, eventID, event).
-			Context(ctx).
-			Do()
+		updated, err = c.Backend.PatchEvent(ctx, c.CalendarID, eventID, patch, opts)
 		return err
 	})
 
 	if err != nil {
-		return nil, handleAPIError(err, "update event")
+		appErr := handleAPIError(err, "update event", apiResource{"event", eventID})
+		if isPreconditionFailed(appErr) {
+			return nil, c.eventChanged(ctx, eventID, opts.IfMatch, appErr)
+		}
+		return nil, c.queueOnFailure(appErr, write)
 	}
 
+	c.invalidateCache(c.CalendarID, eventID, updated)
+
 	return convertEvent(updated), nil
 }
 
@@ -290,18 +368,56 @@ func (c *Client) DeleteEvent(ctx context.Context, eventID string) error {
 	}
 
 	err := c.withRetry(ctx, "delete event", func() error {
-		return c.Service.Events.Delete(c.CalendarID, eventID).
-			Context(ctx).
-			Do()
+		return c.Backend.DeleteEvent(ctx, c.CalendarID, eventID)
 	})
 
 	if err != nil {
-		return handleAPIError(err, "delete event")
+		return c.queueOnFailure(handleAPIError(err, "delete event", apiResource{"event", eventID}), PendingWrite{
+			Operation:  WriteDelete,
+			CalendarID: c.CalendarID,
+			EventID:    eventID,
+		})
 	}
 
+	c.invalidateCache(c.CalendarID, eventID)
+
 	return nil
 }
 
+// ListInstances lists the occurrences of a recurring event in a date range
+func (c *Client) ListInstances(ctx context.Context, eventID string, from, to time.Time) ([]*types.Event, error) {
+	if eventID == "" {
+		return nil, types.ErrMissingRequired("event-id")
+	}
+
+	if !to.After(from) {
+		return nil, types.NewAppError(types.ErrCodeInvalidTimeRange,
+			"end time must be after start time", true).
+			WithDetails(fmt.Sprintf("from: %s, to: %s", from.Format(time.RFC3339), to.Format(time.RFC3339)))
+	}
+
+	var instances *calendar.Events
+	err := c.withRetry(ctx, "list instances", func() error {
+		var err error
+		instances, err = c.Backend.ListInstances(ctx, c.CalendarID, eventID, ListOptions{
+			TimeMin: from.Format(time.RFC3339),
+			TimeMax: to.Format(time.RFC3339),
+		})
+		return err
+	})
+
+	if err != nil {
+		return nil, handleAPIError(err, "list instances", apiResource{"event", eventID})
+	}
+
+	events := make([]*types.Event, len(instances.Items))
+	for i, item := range instances.Items {
+		events[i] = convertEvent(item)
+	}
+
+	return events, nil
+}
+
 // convertEvent converts Google Calendar event to our Event type
 func convertEvent(event *calendar.Event) *types.Event {
 	if event == nil {
@@ -314,7 +430,17 @@ func convertEvent(event *calendar.Event) *types.Event {
 		Description: event.Description,
 		Location:    event.Location,
 		Status:      event.Status,
+		ETag:        event.Etag,
+
+		RecurringEventID: event.RecurringEventId,
+	}
+
+	if event.Organizer != nil {
+		result.Organizer = event.Organizer.Email
 	}
+	result.Conference = convertConference(event.ConferenceData)
+	result.ExtendedProperties = convertExtendedProperties(event.ExtendedProperties)
+	convertEventType(result, event)
 
 	// Convert start time
 	if event.Start != nil {
@@ -341,6 +467,10 @@ func convertEvent(event *calendar.Event) *types.Event {
 			result.Attendees[i] = types.Attendee{
 				Email:          att.Email,
 				ResponseStatus: att.ResponseStatus,
+				Organizer:      att.Organizer,
+				DisplayName:    att.DisplayName,
+				Comment:        att.Comment,
+				Self:           att.Self,
 			}
 		}
 	}
@@ -384,6 +514,20 @@ func validateCreateParams(params CreateEventParams) error {
 		}
 	}
 
+	if err := validateProperties("prop", params.Properties); err != nil {
+		return err
+	}
+	if err := validateProperties("shared-prop", params.SharedProperties); err != nil {
+		return err
+	}
+	if err := validateEventType(params); err != nil {
+		return err
+	}
+
+	if params.ID != "" {
+		return validateEventID(params.ID)
+	}
+
 	return nil
 }
 
@@ -416,6 +560,16 @@ func validateListParams(params ListEventsParams) error {
 			"must be 'startTime' or 'updated'")
 	}
 
+	if err := validatePropertyFilters("has-prop", params.Properties); err != nil {
+		return err
+	}
+	if err := validatePropertyFilters("has-shared-prop", params.SharedProperties); err != nil {
+		return err
+	}
+	if err := validateEventTypeFilters(params.EventTypes); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -0,0 +1,219 @@
+package calendar
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/btafoya/gcal-cli/pkg/types"
+	"google.golang.org/api/calendar/v3"
+)
+
+// Event types. Birthday and fromGmail events can be listed but not created.
+const (
+	EventTypeDefault         = "default"
+	EventTypeOutOfOffice     = "outOfOffice"
+	EventTypeFocusTime       = "focusTime"
+	EventTypeWorkingLocation = "workingLocation"
+	EventTypeBirthday        = "birthday"
+	EventTypeFromGmail       = "fromGmail"
+)
+
+// EventTypes are the event types listings can be filtered by
+var EventTypes = []string{
+	EventTypeDefault, EventTypeOutOfOffice, EventTypeFocusTime,
+	EventTypeWorkingLocation, EventTypeBirthday, EventTypeFromGmail,
+}
+
+// DeclineModes are how out-of-office and focus time events answer
+// conflicting invitations
+var DeclineModes = []string{
+	"declineNone",
+	"declineAllConflictingInvitations",
+	"declineOnlyNewConflictingInvitations",
+}
+
+// ChatStatuses are the Chat statuses a focus time event can set
+var ChatStatuses = []string{"available", "doNotDisturb"}
+
+// Working location types
+const (
+	WorkingLocationHome   = "homeOffice"
+	WorkingLocationOffice = "officeLocation"
+	WorkingLocationCustom = "customLocation"
+)
+
+// eventType returns the type of event params create
+func eventType(params CreateEventParams) string {
+	switch {
+	case params.OutOfOffice != nil:
+		return EventTypeOutOfOffice
+	case params.FocusTime != nil:
+		return EventTypeFocusTime
+	case params.WorkingLocation != nil:
+		return EventTypeWorkingLocation
+	default:
+		return EventTypeDefault
+	}
+}
+
+// validateEventType checks the properties of an out-of-office, focus time or
+// working location event
+func validateEventType(params CreateEventParams) error {
+	set := 0
+	for _, isSet := range []bool{params.OutOfOffice != nil, params.FocusTime != nil, params.WorkingLocation != nil} {
+		if isSet {
+			set++
+		}
+	}
+	if set > 1 {
+		return types.ErrInvalidInput("eventType",
+			"only one of outOfOffice, focusTime and workingLocation can be set")
+	}
+
+	kind := eventType(params)
+	if kind == EventTypeDefault {
+		return nil
+	}
+	if len(params.Attendees) > 0 {
+		return types.ErrInvalidInput("attendees",
+			fmt.Sprintf("%s events cannot have attendees", kind))
+	}
+	if params.Meet {
+		return types.ErrInvalidInput("meet",
+			fmt.Sprintf("%s events cannot have a conference", kind))
+	}
+
+	switch kind {
+	case EventTypeOutOfOffice:
+		return validateDeclineMode(params.OutOfOffice.AutoDeclineMode)
+	case EventTypeFocusTime:
+		if params.AllDay {
+			return types.ErrInvalidInput("all-day", "focus time cannot be an all-day event").
+				WithSuggestedAction("Give a start and end time")
+		}
+		if err := validateDeclineMode(params.FocusTime.AutoDeclineMode); err != nil {
+			return err
+		}
+		return validateOneOf("chat-status", params.FocusTime.ChatStatus, ChatStatuses)
+	default:
+		location := params.WorkingLocation
+		switch location.Type {
+		case WorkingLocationHome, WorkingLocationOffice:
+			return nil
+		case WorkingLocationCustom:
+			if location.Label == "" {
+				return types.ErrMissingRequired("label").
+					WithDetails("a custom working location needs a label")
+			}
+			return nil
+		default:
+			return types.ErrInvalidInput("type",
+				fmt.Sprintf("working location type must be one of %s, got '%s'",
+					strings.Join([]string{WorkingLocationHome, WorkingLocationOffice, WorkingLocationCustom}, ", "), location.Type))
+		}
+	}
+}
+
+// validateDeclineMode checks an auto-decline mode; empty leaves the default
+func validateDeclineMode(mode string) error {
+	return validateOneOf("decline-mode", mode, DeclineModes)
+}
+
+// validateOneOf checks that value, when set, is one of valid
+func validateOneOf(field, value string, valid []string) error {
+	if value == "" {
+		return nil
+	}
+	for _, v := range valid {
+		if value == v {
+			return nil
+		}
+	}
+	return types.ErrInvalidInput(field,
+		fmt.Sprintf("must be one of %s, got '%s'", strings.Join(valid, ", "), value))
+}
+
+// validateEventTypeFilters checks the types a listing is filtered by
+func validateEventTypeFilters(eventTypes []string) error {
+	for _, kind := range eventTypes {
+		if err := validateOneOf("event-type", kind, EventTypes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyEventType sets the type and type properties of event from params. A
+// working location is shown to everyone who can see the calendar and never
+// makes the user busy.
+func applyEventType(event *calendar.Event, params CreateEventParams) {
+	switch {
+	case params.OutOfOffice != nil:
+		event.EventType = EventTypeOutOfOffice
+		event.OutOfOfficeProperties = &calendar.EventOutOfOfficeProperties{
+			AutoDeclineMode: params.OutOfOffice.AutoDeclineMode,
+			DeclineMessage:  params.OutOfOffice.DeclineMessage,
+		}
+	case params.FocusTime != nil:
+		event.EventType = EventTypeFocusTime
+		event.FocusTimeProperties = &calendar.EventFocusTimeProperties{
+			AutoDeclineMode: params.FocusTime.AutoDeclineMode,
+			DeclineMessage:  params.FocusTime.DeclineMessage,
+			ChatStatus:      params.FocusTime.ChatStatus,
+		}
+	case params.WorkingLocation != nil:
+		location := params.WorkingLocation
+		properties := &calendar.EventWorkingLocationProperties{Type: location.Type}
+		switch location.Type {
+		case WorkingLocationHome:
+			properties.HomeOffice = struct{}{}
+		case WorkingLocationOffice:
+			properties.OfficeLocation = &calendar.EventWorkingLocationPropertiesOfficeLocation{
+				Label:      location.Label,
+				BuildingId: location.BuildingID,
+				FloorId:    location.FloorID,
+				DeskId:     location.DeskID,
+			}
+		case WorkingLocationCustom:
+			properties.CustomLocation = &calendar.EventWorkingLocationPropertiesCustomLocation{
+				Label: location.Label,
+			}
+		}
+		event.EventType = EventTypeWorkingLocation
+		event.WorkingLocationProperties = properties
+		event.Visibility = "public"
+		event.Transparency = "transparent"
+	}
+}
+
+// convertEventType copies the type properties of an event into result
+func convertEventType(result *types.Event, event *calendar.Event) {
+	result.EventType = event.EventType
+
+	if p := event.OutOfOfficeProperties; p != nil {
+		result.OutOfOffice = &types.OutOfOffice{
+			AutoDeclineMode: p.AutoDeclineMode,
+			DeclineMessage:  p.DeclineMessage,
+		}
+	}
+	if p := event.FocusTimeProperties; p != nil {
+		result.FocusTime = &types.FocusTime{
+			AutoDeclineMode: p.AutoDeclineMode,
+			DeclineMessage:  p.DeclineMessage,
+			ChatStatus:      p.ChatStatus,
+		}
+	}
+	if p := event.WorkingLocationProperties; p != nil {
+		location := &types.WorkingLocation{Type: p.Type}
+		if p.OfficeLocation != nil {
+			location.Label = p.OfficeLocation.Label
+			location.BuildingID = p.OfficeLocation.BuildingId
+			location.FloorID = p.OfficeLocation.FloorId
+			location.DeskID = p.OfficeLocation.DeskId
+		}
+		if p.CustomLocation != nil {
+			location.Label = p.CustomLocation.Label
+		}
+		result.WorkingLocation = location
+	}
+}
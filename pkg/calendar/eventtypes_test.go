@@ -0,0 +1,184 @@
+package calendar
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/btafoya/gcal-cli/pkg/types"
+)
+
+// TestCreateEvent_EventTypes tests creating out-of-office, focus time and
+// working location events and filtering listings by type
+func TestCreateEvent_EventTypes(t *testing.T) {
+	ctx := context.Background()
+	client := NewClientWithBackend(NewFakeBackend(), "primary")
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	ooo, err := client.CreateEvent(ctx, CreateEventParams{
+		Summary: "Vacation",
+		Start:   day,
+		End:     day.AddDate(0, 0, 5),
+		OutOfOffice: &types.OutOfOffice{
+			AutoDeclineMode: "declineAllConflictingInvitations",
+			DeclineMessage:  "Back on the 22nd",
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateEvent(outOfOffice) error = %v", err)
+	}
+	if ooo.EventType != EventTypeOutOfOffice || ooo.OutOfOffice == nil ||
+		ooo.OutOfOffice.AutoDeclineMode != "declineAllConflictingInvitations" || ooo.OutOfOffice.DeclineMessage != "Back on the 22nd" {
+		t.Errorf("Out-of-office event = %+v, settings %+v", ooo, ooo.OutOfOffice)
+	}
+
+	focus, err := client.CreateEvent(ctx, CreateEventParams{
+		Summary:   "Focus time",
+		Start:     day.Add(9 * time.Hour),
+		End:       day.Add(11 * time.Hour),
+		FocusTime: &types.FocusTime{ChatStatus: "doNotDisturb"},
+	})
+	if err != nil {
+		t.Fatalf("CreateEvent(focusTime) error = %v", err)
+	}
+	if focus.EventType != EventTypeFocusTime || focus.FocusTime == nil || focus.FocusTime.ChatStatus != "doNotDisturb" {
+		t.Errorf("Focus time event = %+v, settings %+v", focus, focus.FocusTime)
+	}
+
+	office, err := client.CreateEvent(ctx, CreateEventParams{
+		Summary: "HQ",
+		Start:   day,
+		End:     day.AddDate(0, 0, 1),
+		AllDay:  true,
+		WorkingLocation: &types.WorkingLocation{
+			Type:   WorkingLocationOffice,
+			Label:  "HQ",
+			DeskID: "4-12",
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateEvent(workingLocation) error = %v", err)
+	}
+	want := &types.WorkingLocation{Type: WorkingLocationOffice, Label: "HQ", DeskID: "4-12"}
+	if office.EventType != EventTypeWorkingLocation || !reflect.DeepEqual(office.WorkingLocation, want) {
+		t.Errorf("Working location = %q %+v, want %+v", office.EventType, office.WorkingLocation, want)
+	}
+
+	plain, err := client.CreateEvent(ctx, CreateEventParams{
+		Summary: "Standup",
+		Start:   day.Add(12 * time.Hour),
+		End:     day.Add(13 * time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("CreateEvent(default) error = %v", err)
+	}
+	if plain.EventType != EventTypeDefault {
+		t.Errorf("EventType = %q, want %q", plain.EventType, EventTypeDefault)
+	}
+
+	tests := []struct {
+		eventTypes []string
+		want       []string
+	}{
+		{nil, []string{"Focus time", "HQ", "Standup", "Vacation"}},
+		{[]string{EventTypeDefault}, []string{"Standup"}},
+		{[]string{EventTypeOutOfOffice, EventTypeFocusTime}, []string{"Focus time", "Vacation"}},
+	}
+	for _, tt := range tests {
+		events, err := client.ListEvents(ctx, ListEventsParams{
+			From:       day,
+			To:         day.AddDate(0, 0, 1),
+			EventTypes: tt.eventTypes,
+		})
+		if err != nil {
+			t.Fatalf("ListEvents(%v) error = %v", tt.eventTypes, err)
+		}
+		got := make([]string, len(events))
+		for i, event := range events {
+			got[i] = event.Summary
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ListEvents(%v) = %v, want %v", tt.eventTypes, got, tt.want)
+		}
+	}
+
+	if _, err := client.ListEvents(ctx, ListEventsParams{
+		From:       day,
+		To:         day.AddDate(0, 0, 1),
+		EventTypes: []string{"holiday"},
+	}); err == nil {
+		t.Error("ListEvents() with an unknown event type succeeded, want error")
+	}
+}
+
+// TestCreateEvent_EventTypeValidation tests rejected special events
+func TestCreateEvent_EventTypeValidation(t *testing.T) {
+	client := NewClientWithBackend(NewFakeBackend(), "primary")
+	start := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		params CreateEventParams
+	}{
+		{"two types", CreateEventParams{OutOfOffice: &types.OutOfOffice{}, FocusTime: &types.FocusTime{}}},
+		{"attendees", CreateEventParams{OutOfOffice: &types.OutOfOffice{}, Attendees: []string{"a@example.com"}}},
+		{"unknown decline mode", CreateEventParams{OutOfOffice: &types.OutOfOffice{AutoDeclineMode: "declineAll"}}},
+		{"all-day focus time", CreateEventParams{FocusTime: &types.FocusTime{}, AllDay: true}},
+		{"unknown chat status", CreateEventParams{FocusTime: &types.FocusTime{ChatStatus: "away"}}},
+		{"unknown location", CreateEventParams{WorkingLocation: &types.WorkingLocation{Type: "cafe"}}},
+		{"custom without label", CreateEventParams{WorkingLocation: &types.WorkingLocation{Type: WorkingLocationCustom}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := tt.params
+			params.Summary = "Special"
+			params.Start = start
+			params.End = start.Add(time.Hour)
+
+			_, err := client.CreateEvent(context.Background(), params)
+			appErr, ok := err.(*types.AppError)
+			if !ok || appErr.Code != types.ErrCodeInvalidInput && appErr.Code != types.ErrCodeMissingRequired {
+				t.Errorf("CreateEvent() error = %v, want a validation error", err)
+			}
+		})
+	}
+}
+
+// TestUpdateEvent_EventType tests changing the settings of a special event,
+// but not its type
+func TestUpdateEvent_EventType(t *testing.T) {
+	ctx := context.Background()
+	client := NewClientWithBackend(NewFakeBackend(), "primary")
+	start := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+
+	focus, err := client.CreateEvent(ctx, CreateEventParams{
+		Summary:   "Focus time",
+		Start:     start,
+		End:       start.Add(2 * time.Hour),
+		FocusTime: &types.FocusTime{ChatStatus: "available"},
+	})
+	if err != nil {
+		t.Fatalf("CreateEvent() error = %v", err)
+	}
+
+	updated, err := client.UpdateEvent(ctx, focus.ID, CreateEventParams{
+		FocusTime: &types.FocusTime{ChatStatus: "doNotDisturb"},
+	})
+	if err != nil {
+		t.Fatalf("UpdateEvent() error = %v", err)
+	}
+	if updated.EventType != EventTypeFocusTime || updated.FocusTime.ChatStatus != "doNotDisturb" {
+		t.Errorf("Updated event = %q %+v, want focus time with doNotDisturb", updated.EventType, updated.FocusTime)
+	}
+
+	_, err = client.UpdateEvent(ctx, focus.ID, CreateEventParams{
+		OutOfOffice: &types.OutOfOffice{},
+	})
+	if appErr, ok := err.(*types.AppError); !ok || appErr.Code != types.ErrCodeInvalidInput {
+		t.Errorf("UpdateEvent() changing the type error = %v, want INVALID_INPUT", err)
+	}
+}
@@ -0,0 +1,1283 @@
+package calendar
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/btafoya/gcal-cli/pkg/types"
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// FakePrimaryCalendarID is the ID of the primary calendar in an empty FakeBackend
+const FakePrimaryCalendarID = "fake-user@example.com"
+
+// maxFakeInstances caps recurrence expansion for unbounded rules
+const maxFakeInstances = 1000
+
+// FakeSeed is the JSON document used to seed and persist a FakeBackend
+type FakeSeed struct {
+	Calendars []FakeCalendarSeed `json:"calendars"`
+}
+
+// FakeCalendarSeed describes one calendar with its events and ACL rules
+type FakeCalendarSeed struct {
+	ID          string              `json:"id"`
+	Summary     string              `json:"summary,omitempty"`
+	Description string              `json:"description,omitempty"`
+	TimeZone    string              `json:"timeZone,omitempty"`
+	Primary     bool                `json:"primary,omitempty"`
+	AccessRole  string              `json:"accessRole,omitempty"`
+	Events      []*calendar.Event   `json:"events,omitempty"`
+	ACL         []*calendar.AclRule `json:"acl,omitempty"`
+}
+
+// FakeBackend is an in-memory Backend for tests and offline use. It mimics
+// the Calendar API closely enough to exercise client logic: events with
+// recurrence expansion, instances, free/busy, ACL rules and the calendar list.
+type FakeBackend struct {
+	mu          sync.Mutex
+	calendars   map[string]*fakeCalendar
+	order       []string
+	sequence    int
+	persistPath string
+}
+
+type fakeCalendar struct {
+	entry  *calendar.CalendarListEntry
+	events map[string]*calendar.Event
+	acl    map[string]*calendar.AclRule
+}
+
+// NewFakeBackend creates a fake backend with a single empty primary calendar
+func NewFakeBackend() *FakeBackend {
+	fb := &FakeBackend{calendars: make(map[string]*fakeCalendar)}
+	fb.addCalendar(FakeCalendarSeed{
+		ID:         FakePrimaryCalendarID,
+		Summary:    "Fake Calendar",
+		TimeZone:   "UTC",
+		Primary:    true,
+		AccessRole: "owner",
+	})
+	return fb
+}
+
+// NewFakeBackendFromSeed creates a fake backend populated from a seed document
+func NewFakeBackendFromSeed(seed FakeSeed) *FakeBackend {
+	if len(seed.Calendars) == 0 {
+		return NewFakeBackend()
+	}
+
+	fb := &FakeBackend{calendars: make(map[string]*fakeCalendar)}
+	for _, cal := range seed.Calendars {
+		fb.addCalendar(cal)
+	}
+	return fb
+}
+
+// LoadFakeBackend creates a fake backend from a seed JSON file
+func LoadFakeBackend(path string) (*FakeBackend, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, types.NewAppError(types.ErrCodeFileError, "file operation failed", false).
+			WithDetails("could not read fake backend seed: " + path).
+			WithWrappedError(err)
+	}
+
+	var seed FakeSeed
+	if err := json.Unmarshal(data, &seed); err != nil {
+		return nil, types.NewAppError(types.ErrCodeInvalidFormat,
+			"invalid fake backend seed", true).
+			WithDetails(err.Error()).
+			WithWrappedError(err)
+	}
+
+	return NewFakeBackendFromSeed(seed), nil
+}
+
+// PersistTo makes the backend write its state to path after every change
+func (fb *FakeBackend) PersistTo(path string) {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+	fb.persistPath = path
+}
+
+// Snapshot returns the current state as a seed document
+func (fb *FakeBackend) Snapshot() FakeSeed {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+	return fb.snapshot()
+}
+
+// Save writes the current state to a seed JSON file
+func (fb *FakeBackend) Save(path string) error {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+	return fb.save(path)
+}
+
+// InsertEvent implements Backend
+func (fb *FakeBackend) InsertEvent(ctx context.Context, calendarID string, event *calendar.Event, opts WriteOptions) (*calendar.Event, error) {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	cal, err := fb.calendar(calendarID)
+	if err != nil {
+		return nil, err
+	}
+
+	stored := cloneEvent(event)
+	if stored.Id == "" {
+		stored.Id = fb.newEventID(cal)
+	}
+	if _, exists := cal.events[stored.Id]; exists {
+		return nil, fakeError(http.StatusConflict, "duplicate", "The requested identifier already exists.")
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	if stored.Status == "" {
+		stored.Status = "confirmed"
+	}
+	if stored.ICalUID == "" {
+		stored.ICalUID = stored.Id + "@fake.local"
+	}
+	if stored.EventType == "" {
+		stored.EventType = EventTypeDefault
+	}
+	stored.Created = now
+	stored.Updated = now
+	stored.Etag = fb.nextEtag()
+	stored.HtmlLink = "https://calendar.fake.local/event?eid=" + stored.Id
+	stored.Organizer = &calendar.EventOrganizer{Email: cal.entry.Id, Self: true}
+	stored.Creator = &calendar.EventCreator{Email: cal.entry.Id, Self: true}
+	for _, att := range stored.Attendees {
+		if att.ResponseStatus == "" {
+			att.ResponseStatus = "needsAction"
+		}
+	}
+	markSelf(stored, cal)
+	fakeConference(stored, nil, "", opts)
+
+	cal.events[stored.Id] = stored
+	if err := fb.persist(); err != nil {
+		return nil, err
+	}
+
+	return cloneEvent(stored), nil
+}
+
+// GetEvent implements Backend
+func (fb *FakeBackend) GetEvent(ctx context.Context, calendarID, eventID string) (*calendar.Event, error) {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	cal, err := fb.calendar(calendarID)
+	if err != nil {
+		return nil, err
+	}
+
+	event, ok := fb.event(cal, eventID)
+	if !ok {
+		return nil, fakeError(http.StatusNotFound, "notFound", "Not Found")
+	}
+
+	return cloneEvent(event), nil
+}
+
+// ListEvents implements Backend
+func (fb *FakeBackend) ListEvents(ctx context.Context, calendarID string, opts ListOptions) (*calendar.Events, error) {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	cal, err := fb.calendar(calendarID)
+	if err != nil {
+		return nil, err
+	}
+
+	timeMin, timeMax, err := parseWindow(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.OrderBy == "startTime" && !opts.SingleEvents {
+		return nil, fakeError(http.StatusBadRequest, "invalid",
+			"The requested ordering is not available for the particular query.")
+	}
+
+	items := make([]*calendar.Event, 0)
+	for _, event := range cal.events {
+		if event.Status == "cancelled" && !opts.ShowDeleted {
+			continue
+		}
+		if !matchesQuery(event, opts.Query) || !matchesProperties(event, opts) || !matchesEventType(event, opts) {
+			continue
+		}
+
+		if opts.SingleEvents && len(event.Recurrence) > 0 {
+			items = append(items, expandInstances(cal, event, timeMin, timeMax, opts.ShowDeleted)...)
+			continue
+		}
+		if opts.SingleEvents && event.RecurringEventId != "" {
+			continue // listed in place of its instance
+		}
+
+		if overlapsWindow(event, timeMin, timeMax) {
+			items = append(items, cloneEvent(event))
+		}
+	}
+
+	sortEvents(items, opts.OrderBy)
+	return paginate(cal, items, opts)
+}
+
+// UpdateEvent implements Backend
+func (fb *FakeBackend) UpdateEvent(ctx context.Context, calendarID, eventID string, event *calendar.Event, opts WriteOptions) (*calendar.Event, error) {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	return fb.replaceEvent(calendarID, eventID, opts, func(*calendar.Event) (*calendar.Event, error) {
+		return cloneEvent(event), nil
+	})
+}
+
+// PatchEvent implements Backend
+func (fb *FakeBackend) PatchEvent(ctx context.Context, calendarID, eventID string, patch *calendar.Event, opts WriteOptions) (*calendar.Event, error) {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	return fb.replaceEvent(calendarID, eventID, opts, func(existing *calendar.Event) (*calendar.Event, error) {
+		// With attendeesOmitted, the listed attendees update the stored ones
+		// instead of replacing the whole list
+		if patch.AttendeesOmitted {
+			patch = cloneEvent(patch)
+			patch.Attendees = mergeAttendees(existing.Attendees, patch.Attendees)
+			patch.AttendeesOmitted = false
+		}
+		return mergeEventPatch(existing, patch)
+	})
+}
+
+// replaceEvent stores a new version of an event, built from the stored one
+// by change. Changing a single instance of a recurring event stores it as an
+// exception. Callers must hold the lock.
+func (fb *FakeBackend) replaceEvent(calendarID, eventID string, opts WriteOptions, change func(existing *calendar.Event) (*calendar.Event, error)) (*calendar.Event, error) {
+	cal, err := fb.calendar(calendarID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, ok := fb.event(cal, eventID)
+	if !ok {
+		return nil, fakeError(http.StatusNotFound, "notFound", "Not Found")
+	}
+	if opts.IfMatch != "" && opts.IfMatch != existing.Etag {
+		return nil, fakeError(http.StatusPreconditionFailed, "conditionNotMet", "Precondition Failed")
+	}
+
+	updated, err := change(existing)
+	if err != nil {
+		return nil, err
+	}
+	if updated.EventType != "" && updated.EventType != fakeEventType(existing) {
+		return nil, fakeError(http.StatusBadRequest, "eventTypeRestriction", "The event type cannot be changed.")
+	}
+	updated.EventType = existing.EventType
+	updated.Id = existing.Id
+	updated.ICalUID = existing.ICalUID
+	updated.Created = existing.Created
+	updated.HtmlLink = existing.HtmlLink
+	updated.Organizer = existing.Organizer
+	updated.Creator = existing.Creator
+	updated.Updated = time.Now().UTC().Format(time.RFC3339)
+	updated.Etag = fb.nextEtag()
+	updated.Sequence = existing.Sequence + 1
+	updated.RecurringEventId = existing.RecurringEventId
+	if updated.OriginalStartTime == nil {
+		updated.OriginalStartTime = existing.OriginalStartTime
+	}
+	if updated.Status == "" {
+		updated.Status = existing.Status
+	}
+	markSelf(updated, cal)
+	fakeConference(updated, existing.ConferenceData, existing.HangoutLink, opts)
+
+	cal.events[eventID] = updated
+	if err := fb.persist(); err != nil {
+		return nil, err
+	}
+
+	return cloneEvent(updated), nil
+}
+
+// DeleteEvent implements Backend. Like the API, it keeps the event as
+// cancelled, so its ID stays taken; a deleted instance of a recurring event
+// becomes a cancelled exception.
+func (fb *FakeBackend) DeleteEvent(ctx context.Context, calendarID, eventID string) error {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	cal, err := fb.calendar(calendarID)
+	if err != nil {
+		return err
+	}
+
+	event, ok := fb.event(cal, eventID)
+	if !ok {
+		if _, taken := cal.events[eventID]; taken {
+			return fakeError(http.StatusGone, "deleted", "Resource has been deleted")
+		}
+		return fakeError(http.StatusNotFound, "notFound", "Not Found")
+	}
+
+	event.Status = "cancelled"
+	event.Updated = time.Now().UTC().Format(time.RFC3339)
+	event.Etag = fb.nextEtag()
+	cal.events[eventID] = event
+	return fb.persist()
+}
+
+// MoveEvent implements Backend
+func (fb *FakeBackend) MoveEvent(ctx context.Context, calendarID, eventID, destinationID string, opts WriteOptions) (*calendar.Event, error) {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	source, err := fb.calendar(calendarID)
+	if err != nil {
+		return nil, err
+	}
+	destination, err := fb.calendar(destinationID)
+	if err != nil {
+		return nil, err
+	}
+
+	event, ok := source.events[eventID]
+	if !ok || event.Status == "cancelled" {
+		return nil, fakeError(http.StatusNotFound, "notFound", "Not Found")
+	}
+	if _, exists := destination.events[eventID]; exists {
+		return nil, fakeError(http.StatusConflict, "duplicate", "The requested identifier already exists.")
+	}
+
+	// The destination calendar becomes the organizer
+	event.Organizer = &calendar.EventOrganizer{Email: destination.entry.Id, Self: true}
+	markSelf(event, destination)
+	event.Updated = time.Now().UTC().Format(time.RFC3339)
+	event.Etag = fb.nextEtag()
+
+	delete(source.events, eventID)
+	destination.events[eventID] = event
+	if err := fb.persist(); err != nil {
+		return nil, err
+	}
+
+	return cloneEvent(event), nil
+}
+
+// ListInstances implements Backend
+func (fb *FakeBackend) ListInstances(ctx context.Context, calendarID, eventID string, opts ListOptions) (*calendar.Events, error) {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	cal, err := fb.calendar(calendarID)
+	if err != nil {
+		return nil, err
+	}
+
+	event, ok := cal.events[eventID]
+	if !ok || event.Status == "cancelled" {
+		return nil, fakeError(http.StatusNotFound, "notFound", "Not Found")
+	}
+
+	timeMin, timeMax, err := parseWindow(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*calendar.Event
+	if len(event.Recurrence) > 0 {
+		items = expandInstances(cal, event, timeMin, timeMax, opts.ShowDeleted)
+	} else if overlapsWindow(event, timeMin, timeMax) {
+		items = []*calendar.Event{cloneEvent(event)}
+	}
+
+	sortEvents(items, "startTime")
+	return paginate(cal, items, opts)
+}
+
+// QuickAddEvent implements Backend, reading the text with ParseQuickAdd in
+// UTC in place of the server's parser
+func (fb *FakeBackend) QuickAddEvent(ctx context.Context, calendarID, text string, opts WriteOptions) (*calendar.Event, error) {
+	params, err := ParseQuickAdd(text, time.UTC, time.Hour)
+	if err != nil {
+		return nil, fakeError(http.StatusBadRequest, "invalid", err.Error())
+	}
+	return fb.InsertEvent(ctx, calendarID, buildEvent(params), opts)
+}
+
+// QueryFreeBusy implements Backend
+func (fb *FakeBackend) QueryFreeBusy(ctx context.Context, request *calendar.FreeBusyRequest) (*calendar.FreeBusyResponse, error) {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	timeMin, err := time.Parse(time.RFC3339, request.TimeMin)
+	if err != nil {
+		return nil, fakeError(http.StatusBadRequest, "invalid", "Bad Request: timeMin")
+	}
+	timeMax, err := time.Parse(time.RFC3339, request.TimeMax)
+	if err != nil {
+		return nil, fakeError(http.StatusBadRequest, "invalid", "Bad Request: timeMax")
+	}
+
+	response := &calendar.FreeBusyResponse{
+		Kind:      "calendar#freeBusy",
+		TimeMin:   request.TimeMin,
+		TimeMax:   request.TimeMax,
+		Calendars: make(map[string]calendar.FreeBusyCalendar),
+	}
+
+	for _, item := range request.Items {
+		cal, err := fb.calendar(item.Id)
+		if err != nil {
+			response.Calendars[item.Id] = calendar.FreeBusyCalendar{
+				Errors: []*calendar.Error{{Domain: "global", Reason: "notFound"}},
+			}
+			continue
+		}
+
+		var periods []timeRange
+		for _, event := range cal.events {
+			if event.Status == "cancelled" || event.Transparency == "transparent" {
+				continue
+			}
+
+			occurrences := []*calendar.Event{event}
+			if len(event.Recurrence) > 0 {
+				occurrences = expandRecurrence(event, timeMin, timeMax)
+			}
+
+			for _, occurrence := range occurrences {
+				start, end, ok := eventRange(occurrence)
+				if !ok || !start.Before(timeMax) || !end.After(timeMin) {
+					continue
+				}
+				// Busy periods are clipped to the query window
+				if start.Before(timeMin) {
+					start = timeMin
+				}
+				if end.After(timeMax) {
+					end = timeMax
+				}
+				periods = append(periods, timeRange{start, end})
+			}
+		}
+
+		busy := make([]*calendar.TimePeriod, 0)
+		for _, period := range mergeRanges(periods) {
+			busy = append(busy, &calendar.TimePeriod{
+				Start: period.start.UTC().Format(time.RFC3339),
+				End:   period.end.UTC().Format(time.RFC3339),
+			})
+		}
+		response.Calendars[item.Id] = calendar.FreeBusyCalendar{Busy: busy}
+	}
+
+	return response, nil
+}
+
+// ListACL implements Backend
+func (fb *FakeBackend) ListACL(ctx context.Context, calendarID string) (*calendar.Acl, error) {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	cal, err := fb.calendar(calendarID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(cal.acl))
+	for id := range cal.acl {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	acl := &calendar.Acl{Kind: "calendar#acl", Items: make([]*calendar.AclRule, 0, len(ids))}
+	for _, id := range ids {
+		rule := *cal.acl[id]
+		acl.Items = append(acl.Items, &rule)
+	}
+
+	return acl, nil
+}
+
+// InsertACL implements Backend
+func (fb *FakeBackend) InsertACL(ctx context.Context, calendarID string, rule *calendar.AclRule) (*calendar.AclRule, error) {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	cal, err := fb.calendar(calendarID)
+	if err != nil {
+		return nil, err
+	}
+
+	if rule.Scope == nil || rule.Scope.Type == "" {
+		return nil, fakeError(http.StatusBadRequest, "required", "Missing scope")
+	}
+
+	stored := *rule
+	stored.Scope = &calendar.AclRuleScope{Type: rule.Scope.Type, Value: rule.Scope.Value}
+	stored.Id = aclRuleID(stored.Scope)
+	stored.Kind = "calendar#aclRule"
+	stored.Etag = fb.nextEtag()
+	cal.acl[stored.Id] = &stored
+
+	if err := fb.persist(); err != nil {
+		return nil, err
+	}
+
+	result := stored
+	return &result, nil
+}
+
+// DeleteACL implements Backend
+func (fb *FakeBackend) DeleteACL(ctx context.Context, calendarID, ruleID string) error {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	cal, err := fb.calendar(calendarID)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := cal.acl[ruleID]; !ok {
+		return fakeError(http.StatusNotFound, "notFound", "Not Found")
+	}
+
+	delete(cal.acl, ruleID)
+	return fb.persist()
+}
+
+// ListCalendars implements Backend
+func (fb *FakeBackend) ListCalendars(ctx context.Context) (*calendar.CalendarList, error) {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	list := &calendar.CalendarList{Kind: "calendar#calendarList"}
+	for _, id := range fb.order {
+		entry := *fb.calendars[id].entry
+		list.Items = append(list.Items, &entry)
+	}
+
+	return list, nil
+}
+
+// GetCalendar implements Backend
+func (fb *FakeBackend) GetCalendar(ctx context.Context, calendarID string) (*calendar.Calendar, error) {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	cal, err := fb.calendar(calendarID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &calendar.Calendar{
+		Kind:        "calendar#calendar",
+		Id:          cal.entry.Id,
+		Summary:     cal.entry.Summary,
+		Description: cal.entry.Description,
+		TimeZone:    cal.entry.TimeZone,
+	}, nil
+}
+
+// addCalendar adds a seeded calendar; callers must hold the lock or own fb
+func (fb *FakeBackend) addCalendar(seed FakeCalendarSeed) {
+	accessRole := seed.AccessRole
+	if accessRole == "" {
+		accessRole = "owner"
+	}
+
+	cal := &fakeCalendar{
+		entry: &calendar.CalendarListEntry{
+			Kind:        "calendar#calendarListEntry",
+			Id:          seed.ID,
+			Summary:     seed.Summary,
+			Description: seed.Description,
+			TimeZone:    seed.TimeZone,
+			Primary:     seed.Primary,
+			AccessRole:  accessRole,
+		},
+		events: make(map[string]*calendar.Event),
+		acl:    make(map[string]*calendar.AclRule),
+	}
+
+	for _, event := range seed.Events {
+		stored := cloneEvent(event)
+		if stored.Id == "" {
+			stored.Id = fb.newEventID(cal)
+		}
+		if stored.Status == "" {
+			stored.Status = "confirmed"
+		}
+		if stored.Etag == "" {
+			stored.Etag = fb.nextEtag()
+		}
+		markSelf(stored, cal)
+		cal.events[stored.Id] = stored
+	}
+
+	if len(seed.ACL) == 0 && accessRole == "owner" {
+		seed.ACL = []*calendar.AclRule{{
+			Role:  "owner",
+			Scope: &calendar.AclRuleScope{Type: "user", Value: seed.ID},
+		}}
+	}
+	for _, rule := range seed.ACL {
+		stored := *rule
+		if stored.Scope == nil {
+			stored.Scope = &calendar.AclRuleScope{Type: "default"}
+		}
+		if stored.Id == "" {
+			stored.Id = aclRuleID(stored.Scope)
+		}
+		cal.acl[stored.Id] = &stored
+	}
+
+	fb.calendars[seed.ID] = cal
+	fb.order = append(fb.order, seed.ID)
+}
+
+// calendar resolves a calendar ID, including the "primary" alias
+func (fb *FakeBackend) calendar(calendarID string) (*fakeCalendar, error) {
+	if calendarID == "primary" {
+		for _, id := range fb.order {
+			if fb.calendars[id].entry.Primary {
+				return fb.calendars[id], nil
+			}
+		}
+	}
+
+	cal, ok := fb.calendars[calendarID]
+	if !ok {
+		return nil, fakeError(http.StatusNotFound, "notFound", "Not Found")
+	}
+
+	return cal, nil
+}
+
+// event finds an event by ID, including single instances of recurring
+// events ("<id>_<start>"), which are built from the series until they are
+// changed. Deleted events are not found. Callers must hold the lock.
+func (fb *FakeBackend) event(cal *fakeCalendar, eventID string) (*calendar.Event, bool) {
+	if event, ok := cal.events[eventID]; ok {
+		return event, event.Status != "cancelled"
+	}
+
+	i := strings.LastIndex(eventID, "_")
+	if i < 0 {
+		return nil, false
+	}
+	series, ok := cal.events[eventID[:i]]
+	if !ok || len(series.Recurrence) == 0 || series.Status == "cancelled" {
+		return nil, false
+	}
+
+	occurrence, err := time.Parse("20060102T150405Z", eventID[i+1:])
+	if err != nil {
+		if occurrence, err = time.Parse("20060102", eventID[i+1:]); err != nil {
+			return nil, false
+		}
+	}
+	for _, instance := range expandRecurrence(series, occurrence, occurrence.Add(time.Second)) {
+		if instance.Id == eventID {
+			return instance, true
+		}
+	}
+	return nil, false
+}
+
+// newEventID generates an event ID that is not yet used in the calendar.
+// The sequence restarts when state is reloaded, so taken IDs are skipped.
+func (fb *FakeBackend) newEventID(cal *fakeCalendar) string {
+	for {
+		id := fmt.Sprintf("fakeevt%06d", fb.nextSequence())
+		if _, exists := cal.events[id]; !exists {
+			return id
+		}
+	}
+}
+
+func (fb *FakeBackend) nextSequence() int {
+	fb.sequence++
+	return fb.sequence
+}
+
+func (fb *FakeBackend) nextEtag() string {
+	return fmt.Sprintf(`"%d"`, fb.nextSequence())
+}
+
+func (fb *FakeBackend) snapshot() FakeSeed {
+	seed := FakeSeed{Calendars: make([]FakeCalendarSeed, 0, len(fb.order))}
+
+	for _, id := range fb.order {
+		cal := fb.calendars[id]
+		calSeed := FakeCalendarSeed{
+			ID:          cal.entry.Id,
+			Summary:     cal.entry.Summary,
+			Description: cal.entry.Description,
+			TimeZone:    cal.entry.TimeZone,
+			Primary:     cal.entry.Primary,
+			AccessRole:  cal.entry.AccessRole,
+		}
+
+		for _, event := range cal.events {
+			calSeed.Events = append(calSeed.Events, cloneEvent(event))
+		}
+		sortEvents(calSeed.Events, "startTime")
+
+		for _, rule := range cal.acl {
+			r := *rule
+			calSeed.ACL = append(calSeed.ACL, &r)
+		}
+		sort.Slice(calSeed.ACL, func(i, j int) bool { return calSeed.ACL[i].Id < calSeed.ACL[j].Id })
+
+		seed.Calendars = append(seed.Calendars, calSeed)
+	}
+
+	return seed
+}
+
+func (fb *FakeBackend) save(path string) error {
+	data, err := json.MarshalIndent(fb.snapshot(), "", "  ")
+	if err != nil {
+		return types.NewAppError(types.ErrCodeFileError, "file operation failed", false).
+			WithDetails("could not marshal fake backend state").
+			WithWrappedError(err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return types.NewAppError(types.ErrCodeFileError, "file operation failed", false).
+			WithDetails("could not write fake backend state: " + path).
+			WithWrappedError(err)
+	}
+
+	return nil
+}
+
+// persist saves state if persistence is enabled; callers must hold the lock
+func (fb *FakeBackend) persist() error {
+	if fb.persistPath == "" {
+		return nil
+	}
+	return fb.save(fb.persistPath)
+}
+
+// fakeError builds an API error shaped like the ones Google returns
+func fakeError(code int, reason, message string) error {
+	return &googleapi.Error{
+		Code:    code,
+		Message: message,
+		Errors:  []googleapi.ErrorItem{{Reason: reason, Message: message}},
+	}
+}
+
+// fakeConference applies the conference data of a write the way the API
+// does: ignored without conferenceDataVersion 1, keeping the previous data,
+// and a Meet create request is completed at once
+func fakeConference(event *calendar.Event, previous *calendar.ConferenceData, hangoutLink string, opts WriteOptions) {
+	if opts.ConferenceDataVersion < 1 {
+		event.ConferenceData = previous
+		event.HangoutLink = hangoutLink
+		return
+	}
+
+	data := event.ConferenceData
+	if data == nil || data.CreateRequest == nil || len(data.EntryPoints) > 0 {
+		return
+	}
+
+	sum := sha256.Sum256([]byte(data.CreateRequest.RequestId))
+	letters := make([]byte, 10)
+	for i := range letters {
+		letters[i] = 'a' + sum[i]%26
+	}
+	code := fmt.Sprintf("%s-%s-%s", letters[:3], letters[3:7], letters[7:])
+	link := "https://meet.fake.local/" + code
+
+	data.ConferenceId = code
+	data.ConferenceSolution = &calendar.ConferenceSolution{
+		Name: "Google Meet",
+		Key:  &calendar.ConferenceSolutionKey{Type: meetSolutionType},
+	}
+	data.CreateRequest.Status = &calendar.ConferenceRequestStatus{StatusCode: "success"}
+	data.EntryPoints = []*calendar.EntryPoint{
+		{EntryPointType: "video", Uri: link, Label: "meet.fake.local/" + code},
+		{EntryPointType: "phone", Uri: "tel:+1-555-0100", Label: "+1 555-0100",
+			Pin: fmt.Sprintf("%09d", binary.BigEndian.Uint32(sum[10:14])%1000000000)},
+	}
+	event.HangoutLink = link
+}
+
+// markSelf flags the attendee that is the calendar holding the event, as the
+// API does for each calendar's copy
+func markSelf(event *calendar.Event, cal *fakeCalendar) {
+	for _, att := range event.Attendees {
+		att.Self = strings.EqualFold(att.Email, cal.entry.Id)
+	}
+}
+
+// mergeAttendees updates the attendees in existing that appear in changes,
+// matched by email, and adds the others
+func mergeAttendees(existing, changes []*calendar.EventAttendee) []*calendar.EventAttendee {
+	merged := make([]*calendar.EventAttendee, 0, len(existing)+len(changes))
+	changed := make(map[string]*calendar.EventAttendee, len(changes))
+	for _, att := range changes {
+		changed[strings.ToLower(att.Email)] = att
+	}
+
+	for _, att := range existing {
+		if change, ok := changed[strings.ToLower(att.Email)]; ok {
+			delete(changed, strings.ToLower(att.Email))
+			att = change
+		}
+		merged = append(merged, att)
+	}
+	for _, att := range changes {
+		if _, ok := changed[strings.ToLower(att.Email)]; ok {
+			merged = append(merged, att)
+		}
+	}
+	return merged
+}
+
+// mergeEventPatch applies a patch to an event the way the API does: set
+// fields replace stored ones, objects are merged, lists are replaced and
+// null fields are cleared (JSON merge patch, RFC 7396)
+func mergeEventPatch(existing, patch *calendar.Event) (*calendar.Event, error) {
+	var target, changes map[string]interface{}
+	data, _ := json.Marshal(existing)
+	json.Unmarshal(data, &target)
+
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return nil, fakeError(http.StatusBadRequest, "invalid", "Invalid patch: "+err.Error())
+	}
+	json.Unmarshal(data, &changes)
+
+	data, _ = json.Marshal(mergePatch(target, changes))
+	var merged calendar.Event
+	if err := json.Unmarshal(data, &merged); err != nil {
+		return nil, fakeError(http.StatusBadRequest, "invalid", "Invalid patch: "+err.Error())
+	}
+	return &merged, nil
+}
+
+// mergePatch merges the JSON object patch into target
+func mergePatch(target, patch map[string]interface{}) map[string]interface{} {
+	if target == nil {
+		target = make(map[string]interface{})
+	}
+	for key, value := range patch {
+		switch value := value.(type) {
+		case nil:
+			delete(target, key)
+		case map[string]interface{}:
+			existing, _ := target[key].(map[string]interface{})
+			target[key] = mergePatch(existing, value)
+		default:
+			target[key] = value
+		}
+	}
+	return target
+}
+
+// cloneEvent deep-copies an event so callers never share stored state
+func cloneEvent(event *calendar.Event) *calendar.Event {
+	data, _ := json.Marshal(event)
+	var clone calendar.Event
+	json.Unmarshal(data, &clone)
+	return &clone
+}
+
+// aclRuleID builds a rule ID in the same "type:value" form Google uses
+func aclRuleID(scope *calendar.AclRuleScope) string {
+	if scope.Value == "" {
+		return scope.Type
+	}
+	return scope.Type + ":" + scope.Value
+}
+
+// timeRange is a half-open interval [start, end)
+type timeRange struct {
+	start, end time.Time
+}
+
+// mergeRanges sorts and merges overlapping or touching ranges
+func mergeRanges(ranges []timeRange) []timeRange {
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].start.Before(ranges[j].start) })
+
+	merged := make([]timeRange, 0, len(ranges))
+	for _, r := range ranges {
+		if n := len(merged); n > 0 && !r.start.After(merged[n-1].end) {
+			if r.end.After(merged[n-1].end) {
+				merged[n-1].end = r.end
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+
+	return merged
+}
+
+// parseWindow parses the optional time bounds of a list query
+func parseWindow(opts ListOptions) (time.Time, time.Time, error) {
+	var timeMin, timeMax time.Time
+	var err error
+
+	if opts.TimeMin != "" {
+		if timeMin, err = time.Parse(time.RFC3339, opts.TimeMin); err != nil {
+			return timeMin, timeMax, fakeError(http.StatusBadRequest, "invalid", "Bad Request: timeMin")
+		}
+	}
+	if opts.TimeMax != "" {
+		if timeMax, err = time.Parse(time.RFC3339, opts.TimeMax); err != nil {
+			return timeMin, timeMax, fakeError(http.StatusBadRequest, "invalid", "Bad Request: timeMax")
+		}
+	}
+
+	return timeMin, timeMax, nil
+}
+
+// eventRange returns the start and end of an event as instants
+func eventRange(event *calendar.Event) (time.Time, time.Time, bool) {
+	start, ok := parseEventDateTime(event.Start)
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	end, ok := parseEventDateTime(event.End)
+	if !ok {
+		end = start
+	}
+	return start, end, true
+}
+
+// parseEventDateTime parses a timed or all-day event boundary
+func parseEventDateTime(edt *calendar.EventDateTime) (time.Time, bool) {
+	if edt == nil {
+		return time.Time{}, false
+	}
+	if edt.DateTime != "" {
+		t, err := time.Parse(time.RFC3339, edt.DateTime)
+		return t, err == nil
+	}
+	if edt.Date != "" {
+		t, err := time.Parse("2006-01-02", edt.Date)
+		return t, err == nil
+	}
+	return time.Time{}, false
+}
+
+// overlapsWindow reports whether an event intersects the optional window
+func overlapsWindow(event *calendar.Event, timeMin, timeMax time.Time) bool {
+	start, end, ok := eventRange(event)
+	if !ok {
+		return true
+	}
+	if !timeMax.IsZero() && !start.Before(timeMax) {
+		return false
+	}
+	if !timeMin.IsZero() && !end.After(timeMin) {
+		return false
+	}
+	return true
+}
+
+// matchesQuery performs the free-text match used by the q parameter
+func matchesQuery(event *calendar.Event, query string) bool {
+	if query == "" {
+		return true
+	}
+
+	query = strings.ToLower(query)
+	fields := []string{event.Summary, event.Description, event.Location}
+	for _, att := range event.Attendees {
+		fields = append(fields, att.Email, att.DisplayName)
+	}
+
+	for _, field := range fields {
+		if strings.Contains(strings.ToLower(field), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesProperties reports whether an event has every extended property
+// the listing asks for
+func matchesProperties(event *calendar.Event, opts ListOptions) bool {
+	var private, shared map[string]string
+	if event.ExtendedProperties != nil {
+		private, shared = event.ExtendedProperties.Private, event.ExtendedProperties.Shared
+	}
+
+	for _, filters := range []struct {
+		properties map[string]string
+		want       []string
+	}{
+		{private, opts.PrivateExtendedProperty},
+		{shared, opts.SharedExtendedProperty},
+	} {
+		for _, filter := range filters.want {
+			key, value, _ := strings.Cut(filter, "=")
+			if got, ok := filters.properties[key]; !ok || got != value {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// matchesEventType reports whether an event is of a type the listing asks
+// for
+func matchesEventType(event *calendar.Event, opts ListOptions) bool {
+	if len(opts.EventTypes) == 0 {
+		return true
+	}
+	for _, kind := range opts.EventTypes {
+		if kind == fakeEventType(event) {
+			return true
+		}
+	}
+	return false
+}
+
+// fakeEventType returns the type of a stored event; seeded events without
+// one are default events
+func fakeEventType(event *calendar.Event) string {
+	if event.EventType == "" {
+		return EventTypeDefault
+	}
+	return event.EventType
+}
+
+// sortEvents orders events by start time or last update
+func sortEvents(events []*calendar.Event, orderBy string) {
+	sort.SliceStable(events, func(i, j int) bool {
+		if orderBy == "updated" {
+			return events[i].Updated < events[j].Updated
+		}
+		startI, _, _ := eventRange(events[i])
+		startJ, _, _ := eventRange(events[j])
+		if startI.Equal(startJ) {
+			return events[i].Id < events[j].Id
+		}
+		return startI.Before(startJ)
+	})
+}
+
+// paginate applies MaxResults and PageToken to a result set
+func paginate(cal *fakeCalendar, items []*calendar.Event, opts ListOptions) (*calendar.Events, error) {
+	offset := 0
+	if opts.PageToken != "" {
+		var err error
+		if offset, err = strconv.Atoi(opts.PageToken); err != nil || offset < 0 || offset > len(items) {
+			return nil, fakeError(http.StatusBadRequest, "invalid", "Invalid page token")
+		}
+	}
+
+	result := &calendar.Events{
+		Kind:     "calendar#events",
+		Summary:  cal.entry.Summary,
+		TimeZone: cal.entry.TimeZone,
+		Items:    items[offset:],
+	}
+
+	if opts.MaxResults > 0 && int64(len(result.Items)) > opts.MaxResults {
+		result.Items = result.Items[:opts.MaxResults]
+		result.NextPageToken = strconv.Itoa(offset + int(opts.MaxResults))
+	}
+
+	return result, nil
+}
+
+// recurrenceRule is the subset of RFC 5545 RRULE supported by the fake
+type recurrenceRule struct {
+	freq     string
+	interval int
+	count    int
+	until    time.Time
+	byDay    map[time.Weekday]bool
+}
+
+// parseRecurrenceRule parses the first RRULE line of a recurrence list
+func parseRecurrenceRule(recurrence []string) (*recurrenceRule, bool) {
+	for _, line := range recurrence {
+		if !strings.HasPrefix(line, "RRULE:") {
+			continue
+		}
+
+		rule := &recurrenceRule{interval: 1}
+		for _, part := range strings.Split(strings.TrimPrefix(line, "RRULE:"), ";") {
+			key, value, _ := strings.Cut(part, "=")
+			switch key {
+			case "FREQ":
+				rule.freq = value
+			case "INTERVAL":
+				if n, err := strconv.Atoi(value); err == nil && n > 0 {
+					rule.interval = n
+				}
+			case "COUNT":
+				rule.count, _ = strconv.Atoi(value)
+			case "UNTIL":
+				for _, layout := range []string{"20060102T150405Z", "20060102"} {
+					if t, err := time.Parse(layout, value); err == nil {
+						rule.until = t
+						break
+					}
+				}
+			case "BYDAY":
+				rule.byDay = make(map[time.Weekday]bool)
+				days := map[string]time.Weekday{
+					"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday,
+					"WE": time.Wednesday, "TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+				}
+				for _, day := range strings.Split(value, ",") {
+					if weekday, ok := days[day]; ok {
+						rule.byDay[weekday] = true
+					}
+				}
+			}
+		}
+
+		switch rule.freq {
+		case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+			return rule, true
+		}
+	}
+
+	return nil, false
+}
+
+// expandInstances lists the instances of a recurring event within the
+// window, with changed instances replaced by their stored exceptions.
+// Deleted instances are left out unless showDeleted is set.
+func expandInstances(cal *fakeCalendar, event *calendar.Event, timeMin, timeMax time.Time, showDeleted bool) []*calendar.Event {
+	instances := make([]*calendar.Event, 0)
+	for _, instance := range expandRecurrence(event, timeMin, timeMax) {
+		if exception, ok := cal.events[instance.Id]; ok {
+			instance = cloneEvent(exception)
+		}
+		if instance.Status == "cancelled" && !showDeleted {
+			continue
+		}
+		instances = append(instances, instance)
+	}
+	return instances
+}
+
+// expandRecurrence expands a recurring event into instances within the window
+func expandRecurrence(event *calendar.Event, timeMin, timeMax time.Time) []*calendar.Event {
+	start, end, ok := eventRange(event)
+	rule, hasRule := parseRecurrenceRule(event.Recurrence)
+	if !ok || !hasRule {
+		if overlapsWindow(event, timeMin, timeMax) {
+			return []*calendar.Event{cloneEvent(event)}
+		}
+		return nil
+	}
+
+	duration := end.Sub(start)
+	allDay := event.Start.Date != ""
+	instances := make([]*calendar.Event, 0)
+	emitted := 0
+
+	emit := func(occurrence time.Time) bool {
+		if rule.count > 0 && emitted >= rule.count {
+			return false
+		}
+		if !rule.until.IsZero() && occurrence.After(rule.until) {
+			return false
+		}
+		if !timeMax.IsZero() && !occurrence.Before(timeMax) {
+			return false
+		}
+		emitted++
+
+		if timeMin.IsZero() || occurrence.Add(duration).After(timeMin) {
+			instances = append(instances, newInstance(event, occurrence, duration, allDay))
+		}
+		return emitted < maxFakeInstances
+	}
+
+	for period := 0; ; period++ {
+		var base time.Time
+		switch rule.freq {
+		case "DAILY":
+			base = start.AddDate(0, 0, period*rule.interval)
+		case "WEEKLY":
+			base = start.AddDate(0, 0, 7*period*rule.interval)
+		case "MONTHLY":
+			base = start.AddDate(0, period*rule.interval, 0)
+		case "YEARLY":
+			base = start.AddDate(period*rule.interval, 0, 0)
+		}
+
+		if rule.freq == "WEEKLY" && len(rule.byDay) > 0 {
+			// Walk the week that begins on the base occurrence's weekday
+			for offset := 0; offset < 7; offset++ {
+				occurrence := base.AddDate(0, 0, offset)
+				if !rule.byDay[occurrence.Weekday()] {
+					continue
+				}
+				if !emit(occurrence) {
+					return instances
+				}
+			}
+			continue
+		}
+
+		if !emit(base) {
+			return instances
+		}
+	}
+}
+
+// newInstance builds a single occurrence of a recurring event
+func newInstance(event *calendar.Event, occurrence time.Time, duration time.Duration, allDay bool) *calendar.Event {
+	instance := cloneEvent(event)
+	instance.Recurrence = nil
+	instance.RecurringEventId = event.Id
+
+	if allDay {
+		instance.Id = event.Id + "_" + occurrence.Format("20060102")
+		instance.Start = &calendar.EventDateTime{Date: occurrence.Format("2006-01-02")}
+		instance.End = &calendar.EventDateTime{Date: occurrence.Add(duration).Format("2006-01-02")}
+		instance.OriginalStartTime = &calendar.EventDateTime{Date: instance.Start.Date}
+		return instance
+	}
+
+	instance.Id = event.Id + "_" + occurrence.UTC().Format("20060102T150405Z")
+	instance.Start = &calendar.EventDateTime{
+		DateTime: occurrence.Format(time.RFC3339),
+		TimeZone: event.Start.TimeZone,
+	}
+	instance.End = &calendar.EventDateTime{
+		DateTime: occurrence.Add(duration).Format(time.RFC3339),
+		TimeZone: event.End.TimeZone,
+	}
+	instance.OriginalStartTime = &calendar.EventDateTime{
+		DateTime: instance.Start.DateTime,
+		TimeZone: instance.Start.TimeZone,
+	}
+	return instance
+}
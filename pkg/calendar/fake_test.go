@@ -0,0 +1,291 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// TestFakeBackend_EventLifecycle tests insert, get, update and delete
+func TestFakeBackend_EventLifecycle(t *testing.T) {
+	ctx := context.Background()
+	fb := NewFakeBackend()
+
+	created, err := fb.InsertEvent(ctx, "primary", createTestEvent(), WriteOptions{})
+	if err != nil {
+		t.Fatalf("InsertEvent() error = %v", err)
+	}
+	if created.Etag == "" || created.Created == "" {
+		t.Errorf("Expected server-owned fields to be set, got %+v", created)
+	}
+
+	// Inserting the same ID again must conflict
+	_, err = fb.InsertEvent(ctx, "primary", createTestEvent(), WriteOptions{})
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) || apiErr.Code != 409 {
+		t.Errorf("Expected 409 conflict, got %v", err)
+	}
+
+	// Mutating the returned event must not change stored state
+	created.Summary = "Mutated"
+	got, err := fb.GetEvent(ctx, FakePrimaryCalendarID, created.Id)
+	if err != nil {
+		t.Fatalf("GetEvent() error = %v", err)
+	}
+	if got.Summary != "Test Event" {
+		t.Errorf("Expected stored summary 'Test Event', got '%s'", got.Summary)
+	}
+
+	got.Summary = "Updated"
+	updated, err := fb.UpdateEvent(ctx, "primary", created.Id, got, WriteOptions{})
+	if err != nil {
+		t.Fatalf("UpdateEvent() error = %v", err)
+	}
+	if updated.Summary != "Updated" || updated.Etag == created.Etag || updated.Sequence != 1 {
+		t.Errorf("Unexpected updated event: %+v", updated)
+	}
+
+	if err := fb.DeleteEvent(ctx, "primary", created.Id); err != nil {
+		t.Fatalf("DeleteEvent() error = %v", err)
+	}
+	_, err = fb.GetEvent(ctx, "primary", created.Id)
+	if !errors.As(err, &apiErr) || apiErr.Code != 404 {
+		t.Errorf("Expected 404 after delete, got %v", err)
+	}
+
+	// The deleted event is kept as cancelled and its ID stays taken
+	window := ListOptions{TimeMin: "2024-01-01T00:00:00Z", TimeMax: "2025-01-01T00:00:00Z"}
+	if listed, _ := fb.ListEvents(ctx, "primary", window); len(listed.Items) != 0 {
+		t.Errorf("Expected no listed events after delete, got %d", len(listed.Items))
+	}
+	window.ShowDeleted = true
+	if listed, _ := fb.ListEvents(ctx, "primary", window); len(listed.Items) != 1 || listed.Items[0].Status != "cancelled" {
+		t.Errorf("Expected the cancelled event with ShowDeleted, got %+v", listed.Items)
+	}
+	_, err = fb.InsertEvent(ctx, "primary", createTestEvent(), WriteOptions{})
+	if !errors.As(err, &apiErr) || apiErr.Code != 409 {
+		t.Errorf("Expected 409 reusing a deleted event's ID, got %v", err)
+	}
+	err = fb.DeleteEvent(ctx, "primary", created.Id)
+	if !errors.As(err, &apiErr) || apiErr.Code != 410 {
+		t.Errorf("Expected 410 deleting again, got %v", err)
+	}
+}
+
+// TestFakeBackend_Recurrence tests expansion of recurring events
+func TestFakeBackend_Recurrence(t *testing.T) {
+	ctx := context.Background()
+	fb := NewFakeBackend()
+
+	event := &calendar.Event{
+		Id:         "weekly",
+		Summary:    "Team Sync",
+		Start:      &calendar.EventDateTime{DateTime: "2024-01-01T10:00:00Z"},
+		End:        &calendar.EventDateTime{DateTime: "2024-01-01T10:30:00Z"},
+		Recurrence: []string{"RRULE:FREQ=WEEKLY;BYDAY=MO,WE;COUNT=5"},
+	}
+	if _, err := fb.InsertEvent(ctx, "primary", event, WriteOptions{}); err != nil {
+		t.Fatalf("InsertEvent() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		opts     ListOptions
+		expected int
+	}{
+		{"recurring master only", ListOptions{}, 1},
+		{"all instances", ListOptions{SingleEvents: true}, 5},
+		{"window", ListOptions{SingleEvents: true, TimeMin: "2024-01-02T00:00:00Z", TimeMax: "2024-01-09T00:00:00Z"}, 2},
+		{"max results", ListOptions{SingleEvents: true, MaxResults: 2}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := fb.ListEvents(ctx, "primary", tt.opts)
+			if err != nil {
+				t.Fatalf("ListEvents() error = %v", err)
+			}
+			if len(events.Items) != tt.expected {
+				t.Errorf("Expected %d events, got %d", tt.expected, len(events.Items))
+			}
+		})
+	}
+
+	instances, err := fb.ListInstances(ctx, "primary", "weekly", ListOptions{})
+	if err != nil {
+		t.Fatalf("ListInstances() error = %v", err)
+	}
+	if len(instances.Items) != 5 {
+		t.Fatalf("Expected 5 instances, got %d", len(instances.Items))
+	}
+
+	expectedStarts := []string{
+		"2024-01-01T10:00:00Z", "2024-01-03T10:00:00Z", "2024-01-08T10:00:00Z",
+		"2024-01-10T10:00:00Z", "2024-01-15T10:00:00Z",
+	}
+	for i, instance := range instances.Items {
+		if instance.Start.DateTime != expectedStarts[i] {
+			t.Errorf("Instance %d: expected start %s, got %s", i, expectedStarts[i], instance.Start.DateTime)
+		}
+		if instance.RecurringEventId != "weekly" {
+			t.Errorf("Instance %d: expected recurringEventId 'weekly', got '%s'", i, instance.RecurringEventId)
+		}
+	}
+}
+
+// TestFakeBackend_FreeBusy tests busy period merging and unknown calendars
+func TestFakeBackend_FreeBusy(t *testing.T) {
+	ctx := context.Background()
+	fb := NewFakeBackend()
+
+	for _, event := range []*calendar.Event{
+		{Start: &calendar.EventDateTime{DateTime: "2024-01-15T09:00:00Z"}, End: &calendar.EventDateTime{DateTime: "2024-01-15T10:00:00Z"}},
+		{Start: &calendar.EventDateTime{DateTime: "2024-01-15T09:30:00Z"}, End: &calendar.EventDateTime{DateTime: "2024-01-15T11:00:00Z"}},
+		{Start: &calendar.EventDateTime{DateTime: "2024-01-15T13:00:00Z"}, End: &calendar.EventDateTime{DateTime: "2024-01-15T14:00:00Z"}, Transparency: "transparent"},
+		{Start: &calendar.EventDateTime{DateTime: "2024-01-15T16:00:00Z"}, End: &calendar.EventDateTime{DateTime: "2024-01-15T18:00:00Z"}},
+	} {
+		if _, err := fb.InsertEvent(ctx, "primary", event, WriteOptions{}); err != nil {
+			t.Fatalf("InsertEvent() error = %v", err)
+		}
+	}
+
+	response, err := fb.QueryFreeBusy(ctx, &calendar.FreeBusyRequest{
+		TimeMin: "2024-01-15T08:00:00Z",
+		TimeMax: "2024-01-15T17:00:00Z",
+		Items:   []*calendar.FreeBusyRequestItem{{Id: "primary"}, {Id: "missing@example.com"}},
+	})
+	if err != nil {
+		t.Fatalf("QueryFreeBusy() error = %v", err)
+	}
+
+	busy := response.Calendars["primary"].Busy
+	if len(busy) != 2 {
+		t.Fatalf("Expected 2 merged busy periods, got %d", len(busy))
+	}
+	if busy[0].Start != "2024-01-15T09:00:00Z" || busy[0].End != "2024-01-15T11:00:00Z" {
+		t.Errorf("Expected merged period 09:00-11:00, got %s-%s", busy[0].Start, busy[0].End)
+	}
+	if busy[1].End != "2024-01-15T17:00:00Z" {
+		t.Errorf("Expected last period clipped to 17:00, got %s", busy[1].End)
+	}
+
+	if errs := response.Calendars["missing@example.com"].Errors; len(errs) != 1 || errs[0].Reason != "notFound" {
+		t.Errorf("Expected notFound error for unknown calendar, got %+v", errs)
+	}
+}
+
+// TestFakeBackend_ACL tests access control rules
+func TestFakeBackend_ACL(t *testing.T) {
+	ctx := context.Background()
+	fb := NewFakeBackend()
+
+	rule, err := fb.InsertACL(ctx, "primary", &calendar.AclRule{
+		Role:  "reader",
+		Scope: &calendar.AclRuleScope{Type: "user", Value: "friend@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("InsertACL() error = %v", err)
+	}
+	if rule.Id != "user:friend@example.com" {
+		t.Errorf("Expected rule ID 'user:friend@example.com', got '%s'", rule.Id)
+	}
+
+	acl, err := fb.ListACL(ctx, "primary")
+	if err != nil {
+		t.Fatalf("ListACL() error = %v", err)
+	}
+	// The owner rule is created with the calendar
+	if len(acl.Items) != 2 {
+		t.Errorf("Expected 2 ACL rules, got %d", len(acl.Items))
+	}
+
+	if err := fb.DeleteACL(ctx, "primary", rule.Id); err != nil {
+		t.Fatalf("DeleteACL() error = %v", err)
+	}
+	if err := fb.DeleteACL(ctx, "primary", rule.Id); err == nil {
+		t.Error("Expected error deleting a missing rule")
+	}
+}
+
+// TestLoadFakeBackend tests seeding from and persisting to a JSON file
+func TestLoadFakeBackend(t *testing.T) {
+	ctx := context.Background()
+	seedPath := filepath.Join(t.TempDir(), "seed.json")
+
+	seed := `{
+  "calendars": [
+    {"id": "me@example.com", "summary": "Me", "primary": true, "events": [
+      {"id": "seeded", "summary": "Seeded", "start": {"dateTime": "2024-01-15T09:00:00Z"}, "end": {"dateTime": "2024-01-15T10:00:00Z"}}
+    ]},
+    {"id": "team@example.com", "summary": "Team", "accessRole": "reader"}
+  ]
+}`
+	if err := os.WriteFile(seedPath, []byte(seed), 0600); err != nil {
+		t.Fatalf("Failed to write seed: %v", err)
+	}
+
+	fb, err := LoadFakeBackend(seedPath)
+	if err != nil {
+		t.Fatalf("LoadFakeBackend() error = %v", err)
+	}
+	fb.PersistTo(seedPath)
+
+	calendars, err := fb.ListCalendars(ctx)
+	if err != nil {
+		t.Fatalf("ListCalendars() error = %v", err)
+	}
+	if len(calendars.Items) != 2 || !calendars.Items[0].Primary {
+		t.Errorf("Unexpected calendar list: %+v", calendars.Items)
+	}
+
+	if _, err := fb.GetEvent(ctx, "primary", "seeded"); err != nil {
+		t.Errorf("Expected seeded event via primary alias, got %v", err)
+	}
+
+	if _, err := fb.InsertEvent(ctx, "team@example.com", &calendar.Event{Summary: "Added"}, WriteOptions{}); err != nil {
+		t.Fatalf("InsertEvent() error = %v", err)
+	}
+
+	// Changes are written back and survive a reload
+	reloaded, err := LoadFakeBackend(seedPath)
+	if err != nil {
+		t.Fatalf("LoadFakeBackend() after persist error = %v", err)
+	}
+	events, err := reloaded.ListEvents(ctx, "team@example.com", ListOptions{})
+	if err != nil {
+		t.Fatalf("ListEvents() error = %v", err)
+	}
+	if len(events.Items) != 1 || events.Items[0].Summary != "Added" {
+		t.Fatalf("Expected persisted event, got %+v", events.Items)
+	}
+
+	// Generated IDs must not collide with persisted ones after a reload
+	added, err := reloaded.InsertEvent(ctx, "team@example.com", &calendar.Event{Summary: "Again"}, WriteOptions{})
+	if err != nil {
+		t.Fatalf("InsertEvent() after reload error = %v", err)
+	}
+	if added.Id == events.Items[0].Id {
+		t.Errorf("Expected a new event ID, got duplicate '%s'", added.Id)
+	}
+}
+
+// TestLoadFakeBackend_InvalidSeed tests seed parsing errors
+func TestLoadFakeBackend_InvalidSeed(t *testing.T) {
+	seedPath := filepath.Join(t.TempDir(), "seed.json")
+	if err := os.WriteFile(seedPath, []byte("{not json"), 0600); err != nil {
+		t.Fatalf("Failed to write seed: %v", err)
+	}
+
+	if _, err := LoadFakeBackend(seedPath); err == nil {
+		t.Error("Expected error for invalid seed")
+	}
+
+	if _, err := LoadFakeBackend(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected error for missing seed")
+	}
+}
@@ -39,7 +39,7 @@ type FreeBusyQueryResponse struct {
 
 // QueryFreeBusy queries the free/busy status of one or more calendars
 func (c *Client) QueryFreeBusy(ctx context.Context, request FreeBusyQueryRequest) (*FreeBusyQueryResponse, error) {
-	if c.Service == nil {
+	if c.Backend == nil {
 		return nil, types.ErrAuthFailed("calendar service not initialized")
 	}
 
@@ -86,9 +86,9 @@ func (c *Client) QueryFreeBusy(ctx context.Context, request FreeBusyQueryRequest
 	}
 
 	// Execute query
-	fbResponse, err := c.Service.Freebusy.Query(fbRequest).Context(ctx).Do()
+	fbResponse, err := c.Backend.QueryFreeBusy(ctx, fbRequest)
 	if err != nil {
-		return nil, types.ErrAPIError.WithDetails(fmt.Sprintf("free/busy query failed: %v", err))
+		return nil, handleAPIError(err, "free/busy query", apiResource{kind: "calendar"})
 	}
 
 	// Convert response
@@ -0,0 +1,120 @@
+package calendar
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/btafoya/gcal-cli/pkg/types"
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// eventIDEncoding is lower-case base32hex, whose alphabet (0-9 and a-v) is
+// what the Calendar API accepts in client-supplied event IDs
+var eventIDEncoding = base32.HexEncoding.WithPadding(base32.NoPadding)
+
+// newEventID returns the ID to create an event with: derived from the
+// idempotency key, so every run with the same key targets the same event, or
+// random when there is no key
+func newEventID(idempotencyKey string) string {
+	if idempotencyKey != "" {
+		sum := sha256.Sum256([]byte(idempotencyKey))
+		return strings.ToLower(eventIDEncoding.EncodeToString(sum[:20]))
+	}
+
+	id := make([]byte, 16)
+	rand.Read(id)
+	return strings.ToLower(eventIDEncoding.EncodeToString(id))
+}
+
+// validateEventID checks a client-supplied event ID against the API's rules
+func validateEventID(id string) error {
+	if len(id) < 5 || len(id) > 1024 {
+		return types.ErrInvalidInput("id", "event IDs must be 5 to 1024 characters long")
+	}
+	for _, r := range id {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'v') {
+			return types.ErrInvalidInput("id",
+				fmt.Sprintf("event IDs may only use the characters 0-9 and a-v, got '%c'", r))
+		}
+	}
+	return nil
+}
+
+// insertEvent creates an event that carries a client-side ID, retrying like
+// any other call. Because the ID is fixed, a retry after a request that did
+// succeed cannot create a duplicate: the API answers 409 instead, and the
+// event created earlier is fetched and returned. An existing event with a
+// different summary, start or end is a CONFLICT, not a match.
+func (c *Client) insertEvent(ctx context.Context, calendarID string, event *calendar.Event, opts WriteOptions) (*calendar.Event, error) {
+	var created *calendar.Event
+	err := c.withRetry(ctx, "create event", func() error {
+		var err error
+		created, err = c.Backend.InsertEvent(ctx, calendarID, event, opts)
+		return err
+	})
+	if err == nil || !isDuplicateID(err) {
+		return created, err
+	}
+
+	var existing *calendar.Event
+	err = c.withRetry(ctx, "get event", func() error {
+		var err error
+		existing, err = c.Backend.GetEvent(ctx, calendarID, event.Id)
+		return err
+	})
+	// IDs of deleted events stay taken; reading one either shows it as
+	// cancelled or fails as gone
+	if (err == nil && existing.Status == "cancelled") || isGone(err) {
+		return nil, types.NewAppError(types.ErrCodeConflict, "event ID already used", false).
+			WithDetails(fmt.Sprintf("event %s was deleted and its ID cannot be reused", event.Id)).
+			WithSuggestedAction("Use a different --idempotency-key")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// A reused key must describe the same event, not silently return another
+	if existing.Summary != event.Summary || !sameTime(existing.Start, event.Start) || !sameTime(existing.End, event.End) {
+		return nil, types.NewAppError(types.ErrCodeConflict, "event ID already used", false).
+			WithDetails(fmt.Sprintf("event %s already exists with a different summary or time", event.Id)).
+			WithSuggestedAction("Use a different --idempotency-key")
+	}
+
+	return existing, nil
+}
+
+// sameTime reports whether two event times are the same instant or day,
+// however their offsets are written
+func sameTime(a, b *calendar.EventDateTime) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Date != "" || b.Date != "" {
+		return a.Date == b.Date
+	}
+	ta, errA := time.Parse(time.RFC3339, a.DateTime)
+	tb, errB := time.Parse(time.RFC3339, b.DateTime)
+	if errA != nil || errB != nil {
+		return a.DateTime == b.DateTime
+	}
+	return ta.Equal(tb)
+}
+
+// isDuplicateID reports whether an insert failed because the event ID exists
+func isDuplicateID(err error) bool {
+	var apiErr *googleapi.Error
+	return errors.As(err, &apiErr) && apiErr.Code == 409
+}
+
+// isGone reports whether a read failed because the event was deleted
+func isGone(err error) bool {
+	var apiErr *googleapi.Error
+	return errors.As(err, &apiErr) && (apiErr.Code == 404 || apiErr.Code == 410)
+}
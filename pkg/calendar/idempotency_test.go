@@ -0,0 +1,222 @@
+package calendar
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/btafoya/gcal-cli/pkg/types"
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// lostResponseBackend stores the first inserted event but reports a server
+// error, like a request whose response never arrived
+type lostResponseBackend struct {
+	*FakeBackend
+	inserts atomic.Int32
+}
+
+func (b *lostResponseBackend) InsertEvent(ctx context.Context, calendarID string, event *calendar.Event, opts WriteOptions) (*calendar.Event, error) {
+	created, err := b.FakeBackend.InsertEvent(ctx, calendarID, event, opts)
+	if b.inserts.Add(1) == 1 && err == nil {
+		return nil, &googleapi.Error{Code: 503, Message: "Backend Error"}
+	}
+	return created, err
+}
+
+// countEvents returns the number of events stored in the primary fake calendar
+func countEvents(t *testing.T, fake *FakeBackend) int {
+	t.Helper()
+	events, err := fake.ListEvents(context.Background(), "primary", ListOptions{
+		TimeMin: "2024-01-01T00:00:00Z",
+		TimeMax: "2025-01-01T00:00:00Z",
+	})
+	if err != nil {
+		t.Fatalf("ListEvents() error = %v", err)
+	}
+	return len(events.Items)
+}
+
+// TestCreateEvent_RetryAfterLostResponse tests that retrying a create that
+// succeeded server-side returns the event instead of a duplicate
+func TestCreateEvent_RetryAfterLostResponse(t *testing.T) {
+	fake := NewFakeBackend()
+	backend := &lostResponseBackend{FakeBackend: fake}
+	client := NewClientWithBackend(backend, "primary")
+	client.RetryDelay = time.Millisecond
+
+	start := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	event, err := client.CreateEvent(context.Background(), CreateEventParams{
+		Summary: "Standup", Start: start, End: start.Add(15 * time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("CreateEvent() error = %v", err)
+	}
+	if event.Summary != "Standup" || validateEventID(event.ID) != nil {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+	if backend.inserts.Load() != 2 {
+		t.Errorf("Expected the insert to be retried once, got %d attempts", backend.inserts.Load())
+	}
+	if n := countEvents(t, fake); n != 1 {
+		t.Errorf("Expected 1 stored event, got %d", n)
+	}
+}
+
+// TestCreateEvent_IdempotencyKey tests that a key maps to a single event
+func TestCreateEvent_IdempotencyKey(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFakeBackend()
+	client := NewClientWithBackend(fake, "primary")
+	client.RetryDelay = time.Millisecond
+
+	start := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	params := CreateEventParams{Summary: "Review", Start: start, End: start.Add(time.Hour), IdempotencyKey: "review-1"}
+
+	first, err := client.CreateEvent(ctx, params)
+	if err != nil {
+		t.Fatalf("CreateEvent() error = %v", err)
+	}
+	second, err := client.CreateEvent(ctx, params)
+	if err != nil {
+		t.Fatalf("repeated CreateEvent() error = %v", err)
+	}
+	if first.ID != second.ID || first.ID != newEventID("review-1") {
+		t.Errorf("Expected both creates to return %s, got %s and %s", newEventID("review-1"), first.ID, second.ID)
+	}
+
+	params.IdempotencyKey = "review-2"
+	if _, err := client.CreateEvent(ctx, params); err != nil {
+		t.Fatalf("CreateEvent() with new key error = %v", err)
+	}
+	if n := countEvents(t, fake); n != 2 {
+		t.Errorf("Expected 2 stored events, got %d", n)
+	}
+
+	// A reused key with different details is not the same event
+	changed := params
+	changed.Summary = "Other review"
+	_, err = client.CreateEvent(ctx, changed)
+	if appErr, ok := err.(*types.AppError); !ok || appErr.Code != types.ErrCodeConflict {
+		t.Errorf("Expected CONFLICT for a reused key with another summary, got %v", err)
+	}
+	changed = params
+	changed.End = start.Add(2 * time.Hour)
+	_, err = client.CreateEvent(ctx, changed)
+	if appErr, ok := err.(*types.AppError); !ok || appErr.Code != types.ErrCodeConflict {
+		t.Errorf("Expected CONFLICT for a reused key with another end, got %v", err)
+	}
+
+	// The ID of a deleted event is not silently reused
+	params.IdempotencyKey = "gone"
+	gone, err := client.CreateEvent(ctx, params)
+	if err != nil {
+		t.Fatalf("CreateEvent() error = %v", err)
+	}
+	if err := client.DeleteEvent(ctx, gone.ID); err != nil {
+		t.Fatalf("DeleteEvent() error = %v", err)
+	}
+	_, err = client.CreateEvent(ctx, params)
+	if appErr, ok := err.(*types.AppError); !ok || appErr.Code != types.ErrCodeConflict {
+		t.Errorf("Expected CONFLICT for a deleted event's ID, got %v", err)
+	}
+
+	params.ID = "Not-Base32"
+	if _, err := client.CreateEvent(ctx, params); err == nil {
+		t.Error("Expected error for an invalid event ID")
+	}
+}
+
+// TestBatchCreateEvents_IdempotencyKey tests that a repeated batch creates nothing twice
+func TestBatchCreateEvents_IdempotencyKey(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFakeBackend()
+	client := NewClientWithBackend(fake, "primary")
+	client.RetryDelay = time.Millisecond
+
+	start := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	params := BatchCreateParams{IdempotencyKey: "import-42"}
+	for i := 0; i < 3; i++ {
+		params.Events = append(params.Events, CreateEventParams{
+			Summary: "Slot", Start: start.Add(time.Duration(i) * time.Hour), End: start.Add(time.Duration(i)*time.Hour + 30*time.Minute),
+		})
+	}
+
+	for run := 0; run < 2; run++ {
+		results, err := client.BatchCreateEvents(ctx, params)
+		if err != nil {
+			t.Fatalf("BatchCreateEvents() run %d error = %v", run, err)
+		}
+		if results[2].EventID != newEventID("import-42/2") {
+			t.Errorf("Unexpected ID %s for the third event", results[2].EventID)
+		}
+	}
+	if n := countEvents(t, fake); n != 3 {
+		t.Errorf("Expected 3 stored events, got %d", n)
+	}
+}
+
+// TestCreateEventMultiCalendar_Retry tests that copies created before a lost
+// response are not duplicated
+func TestCreateEventMultiCalendar_Retry(t *testing.T) {
+	fake := NewFakeBackend()
+	client := NewClientWithBackend(&lostResponseBackend{FakeBackend: fake}, "primary")
+	client.RetryDelay = time.Millisecond
+
+	start := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	results, err := client.CreateEventMultiCalendar(context.Background(), []string{"primary"}, &calendar.Event{
+		Summary: "All hands",
+		Start:   &calendar.EventDateTime{DateTime: start.Format(time.RFC3339)},
+		End:     &calendar.EventDateTime{DateTime: start.Add(time.Hour).Format(time.RFC3339)},
+	})
+	if err != nil {
+		t.Fatalf("CreateEventMultiCalendar() error = %v", err)
+	}
+	if results["primary"] == nil || results["primary"].ID == "" {
+		t.Fatalf("Expected an event for the primary calendar, got %v", results)
+	}
+	if n := countEvents(t, fake); n != 1 {
+		t.Errorf("Expected 1 stored event, got %d", n)
+	}
+}
+
+// TestCreateEventFromTemplate_Retry tests that a template event created
+// before a lost response is not duplicated
+func TestCreateEventFromTemplate_Retry(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	tm, err := NewTemplateManager()
+	if err != nil {
+		t.Fatalf("NewTemplateManager() error = %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "gcal-cli"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.Add("sync", EventTemplate{Summary: "Sync", DurationMinutes: 30}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	fake := NewFakeBackend()
+	backend := &lostResponseBackend{FakeBackend: fake}
+	client := NewClientWithBackend(backend, "primary")
+	client.RetryDelay = time.Millisecond
+
+	start := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	event, err := client.CreateEventFromTemplate(context.Background(), "primary", "sync", start, nil)
+	if err != nil {
+		t.Fatalf("CreateEventFromTemplate() error = %v", err)
+	}
+	if event.Summary != "Sync" || validateEventID(event.ID) != nil {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+	if backend.inserts.Load() != 2 {
+		t.Errorf("Expected the insert to be retried once, got %d attempts", backend.inserts.Load())
+	}
+	if n := countEvents(t, fake); n != 1 {
+		t.Errorf("Expected 1 stored event, got %d", n)
+	}
+}
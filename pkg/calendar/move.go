@@ -0,0 +1,159 @@
+package calendar
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/btafoya/gcal-cli/pkg/types"
+	"google.golang.org/api/calendar/v3"
+)
+
+// MoveEvent moves an event from the client's calendar to another calendar.
+// The event keeps its ID, and the destination calendar becomes its organizer.
+func (c *Client) MoveEvent(ctx context.Context, eventID, destinationID string) (*types.Event, error) {
+	if eventID == "" {
+		return nil, types.ErrMissingRequired("event-id")
+	}
+	if destinationID == "" {
+		return nil, types.ErrMissingRequired("to")
+	}
+	if destinationID == c.CalendarID {
+		return nil, types.ErrInvalidInput("to",
+			fmt.Sprintf("event is already in calendar %s", destinationID))
+	}
+
+	var moved *calendar.Event
+	err := c.withRetry(ctx, "move event", func() error {
+		var err error
+		moved, err = c.Backend.MoveEvent(ctx, c.CalendarID, eventID, destinationID, WriteOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, handleAPIError(err, "move event", apiResource{"event", eventID})
+	}
+
+	c.invalidateCache(c.CalendarID, eventID)
+	c.invalidateCache(destinationID, moved.Id, moved)
+
+	return convertEvent(moved), nil
+}
+
+// CopyEvent copies an event from the client's calendar into each of
+// calendarIDs. The copies share a new ID and leave out everything the server
+// owns, so they are independent events.
+func (c *Client) CopyEvent(ctx context.Context, eventID string, calendarIDs []string) (map[string]*types.Event, error) {
+	if eventID == "" {
+		return nil, types.ErrMissingRequired("event-id")
+	}
+	if len(calendarIDs) == 0 {
+		return nil, types.ErrMissingRequired("to")
+	}
+
+	source, err := c.getSourceEvent(ctx, c.CalendarID, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.CreateEventMultiCalendar(ctx, calendarIDs, copyableEvent(source))
+}
+
+// DuplicateEvent creates a copy of an event in the client's calendar that
+// starts at start and lasts as long as the original. All-day events move by
+// whole days to start's date.
+func (c *Client) DuplicateEvent(ctx context.Context, eventID string, start time.Time) (*types.Event, error) {
+	if eventID == "" {
+		return nil, types.ErrMissingRequired("event-id")
+	}
+	if start.IsZero() {
+		return nil, types.ErrMissingRequired("start")
+	}
+
+	source, err := c.getSourceEvent(ctx, c.CalendarID, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	duplicate := copyableEvent(source)
+	if err := shiftEvent(duplicate, start); err != nil {
+		return nil, err
+	}
+	duplicate.Id = newEventID("")
+
+	created, err := c.insertEvent(ctx, c.CalendarID, duplicate, WriteOptions{})
+	if err != nil {
+		return nil, handleAPIError(err, "duplicate event", apiResource{"calendar", c.CalendarID})
+	}
+
+	c.invalidateCache(c.CalendarID, created.Id, created)
+
+	return convertEvent(created), nil
+}
+
+// copyableEvent returns a copy of event without the fields the server owns
+// (ID, iCalUID, etag, links, timestamps, organizer, recurrence instance and
+// conference data), ready to be inserted as a new event. Attendees are
+// invited afresh.
+func copyableEvent(event *calendar.Event) *calendar.Event {
+	clone := cloneEvent(event)
+
+	clone.Id = ""
+	clone.ICalUID = ""
+	clone.Etag = ""
+	clone.HtmlLink = ""
+	clone.HangoutLink = ""
+	clone.Created = ""
+	clone.Updated = ""
+	clone.Creator = nil
+	clone.Organizer = nil
+	clone.Sequence = 0
+	clone.Status = ""
+	clone.RecurringEventId = ""
+	clone.OriginalStartTime = nil
+	clone.ConferenceData = nil
+
+	for _, attendee := range clone.Attendees {
+		attendee.Id = ""
+		attendee.Self = false
+		attendee.Organizer = false
+		attendee.ResponseStatus = "needsAction"
+	}
+
+	return clone
+}
+
+// shiftEvent moves an event to start, keeping its duration
+func shiftEvent(event *calendar.Event, start time.Time) error {
+	if event.Start == nil || event.End == nil {
+		return types.ErrInvalidInput("event-id", "event has no start or end time")
+	}
+
+	if event.Start.Date != "" {
+		from, err := time.Parse("2006-01-02", event.Start.Date)
+		if err != nil {
+			return types.ErrInvalidInput("start", err.Error())
+		}
+		to, err := time.Parse("2006-01-02", event.End.Date)
+		if err != nil {
+			return types.ErrInvalidInput("end", err.Error())
+		}
+
+		day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+		event.Start.Date = day.Format("2006-01-02")
+		event.End.Date = day.Add(to.Sub(from)).Format("2006-01-02")
+		return nil
+	}
+
+	from, err := time.Parse(time.RFC3339, event.Start.DateTime)
+	if err != nil {
+		return types.ErrInvalidInput("start", err.Error())
+	}
+	to, err := time.Parse(time.RFC3339, event.End.DateTime)
+	if err != nil {
+		return types.ErrInvalidInput("end", err.Error())
+	}
+
+	event.Start.DateTime = start.Format(time.RFC3339)
+	event.End.DateTime = start.Add(to.Sub(from)).Format(time.RFC3339)
+	return nil
+}
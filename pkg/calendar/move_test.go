@@ -0,0 +1,173 @@
+package calendar
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/btafoya/gcal-cli/pkg/types"
+)
+
+// newTwoCalendarClient creates a client on the primary calendar of a fake
+// backend that also has a team calendar
+func newTwoCalendarClient(t *testing.T) (*Client, *FakeBackend) {
+	t.Helper()
+	fake := NewFakeBackendFromSeed(FakeSeed{Calendars: []FakeCalendarSeed{
+		{ID: "me@example.com", Primary: true, AccessRole: "owner"},
+		{ID: "team@example.com", AccessRole: "writer"},
+	}})
+	client := NewClientWithBackend(fake, "primary")
+	client.RetryDelay = time.Millisecond
+	return client, fake
+}
+
+// TestMoveEvent tests moving an event between calendars
+func TestMoveEvent(t *testing.T) {
+	ctx := context.Background()
+	client, fake := newTwoCalendarClient(t)
+	source := insertRichEvent(t, fake)
+
+	moved, err := client.MoveEvent(ctx, source.Id, "team@example.com")
+	if err != nil {
+		t.Fatalf("MoveEvent() error = %v", err)
+	}
+	if moved.ID != source.Id {
+		t.Errorf("Moved event ID = %s, want %s", moved.ID, source.Id)
+	}
+
+	if _, err := fake.GetEvent(ctx, "primary", source.Id); err == nil {
+		t.Error("Event is still in the source calendar")
+	}
+	stored, err := fake.GetEvent(ctx, "team@example.com", source.Id)
+	if err != nil {
+		t.Fatalf("Event not in the destination calendar: %v", err)
+	}
+	if stored.Organizer.Email != "team@example.com" || stored.ColorId != "5" {
+		t.Errorf("Unexpected moved event: organizer %+v, color %q", stored.Organizer, stored.ColorId)
+	}
+}
+
+// TestMoveEvent_Validation tests rejected moves
+func TestMoveEvent_Validation(t *testing.T) {
+	client, _ := newTwoCalendarClient(t)
+
+	tests := []struct {
+		name        string
+		destination string
+		code        string
+	}{
+		{"no destination", "", types.ErrCodeMissingRequired},
+		{"same calendar", "primary", types.ErrCodeInvalidInput},
+		{"unknown calendar", "nobody@example.com", types.ErrCodeNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.MoveEvent(context.Background(), "abcdef", tt.destination)
+			if appErr, ok := err.(*types.AppError); !ok || appErr.Code != tt.code {
+				t.Errorf("MoveEvent() error = %v, want %s", err, tt.code)
+			}
+		})
+	}
+}
+
+// TestCopyEvent tests that copies are new events without server-owned fields
+func TestCopyEvent(t *testing.T) {
+	ctx := context.Background()
+	client, fake := newTwoCalendarClient(t)
+	source := insertRichEvent(t, fake)
+
+	copies, err := client.CopyEvent(ctx, source.Id, []string{"team@example.com", "primary"})
+	if err != nil {
+		t.Fatalf("CopyEvent() error = %v", err)
+	}
+	if len(copies) != 2 {
+		t.Fatalf("Expected 2 copies, got %d", len(copies))
+	}
+
+	for calendarID, copied := range copies {
+		if copied.ID == source.Id {
+			t.Errorf("Copy in %s reuses the source ID", calendarID)
+		}
+
+		stored, err := fake.GetEvent(ctx, calendarID, copied.ID)
+		if err != nil {
+			t.Fatalf("Copy not stored in %s: %v", calendarID, err)
+		}
+		if stored.ICalUID == source.ICalUID || stored.ConferenceData != nil {
+			t.Errorf("Copy in %s kept server-owned fields: %+v", calendarID, stored)
+		}
+		if stored.Summary != source.Summary || stored.ColorId != "5" || stored.Reminders == nil {
+			t.Errorf("Copy in %s lost details: %+v", calendarID, stored)
+		}
+		for _, attendee := range stored.Attendees {
+			if attendee.ResponseStatus != "needsAction" {
+				t.Errorf("Attendee %s kept response %q", attendee.Email, attendee.ResponseStatus)
+			}
+		}
+	}
+
+	if _, err := fake.GetEvent(ctx, "primary", source.Id); err != nil {
+		t.Errorf("Source event was removed: %v", err)
+	}
+}
+
+// TestSyncEventAcrossCalendars_SameCalendar tests that syncing into the
+// source calendar creates a copy instead of colliding with the source ID
+func TestSyncEventAcrossCalendars_SameCalendar(t *testing.T) {
+	client, fake := newTwoCalendarClient(t)
+	source := insertRichEvent(t, fake)
+
+	copies, err := client.SyncEventAcrossCalendars(context.Background(), "primary", source.Id, []string{"primary"})
+	if err != nil {
+		t.Fatalf("SyncEventAcrossCalendars() error = %v", err)
+	}
+	if copies["primary"] == nil || copies["primary"].ID == source.Id {
+		t.Errorf("Expected a copy with a new ID, got %+v", copies["primary"])
+	}
+}
+
+// TestDuplicateEvent tests cloning an event to a new time
+func TestDuplicateEvent(t *testing.T) {
+	ctx := context.Background()
+	client, fake := newTwoCalendarClient(t)
+	source := insertRichEvent(t, fake)
+
+	start := time.Date(2024, 1, 22, 15, 30, 0, 0, time.UTC)
+	duplicate, err := client.DuplicateEvent(ctx, source.Id, start)
+	if err != nil {
+		t.Fatalf("DuplicateEvent() error = %v", err)
+	}
+
+	if duplicate.ID == source.Id || duplicate.Summary != source.Summary {
+		t.Errorf("Unexpected duplicate: %+v", duplicate)
+	}
+	if duplicate.Start.DateTime != "2024-01-22T15:30:00Z" || duplicate.End.DateTime != "2024-01-22T16:30:00Z" {
+		t.Errorf("Duplicate runs %s to %s, want 15:30 to 16:30", duplicate.Start.DateTime, duplicate.End.DateTime)
+	}
+	if countEvents(t, fake) != 2 {
+		t.Errorf("Expected the source and the duplicate, got %d events", countEvents(t, fake))
+	}
+}
+
+// TestDuplicateEvent_AllDay tests that all-day events move by whole days
+func TestDuplicateEvent_AllDay(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newTwoCalendarClient(t)
+
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	source, err := client.CreateEvent(ctx, CreateEventParams{
+		Summary: "Offsite", Start: day, End: day.AddDate(0, 0, 2), AllDay: true,
+	})
+	if err != nil {
+		t.Fatalf("CreateEvent() error = %v", err)
+	}
+
+	duplicate, err := client.DuplicateEvent(ctx, source.ID, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("DuplicateEvent() error = %v", err)
+	}
+	if duplicate.Start.Date != "2024-03-04" || duplicate.End.Date != "2024-03-06" {
+		t.Errorf("Duplicate runs %s to %s, want 2024-03-04 to 2024-03-06", duplicate.Start.Date, duplicate.End.Date)
+	}
+}
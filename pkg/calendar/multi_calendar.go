@@ -2,7 +2,6 @@ package calendar
 
 import (
 	"context"
-	"fmt"
 	"sync"
 	"time"
 
@@ -25,7 +24,7 @@ type MultiCalendarListResult struct {
 
 // ListEventsMultiCalendar lists events from multiple calendars in parallel
 func (c *Client) ListEventsMultiCalendar(ctx context.Context, calendarIDs []string, timeMin, timeMax time.Time, maxResults int) (*MultiCalendarListResult, error) {
-	if c.Service == nil {
+	if c.Backend == nil {
 		return nil, types.ErrAuthFailed("calendar service not initialized")
 	}
 
@@ -49,19 +48,15 @@ func (c *Client) ListEventsMultiCalendar(ctx context.Context, calendarIDs []stri
 			defer wg.Done()
 
 			// List events for this calendar
-			call := c.Service.Events.List(calendarID).
-				TimeMin(timeMin.Format(time.RFC3339)).
-				TimeMax(timeMax.Format(time.RFC3339)).
-				SingleEvents(true).
-				OrderBy("startTime")
-
-			if maxResults > 0 {
-				call = call.MaxResults(int64(maxResults))
-			}
-
-			events, err := call.Context(ctx).Do()
+			events, err := c.Backend.ListEvents(ctx, calendarID, ListOptions{
+				TimeMin:      timeMin.Format(time.RFC3339),
+				TimeMax:      timeMax.Format(time.RFC3339),
+				MaxResults:   int64(maxResults),
+				OrderBy:      "startTime",
+				SingleEvents: true,
+			})
 			if err != nil {
-				errorsChan <- fmt.Errorf("calendar %s: %w", calendarID, err)
+				errorsChan <- handleAPIError(err, "list events", apiResource{"calendar", calendarID})
 				return
 			}
 
@@ -83,8 +78,7 @@ func (c *Client) ListEventsMultiCalendar(ctx context.Context, calendarIDs []stri
 
 	// Check for errors
 	if len(errorsChan) > 0 {
-		err := <-errorsChan
-		return nil, types.ErrAPIError.WithDetails(fmt.Sprintf("multi-calendar query failed: %v", err))
+		return nil, <-errorsChan
 	}
 
 	result.TotalCount = len(result.Events)
@@ -95,9 +89,11 @@ func (c *Client) ListEventsMultiCalendar(ctx context.Context, calendarIDs []stri
 	return result, nil
 }
 
-// CreateEventMultiCalendar creates the same event in multiple calendars
+// CreateEventMultiCalendar creates the same event in multiple calendars. The
+// copies share one event ID (event.Id, or a random one), so retries and
+// repeated calls do not create duplicates.
 func (c *Client) CreateEventMultiCalendar(ctx context.Context, calendarIDs []string, event *calendar.Event) (map[string]*types.Event, error) {
-	if c.Service == nil {
+	if c.Backend == nil {
 		return nil, types.ErrAuthFailed("calendar service not initialized")
 	}
 
@@ -105,6 +101,12 @@ func (c *Client) CreateEventMultiCalendar(ctx context.Context, calendarIDs []str
 		return nil, types.ErrInvalidInput("calendarIds", "at least one calendar ID required")
 	}
 
+	if event.Id == "" {
+		withID := *event
+		withID.Id = newEventID("")
+		event = &withID
+	}
+
 	results := make(map[string]*types.Event)
 	var wg sync.WaitGroup
 	var mu sync.Mutex
@@ -115,14 +117,14 @@ func (c *Client) CreateEventMultiCalendar(ctx context.Context, calendarIDs []str
 		go func(calendarID string) {
 			defer wg.Done()
 
-			createdEvent, err := c.Service.Events.Insert(calendarID, event).
-				Context(ctx).
-				Do()
+			createdEvent, err := c.insertEvent(ctx, calendarID, event, WriteOptions{})
 			if err != nil {
-				errorsChan <- fmt.Errorf("calendar %s: %w", calendarID, err)
+				errorsChan <- handleAPIError(err, "create event", apiResource{"calendar", calendarID})
 				return
 			}
 
+			c.invalidateCache(calendarID, createdEvent.Id, createdEvent)
+
 			mu.Lock()
 			results[calendarID] = convertEvent(createdEvent)
 			mu.Unlock()
@@ -134,8 +136,7 @@ func (c *Client) CreateEventMultiCalendar(ctx context.Context, calendarIDs []str
 
 	// Check for errors
 	if len(errorsChan) > 0 {
-		err := <-errorsChan
-		return nil, types.ErrAPIError.WithDetails(fmt.Sprintf("multi-calendar create failed: %v", err))
+		return nil, <-errorsChan
 	}
 
 	return results, nil
@@ -143,7 +144,7 @@ func (c *Client) CreateEventMultiCalendar(ctx context.Context, calendarIDs []str
 
 // FindCommonFreeTime finds time slots when all calendars are free
 func (c *Client) FindCommonFreeTime(ctx context.Context, calendarIDs []string, start, end time.Time, slotDuration time.Duration) ([]time.Time, error) {
-	if c.Service == nil {
+	if c.Backend == nil {
 		return nil, types.ErrAuthFailed("calendar service not initialized")
 	}
 
@@ -203,13 +204,13 @@ func (c *Client) FindCommonFreeTime(ctx context.Context, calendarIDs []string, s
 // SyncEventAcrossCalendars synchronizes an event across multiple calendars
 func (c *Client) SyncEventAcrossCalendars(ctx context.Context, sourceCalendarID, eventID string, targetCalendarIDs []string) (map[string]*types.Event, error) {
 	// Get the source event
-	sourceEvent, err := c.Service.Events.Get(sourceCalendarID, eventID).Context(ctx).Do()
+	sourceEvent, err := c.getSourceEvent(ctx, sourceCalendarID, eventID)
 	if err != nil {
-		return nil, types.ErrAPIError.WithDetails(fmt.Sprintf("failed to get source event: %v", err))
+		return nil, err
 	}
 
-	// Create copies in target calendars
-	return c.CreateEventMultiCalendar(ctx, targetCalendarIDs, sourceEvent)
+	// Create copies in target calendars, without the source's ID
+	return c.CreateEventMultiCalendar(ctx, targetCalendarIDs, copyableEvent(sourceEvent))
 }
 
 // sortMultiCalendarEvents sorts events by start time
@@ -236,13 +237,13 @@ func sortMultiCalendarEvents(events []MultiCalendarEvent) {
 
 // GetCalendarPermissions retrieves the access control list for a calendar
 func (c *Client) GetCalendarPermissions(ctx context.Context, calendarID string) ([]*calendar.AclRule, error) {
-	if c.Service == nil {
+	if c.Backend == nil {
 		return nil, types.ErrAuthFailed("calendar service not initialized")
 	}
 
-	acl, err := c.Service.Acl.List(calendarID).Context(ctx).Do()
+	acl, err := c.Backend.ListACL(ctx, calendarID)
 	if err != nil {
-		return nil, types.ErrAPIError.WithDetails(fmt.Sprintf("failed to get calendar permissions: %v", err))
+		return nil, handleAPIError(err, "get calendar permissions", apiResource{"calendar", calendarID})
 	}
 
 	return acl.Items, nil
@@ -250,7 +251,7 @@ func (c *Client) GetCalendarPermissions(ctx context.Context, calendarID string)
 
 // ShareCalendar adds a user to a calendar's access control list
 func (c *Client) ShareCalendar(ctx context.Context, calendarID, email, role string) error {
-	if c.Service == nil {
+	if c.Backend == nil {
 		return types.ErrAuthFailed("calendar service not initialized")
 	}
 
@@ -274,9 +275,9 @@ func (c *Client) ShareCalendar(ctx context.Context, calendarID, email, role stri
 		},
 	}
 
-	_, err := c.Service.Acl.Insert(calendarID, rule).Context(ctx).Do()
+	_, err := c.Backend.InsertACL(ctx, calendarID, rule)
 	if err != nil {
-		return types.ErrAPIError.WithDetails(fmt.Sprintf("failed to share calendar: %v", err))
+		return handleAPIError(err, "share calendar", apiResource{"calendar", calendarID})
 	}
 
 	return nil
@@ -284,13 +285,13 @@ func (c *Client) ShareCalendar(ctx context.Context, calendarID, email, role stri
 
 // UnshareCalendar removes a user from a calendar's access control list
 func (c *Client) UnshareCalendar(ctx context.Context, calendarID, ruleID string) error {
-	if c.Service == nil {
+	if c.Backend == nil {
 		return types.ErrAuthFailed("calendar service not initialized")
 	}
 
-	err := c.Service.Acl.Delete(calendarID, ruleID).Context(ctx).Do()
+	err := c.Backend.DeleteACL(ctx, calendarID, ruleID)
 	if err != nil {
-		return types.ErrAPIError.WithDetails(fmt.Sprintf("failed to unshare calendar: %v", err))
+		return handleAPIError(err, "unshare calendar", apiResource{"sharing rule", ruleID})
 	}
 
 	return nil
@@ -213,10 +213,17 @@ func parseSpecificDateTime(input string, now time.Time, tz *time.Location) (stri
 	return "", fmt.Errorf("specific date/time parsing not implemented")
 }
 
-// parseTimeOfDay parses time strings like "2pm", "14:30", "3:30pm"
+// parseTimeOfDay parses time strings like "2pm", "14:30", "3:30pm" and "noon"
 func parseTimeOfDay(timeStr string, tz *time.Location) (time.Time, error) {
 	timeStr = strings.TrimSpace(strings.ToLower(timeStr))
 
+	switch timeStr {
+	case "noon":
+		return time.Date(0, 1, 1, 12, 0, 0, 0, time.UTC), nil
+	case "midnight":
+		return time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC), nil
+	}
+
 	// Try formats: "2pm", "14:30", "2:30pm", "14:30:00"
 	formats := []string{
 		"3pm",
@@ -203,6 +203,13 @@ func TestParseTimeOfDay(t *testing.T) {
 			wantMin: 30,
 			wantErr: false,
 		},
+		{
+			name:    "noon",
+			input:   "noon",
+			wantHour: 12,
+			wantMin: 0,
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,107 @@
+package calendar
+
+import (
+	"context"
+
+	"github.com/btafoya/gcal-cli/pkg/types"
+	"google.golang.org/api/calendar/v3"
+)
+
+// offlineBackend rejects every call; offline clients answer reads from the
+// cache before reaching the backend
+type offlineBackend struct{}
+
+// NewOfflineBackend returns a backend that never touches the network
+func NewOfflineBackend() Backend {
+	return offlineBackend{}
+}
+
+// errOffline is returned for operations the cache cannot answer
+func errOffline() error {
+	return types.ErrNetworkError("network access is disabled in offline mode").
+		WithDetails("only cached event reads are available offline").
+		WithSuggestedAction("Run the command without --offline")
+}
+
+func (offlineBackend) InsertEvent(ctx context.Context, calendarID string, event *calendar.Event, opts WriteOptions) (*calendar.Event, error) {
+	return nil, errOffline()
+}
+
+func (offlineBackend) GetEvent(ctx context.Context, calendarID, eventID string) (*calendar.Event, error) {
+	return nil, errOffline()
+}
+
+func (offlineBackend) ListEvents(ctx context.Context, calendarID string, opts ListOptions) (*calendar.Events, error) {
+	return nil, errOffline()
+}
+
+func (offlineBackend) UpdateEvent(ctx context.Context, calendarID, eventID string, event *calendar.Event, opts WriteOptions) (*calendar.Event, error) {
+	return nil, errOffline()
+}
+
+func (offlineBackend) PatchEvent(ctx context.Context, calendarID, eventID string, patch *calendar.Event, opts WriteOptions) (*calendar.Event, error) {
+	return nil, errOffline()
+}
+
+func (offlineBackend) DeleteEvent(ctx context.Context, calendarID, eventID string) error {
+	return errOffline()
+}
+
+func (offlineBackend) MoveEvent(ctx context.Context, calendarID, eventID, destinationID string, opts WriteOptions) (*calendar.Event, error) {
+	return nil, errOffline()
+}
+
+func (offlineBackend) ListInstances(ctx context.Context, calendarID, eventID string, opts ListOptions) (*calendar.Events, error) {
+	return nil, errOffline()
+}
+
+func (offlineBackend) QuickAddEvent(ctx context.Context, calendarID, text string, opts WriteOptions) (*calendar.Event, error) {
+	return nil, errOffline()
+}
+
+func (offlineBackend) QueryFreeBusy(ctx context.Context, request *calendar.FreeBusyRequest) (*calendar.FreeBusyResponse, error) {
+	return nil, errOffline()
+}
+
+func (offlineBackend) ListACL(ctx context.Context, calendarID string) (*calendar.Acl, error) {
+	return nil, errOffline()
+}
+
+func (offlineBackend) InsertACL(ctx context.Context, calendarID string, rule *calendar.AclRule) (*calendar.AclRule, error) {
+	return nil, errOffline()
+}
+
+func (offlineBackend) DeleteACL(ctx context.Context, calendarID, ruleID string) error {
+	return errOffline()
+}
+
+func (offlineBackend) ListCalendars(ctx context.Context) (*calendar.CalendarList, error) {
+	return nil, errOffline()
+}
+
+func (offlineBackend) GetCalendar(ctx context.Context, calendarID string) (*calendar.Calendar, error) {
+	return nil, errOffline()
+}
+
+// invalidateCache drops cached reads that a write to eventID in calendarID
+// may have changed. Recurring events can appear anywhere in the calendar, so
+// writes to them clear the whole calendar.
+func (c *Client) invalidateCache(calendarID, eventID string, changed ...*calendar.Event) {
+	if c.Cache == nil {
+		return
+	}
+
+	var events []*types.Event
+	for _, event := range changed {
+		if event == nil {
+			continue
+		}
+		if len(event.Recurrence) > 0 {
+			c.Cache.Clear(calendarID)
+			return
+		}
+		events = append(events, convertEvent(event))
+	}
+
+	c.Cache.Invalidate(calendarID, eventID, events...)
+}
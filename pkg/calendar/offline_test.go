@@ -0,0 +1,123 @@
+package calendar
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/btafoya/gcal-cli/pkg/cache"
+	"github.com/btafoya/gcal-cli/pkg/types"
+	"google.golang.org/api/calendar/v3"
+)
+
+// TestClientCache tests that reads are cached and writes invalidate them
+func TestClientCache(t *testing.T) {
+	ctx := context.Background()
+	backend := NewFakeBackend()
+	client := NewClientWithBackend(backend, "primary")
+	client.RetryDelay = time.Millisecond
+	client.Cache = cache.New(t.TempDir(), time.Hour)
+
+	start := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	params := ListEventsParams{From: start.Truncate(24 * time.Hour), To: start.Add(24 * time.Hour)}
+
+	created, err := client.CreateEvent(ctx, CreateEventParams{Summary: "Standup", Start: start, End: start.Add(15 * time.Minute)})
+	if err != nil {
+		t.Fatalf("CreateEvent() error = %v", err)
+	}
+	if created.ETag == "" {
+		t.Error("Expected created event to carry an etag")
+	}
+
+	if events, err := client.ListEvents(ctx, params); err != nil || len(events) != 1 {
+		t.Fatalf("ListEvents() = %v, %v; want 1 event", events, err)
+	}
+
+	// Changes made behind the client's back are hidden by the cache
+	backend.InsertEvent(ctx, "primary", &calendar.Event{
+		Summary: "External",
+		Start:   &calendar.EventDateTime{DateTime: start.Add(time.Hour).Format(time.RFC3339)},
+		End:     &calendar.EventDateTime{DateTime: start.Add(2 * time.Hour).Format(time.RFC3339)},
+	}, WriteOptions{})
+	if events, _ := client.ListEvents(ctx, params); len(events) != 1 {
+		t.Fatalf("Expected cached listing with 1 event, got %d", len(events))
+	}
+
+	// A write through the client drops the overlapping listing
+	if _, err := client.CreateEvent(ctx, CreateEventParams{Summary: "Review", Start: start.Add(3 * time.Hour), End: start.Add(4 * time.Hour)}); err != nil {
+		t.Fatalf("CreateEvent() error = %v", err)
+	}
+	if events, _ := client.ListEvents(ctx, params); len(events) != 3 {
+		t.Fatalf("Expected 3 events after invalidation, got %d", len(events))
+	}
+
+	// Updates fetch the current event rather than the cached copy
+	if _, err := client.GetEvent(ctx, created.ID); err != nil {
+		t.Fatalf("GetEvent() error = %v", err)
+	}
+	existing, _ := backend.GetEvent(ctx, "primary", created.ID)
+	existing.Description = "changed externally"
+	backend.UpdateEvent(ctx, "primary", created.ID, existing, WriteOptions{})
+
+	updated, err := client.UpdateEvent(ctx, created.ID, CreateEventParams{Location: "Room 4B"})
+	if err != nil {
+		t.Fatalf("UpdateEvent() error = %v", err)
+	}
+	if updated.Description != "changed externally" {
+		t.Errorf("Expected update to merge with the current event, got description %q", updated.Description)
+	}
+	if event, _ := client.GetEvent(ctx, created.ID); event.Location != "Room 4B" {
+		t.Errorf("Expected invalidated event to be refetched, got location %q", event.Location)
+	}
+
+	if err := client.DeleteEvent(ctx, created.ID); err != nil {
+		t.Fatalf("DeleteEvent() error = %v", err)
+	}
+	if _, err := client.GetEvent(ctx, created.ID); err == nil {
+		t.Error("Expected deleted event to be gone from the cache")
+	}
+}
+
+// TestClientOffline tests that offline clients only answer from the cache
+func TestClientOffline(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	online := newFakeClient(t)
+	online.Cache = cache.New(dir, time.Nanosecond)
+
+	start := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	params := ListEventsParams{From: start.Truncate(24 * time.Hour), To: start.Add(24 * time.Hour)}
+
+	created, err := online.CreateEvent(ctx, CreateEventParams{Summary: "Standup", Start: start, End: start.Add(15 * time.Minute)})
+	if err != nil {
+		t.Fatalf("CreateEvent() error = %v", err)
+	}
+	if _, err := online.ListEvents(ctx, params); err != nil {
+		t.Fatalf("ListEvents() error = %v", err)
+	}
+
+	offline := NewClientWithBackend(NewOfflineBackend(), "primary")
+	offline.Cache = cache.New(dir, time.Nanosecond)
+	offline.Offline = true
+
+	// Stale entries are still served offline
+	events, err := offline.ListEvents(ctx, params)
+	if err != nil || len(events) != 1 {
+		t.Fatalf("Offline ListEvents() = %v, %v; want 1 event", events, err)
+	}
+	if event, err := offline.GetEvent(ctx, created.ID); err != nil || event.Summary != "Standup" {
+		t.Errorf("Offline GetEvent() = %+v, %v", event, err)
+	}
+
+	params.Query = "other"
+	_, err = offline.ListEvents(ctx, params)
+	if appErr, ok := err.(*types.AppError); !ok || appErr.Code != types.ErrCodeCacheMiss {
+		t.Errorf("Expected CACHE_MISS for uncached listing, got %v", err)
+	}
+
+	_, err = offline.CreateEvent(ctx, CreateEventParams{Summary: "x", Start: start, End: start.Add(time.Hour)})
+	if appErr, ok := err.(*types.AppError); !ok || appErr.Code != types.ErrCodeNetworkError {
+		t.Errorf("Expected NETWORK_ERROR for offline write, got %v", err)
+	}
+}
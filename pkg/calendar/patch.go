@@ -0,0 +1,126 @@
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/btafoya/gcal-cli/pkg/types"
+	"google.golang.org/api/calendar/v3"
+)
+
+// ClearableFields are the fields an update can clear with --clear
+var ClearableFields = []string{"location", "description", "recurrence", "attendees"}
+
+// validateClearFields checks that cleared fields are known and not also set
+func validateClearFields(params CreateEventParams) error {
+	set := map[string]bool{
+		"location":    params.Location != "",
+		"description": params.Description != "",
+		"recurrence":  len(params.Recurrence) > 0,
+		"attendees":   len(params.Attendees) > 0,
+	}
+
+	for _, field := range params.Clear {
+		isSet, known := set[field]
+		if !known {
+			return types.ErrInvalidInput("clear",
+				fmt.Sprintf("unknown field '%s' (must be one of: %s)", field, strings.Join(ClearableFields, ", ")))
+		}
+		if isSet {
+			return types.ErrInvalidInput("clear",
+				fmt.Sprintf("%s cannot be both set and cleared", field))
+		}
+	}
+	return nil
+}
+
+// buildEventPatch returns a patch holding only the fields an update changes,
+// so everything else on the event (reminders, colors, conference data,
+// attachments, extended properties, attendee flags) is left as it is
+func buildEventPatch(params CreateEventParams, existing *calendar.Event) *calendar.Event {
+	patch := &calendar.Event{
+		Summary:     params.Summary,
+		Description: params.Description,
+		Location:    params.Location,
+		Recurrence:  params.Recurrence,
+	}
+
+	// Times change only together, and switching between all-day and timed
+	// clears the other form
+	if !params.Start.IsZero() && !params.End.IsZero() {
+		if params.AllDay {
+			patch.Start = &calendar.EventDateTime{
+				Date:       params.Start.Format("2006-01-02"),
+				NullFields: []string{"DateTime", "TimeZone"},
+			}
+			patch.End = &calendar.EventDateTime{
+				Date:       params.End.Format("2006-01-02"),
+				NullFields: []string{"DateTime", "TimeZone"},
+			}
+		} else {
+			tz := params.TimeZone
+			if tz == "" && existing.Start != nil {
+				tz = existing.Start.TimeZone
+			}
+			patch.Start = &calendar.EventDateTime{
+				DateTime:   params.Start.Format(time.RFC3339),
+				TimeZone:   tz,
+				NullFields: []string{"Date"},
+			}
+			patch.End = &calendar.EventDateTime{
+				DateTime:   params.End.Format(time.RFC3339),
+				TimeZone:   tz,
+				NullFields: []string{"Date"},
+			}
+		}
+	}
+
+	// A new attendee list replaces the old one; people already invited are
+	// kept as they are, with their response, comment and flags
+	if len(params.Attendees) > 0 {
+		current := make(map[string]*calendar.EventAttendee, len(existing.Attendees))
+		for _, att := range existing.Attendees {
+			current[strings.ToLower(att.Email)] = att
+		}
+
+		patch.Attendees = make([]*calendar.EventAttendee, len(params.Attendees))
+		for i, email := range params.Attendees {
+			if att, ok := current[strings.ToLower(email)]; ok {
+				kept := *att
+				patch.Attendees[i] = &kept
+				continue
+			}
+			patch.Attendees[i] = &calendar.EventAttendee{Email: email}
+		}
+	}
+
+	// Patching extended properties merges them key by key
+	patch.ExtendedProperties = newExtendedProperties(params.Properties, params.SharedProperties)
+
+	// The settings of an out-of-office, focus time or working location event
+	// can change, though its type cannot
+	applyEventType(patch, params)
+
+	// Ask for a Meet conference unless the event already has one
+	if params.Meet && existing.ConferenceData == nil {
+		patch.ConferenceData = newMeetRequest()
+	}
+
+	for _, field := range params.Clear {
+		switch field {
+		case "location":
+			patch.NullFields = append(patch.NullFields, "Location")
+		case "description":
+			patch.NullFields = append(patch.NullFields, "Description")
+		case "recurrence":
+			patch.Recurrence = []string{}
+			patch.ForceSendFields = append(patch.ForceSendFields, "Recurrence")
+		case "attendees":
+			patch.Attendees = []*calendar.EventAttendee{}
+			patch.ForceSendFields = append(patch.ForceSendFields, "Attendees")
+		}
+	}
+
+	return patch
+}
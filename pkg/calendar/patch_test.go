@@ -0,0 +1,192 @@
+package calendar
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/btafoya/gcal-cli/pkg/types"
+	"google.golang.org/api/calendar/v3"
+)
+
+// insertRichEvent stores an event using fields the CLI does not model
+func insertRichEvent(t *testing.T, fake *FakeBackend) *calendar.Event {
+	t.Helper()
+	event, err := fake.InsertEvent(context.Background(), "primary", &calendar.Event{
+		Summary:     "Design Review",
+		Description: "Review the Q1 designs",
+		Location:    "Room 2A",
+		ColorId:     "5",
+		Start:       &calendar.EventDateTime{DateTime: "2024-01-15T10:00:00Z", TimeZone: "UTC"},
+		End:         &calendar.EventDateTime{DateTime: "2024-01-15T11:00:00Z", TimeZone: "UTC"},
+		Recurrence:  []string{"RRULE:FREQ=WEEKLY;COUNT=4"},
+		Attendees: []*calendar.EventAttendee{
+			{Email: "alice@example.com", ResponseStatus: "accepted"},
+			{Email: "bob@example.com", ResponseStatus: "tentative", Optional: true, DisplayName: "Bob"},
+		},
+		Reminders: &calendar.EventReminders{
+			Overrides: []*calendar.EventReminder{{Method: "popup", Minutes: 15}},
+		},
+		ConferenceData: &calendar.ConferenceData{ConferenceId: "abc-defg-hij"},
+		Attachments:    []*calendar.EventAttachment{{FileUrl: "https://drive.google.com/file/d/1", Title: "Slides"}},
+		ExtendedProperties: &calendar.EventExtendedProperties{
+			Private: map[string]string{"ticket": "OPS-42"},
+		},
+	}, WriteOptions{ConferenceDataVersion: 1})
+	if err != nil {
+		t.Fatalf("InsertEvent() error = %v", err)
+	}
+	return event
+}
+
+// storedEvent reads an event straight from the fake backend
+func storedEvent(t *testing.T, fake *FakeBackend, eventID string) *calendar.Event {
+	t.Helper()
+	event, err := fake.GetEvent(context.Background(), "primary", eventID)
+	if err != nil {
+		t.Fatalf("GetEvent() error = %v", err)
+	}
+	return event
+}
+
+// TestUpdateEvent_KeepsUnrelatedFields tests that an update only changes the
+// fields it was given
+func TestUpdateEvent_KeepsUnrelatedFields(t *testing.T) {
+	fake := NewFakeBackend()
+	client := NewClientWithBackend(fake, "primary")
+	event := insertRichEvent(t, fake)
+
+	start := time.Date(2024, 1, 15, 14, 0, 0, 0, time.UTC)
+	_, err := client.UpdateEvent(context.Background(), event.Id, CreateEventParams{
+		Location: "Room 4B",
+		Start:    start,
+		End:      start.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("UpdateEvent() error = %v", err)
+	}
+
+	stored := storedEvent(t, fake, event.Id)
+	if stored.Location != "Room 4B" || stored.Start.DateTime != "2024-01-15T14:00:00Z" {
+		t.Errorf("Update not applied: location %q, start %+v", stored.Location, stored.Start)
+	}
+	if stored.Summary != "Design Review" || stored.Description != "Review the Q1 designs" {
+		t.Errorf("Summary or description changed: %q / %q", stored.Summary, stored.Description)
+	}
+	if stored.ColorId != "5" {
+		t.Errorf("ColorId = %q, want 5", stored.ColorId)
+	}
+	if stored.Reminders == nil || len(stored.Reminders.Overrides) != 1 {
+		t.Errorf("Reminders lost: %+v", stored.Reminders)
+	}
+	if stored.ConferenceData == nil || stored.ConferenceData.ConferenceId != "abc-defg-hij" {
+		t.Errorf("Conference data lost: %+v", stored.ConferenceData)
+	}
+	if len(stored.Attachments) != 1 {
+		t.Errorf("Attachments lost: %+v", stored.Attachments)
+	}
+	if stored.ExtendedProperties == nil || stored.ExtendedProperties.Private["ticket"] != "OPS-42" {
+		t.Errorf("Extended properties lost: %+v", stored.ExtendedProperties)
+	}
+	if len(stored.Attendees) != 2 || !stored.Attendees[1].Optional || stored.Attendees[1].ResponseStatus != "tentative" {
+		t.Errorf("Attendee flags lost: %+v", stored.Attendees)
+	}
+	if len(stored.Recurrence) != 1 {
+		t.Errorf("Recurrence lost: %v", stored.Recurrence)
+	}
+}
+
+// TestUpdateEvent_KeepsAttendeeFlags tests that a new attendee list keeps
+// everything about the people already invited
+func TestUpdateEvent_KeepsAttendeeFlags(t *testing.T) {
+	fake := NewFakeBackend()
+	client := NewClientWithBackend(fake, "primary")
+	event := insertRichEvent(t, fake)
+
+	_, err := client.UpdateEvent(context.Background(), event.Id, CreateEventParams{
+		Attendees: []string{"Bob@Example.com", "carol@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("UpdateEvent() error = %v", err)
+	}
+
+	stored := storedEvent(t, fake, event.Id)
+	if len(stored.Attendees) != 2 {
+		t.Fatalf("Attendees = %+v, want bob and carol", stored.Attendees)
+	}
+	bob, carol := stored.Attendees[0], stored.Attendees[1]
+	if bob.Email != "bob@example.com" || !bob.Optional || bob.DisplayName != "Bob" || bob.ResponseStatus != "tentative" {
+		t.Errorf("Retained attendee = %+v, want bob with his flags, name and response", bob)
+	}
+	if carol.Email != "carol@example.com" || carol.Optional || carol.DisplayName != "" {
+		t.Errorf("New attendee = %+v, want a plain record for carol", carol)
+	}
+}
+
+// TestUpdateEvent_Clear tests clearing fields explicitly
+func TestUpdateEvent_Clear(t *testing.T) {
+	fake := NewFakeBackend()
+	client := NewClientWithBackend(fake, "primary")
+	event := insertRichEvent(t, fake)
+
+	updated, err := client.UpdateEvent(context.Background(), event.Id, CreateEventParams{
+		Clear: []string{"location", "description", "recurrence", "attendees"},
+	})
+	if err != nil {
+		t.Fatalf("UpdateEvent() error = %v", err)
+	}
+	if updated.Location != "" || updated.Description != "" || len(updated.Recurrence) != 0 || len(updated.Attendees) != 0 {
+		t.Errorf("Fields not cleared: %+v", updated)
+	}
+
+	stored := storedEvent(t, fake, event.Id)
+	if stored.Summary != "Design Review" || stored.ColorId != "5" {
+		t.Errorf("Unrelated fields changed: %q, color %q", stored.Summary, stored.ColorId)
+	}
+}
+
+// TestUpdateEvent_ClearValidation tests rejected --clear values
+func TestUpdateEvent_ClearValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		params CreateEventParams
+	}{
+		{"unknown field", CreateEventParams{Clear: []string{"summary"}}},
+		{"set and cleared", CreateEventParams{Location: "Room 1", Clear: []string{"location"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newFakeClient(t).UpdateEvent(context.Background(), "abcdef", tt.params)
+			appErr, ok := err.(*types.AppError)
+			if !ok || appErr.Code != types.ErrCodeInvalidInput {
+				t.Errorf("UpdateEvent() error = %v, want INVALID_INPUT", err)
+			}
+		})
+	}
+}
+
+// TestUpdateEvent_ToAllDay tests that switching to all-day drops the times
+func TestUpdateEvent_ToAllDay(t *testing.T) {
+	fake := NewFakeBackend()
+	client := NewClientWithBackend(fake, "primary")
+	event := insertRichEvent(t, fake)
+
+	day := time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)
+	_, err := client.UpdateEvent(context.Background(), event.Id, CreateEventParams{
+		Start:  day,
+		End:    day.AddDate(0, 0, 1),
+		AllDay: true,
+	})
+	if err != nil {
+		t.Fatalf("UpdateEvent() error = %v", err)
+	}
+
+	stored := storedEvent(t, fake, event.Id)
+	if stored.Start.Date != "2024-01-16" || stored.Start.DateTime != "" || stored.Start.TimeZone != "" {
+		t.Errorf("Start = %+v, want only the date", stored.Start)
+	}
+	if stored.End.Date != "2024-01-17" || stored.End.DateTime != "" {
+		t.Errorf("End = %+v, want only the date", stored.End)
+	}
+}
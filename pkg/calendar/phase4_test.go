@@ -1,10 +1,12 @@
 package calendar
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/btafoya/gcal-cli/pkg/types"
+	"google.golang.org/api/calendar/v3"
 )
 
 // TestTimezoneConverter tests timezone conversion utilities
@@ -330,3 +332,89 @@ func TestGetBatchSummary(t *testing.T) {
 func boolPtr(b bool) *bool {
 	return &b
 }
+
+// seedBusyCalendar creates a fake-backed client with events on two calendars
+func seedBusyCalendar(t *testing.T) *Client {
+	t.Helper()
+
+	fake := NewFakeBackendFromSeed(FakeSeed{Calendars: []FakeCalendarSeed{
+		{ID: "me@example.com", Primary: true, Events: []*calendar.Event{
+			{Start: &calendar.EventDateTime{DateTime: "2024-01-15T09:00:00Z"}, End: &calendar.EventDateTime{DateTime: "2024-01-15T10:00:00Z"}},
+			{Start: &calendar.EventDateTime{DateTime: "2024-01-15T11:30:00Z"}, End: &calendar.EventDateTime{DateTime: "2024-01-15T12:00:00Z"}},
+		}},
+		{ID: "team@example.com", Events: []*calendar.Event{
+			{Start: &calendar.EventDateTime{DateTime: "2024-01-15T10:00:00Z"}, End: &calendar.EventDateTime{DateTime: "2024-01-15T11:00:00Z"}},
+		}},
+	}})
+
+	return NewClientWithBackend(fake, "primary")
+}
+
+// TestFindFreeSlots tests free slot calculation against busy periods
+func TestFindFreeSlots(t *testing.T) {
+	client := seedBusyCalendar(t)
+	start := time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 15, 13, 0, 0, 0, time.UTC)
+
+	slots, err := client.FindFreeSlots(context.Background(), "primary", start, end, time.Hour)
+	if err != nil {
+		t.Fatalf("FindFreeSlots() error = %v", err)
+	}
+
+	// 08:00, 10:00 and 12:00 are free; 09:00 and 11:00 overlap events
+	expected := []int{8, 10, 12}
+	if len(slots) != len(expected) {
+		t.Fatalf("Expected %d free slots, got %d: %v", len(expected), len(slots), slots)
+	}
+	for i, hour := range expected {
+		if slots[i].Hour() != hour {
+			t.Errorf("Slot %d: expected %02d:00, got %s", i, hour, slots[i].Format("15:04"))
+		}
+	}
+}
+
+// TestCheckConflicts tests conflict detection for proposed events
+func TestCheckConflicts(t *testing.T) {
+	client := seedBusyCalendar(t)
+
+	tests := []struct {
+		name      string
+		start     time.Time
+		end       time.Time
+		conflicts int
+	}{
+		{"before events", time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC), time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC), 0},
+		{"overlaps first", time.Date(2024, 1, 15, 9, 30, 0, 0, time.UTC), time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC), 1},
+		{"spans both", time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC), time.Date(2024, 1, 15, 13, 0, 0, 0, time.UTC), 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hasConflict, conflicts, err := client.CheckConflicts(context.Background(), "primary", tt.start, tt.end)
+			if err != nil {
+				t.Fatalf("CheckConflicts() error = %v", err)
+			}
+			if hasConflict != (tt.conflicts > 0) || len(conflicts) != tt.conflicts {
+				t.Errorf("Expected %d conflicts, got %d (%v)", tt.conflicts, len(conflicts), hasConflict)
+			}
+		})
+	}
+}
+
+// TestFindCommonFreeTime tests free time across multiple calendars
+func TestFindCommonFreeTime(t *testing.T) {
+	client := seedBusyCalendar(t)
+	start := time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 15, 13, 0, 0, 0, time.UTC)
+
+	slots, err := client.FindCommonFreeTime(context.Background(),
+		[]string{"me@example.com", "team@example.com"}, start, end, time.Hour)
+	if err != nil {
+		t.Fatalf("FindCommonFreeTime() error = %v", err)
+	}
+
+	// The team calendar blocks 10:00, leaving only 08:00 and 12:00
+	if len(slots) != 2 || slots[0].Hour() != 8 || slots[1].Hour() != 12 {
+		t.Errorf("Expected free slots at 08:00 and 12:00, got %v", slots)
+	}
+}
@@ -0,0 +1,93 @@
+package calendar
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/btafoya/gcal-cli/pkg/types"
+	"google.golang.org/api/calendar/v3"
+)
+
+// Limits the Calendar API puts on a single extended property
+const (
+	maxPropertyKeyLength   = 44
+	maxPropertyValueLength = 1024
+)
+
+// ParseProperties reads key=value pairs, as given to --prop, into extended
+// properties. A later pair replaces an earlier one with the same key.
+func ParseProperties(field string, pairs []string) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+
+	properties := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, types.ErrInvalidInput(field,
+				fmt.Sprintf("must be key=value, got '%s'", pair))
+		}
+		properties[strings.TrimSpace(key)] = value
+	}
+
+	if err := validateProperties(field, properties); err != nil {
+		return nil, err
+	}
+	return properties, nil
+}
+
+// validateProperties checks extended properties against the API's limits
+func validateProperties(field string, properties map[string]string) error {
+	for key, value := range properties {
+		if key == "" {
+			return types.ErrInvalidInput(field, "property key must not be empty")
+		}
+		if len(key) > maxPropertyKeyLength {
+			return types.ErrInvalidInput(field,
+				fmt.Sprintf("property key '%s' is longer than %d characters", key, maxPropertyKeyLength))
+		}
+		if len(value) > maxPropertyValueLength {
+			return types.ErrInvalidInput(field,
+				fmt.Sprintf("value of property '%s' is longer than %d characters", key, maxPropertyValueLength))
+		}
+	}
+	return nil
+}
+
+// validatePropertyFilters checks key=value filters as the API expects them
+// in privateExtendedProperty and sharedExtendedProperty
+func validatePropertyFilters(field string, filters []string) error {
+	for _, filter := range filters {
+		key, _, ok := strings.Cut(filter, "=")
+		if !ok || key == "" {
+			return types.ErrInvalidInput(field,
+				fmt.Sprintf("must be key=value, got '%s'", filter))
+		}
+	}
+	return nil
+}
+
+// newExtendedProperties returns the API form of private and shared
+// properties, or nil when there are none
+func newExtendedProperties(private, shared map[string]string) *calendar.EventExtendedProperties {
+	if len(private) == 0 && len(shared) == 0 {
+		return nil
+	}
+	return &calendar.EventExtendedProperties{
+		Private: private,
+		Shared:  shared,
+	}
+}
+
+// convertExtendedProperties converts API extended properties, returning nil
+// when the event has none
+func convertExtendedProperties(properties *calendar.EventExtendedProperties) *types.ExtendedProperties {
+	if properties == nil || len(properties.Private) == 0 && len(properties.Shared) == 0 {
+		return nil
+	}
+	return &types.ExtendedProperties{
+		Private: properties.Private,
+		Shared:  properties.Shared,
+	}
+}
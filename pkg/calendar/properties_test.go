@@ -0,0 +1,126 @@
+package calendar
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btafoya/gcal-cli/pkg/types"
+)
+
+// TestParseProperties tests reading key=value pairs
+func TestParseProperties(t *testing.T) {
+	got, err := ParseProperties("prop", []string{"source=ci", "ticket=OPS-12", "note=a=b", "source=agent"})
+	if err != nil {
+		t.Fatalf("ParseProperties() error = %v", err)
+	}
+	want := map[string]string{"source": "agent", "ticket": "OPS-12", "note": "a=b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseProperties() = %v, want %v", got, want)
+	}
+
+	if got, err := ParseProperties("prop", nil); got != nil || err != nil {
+		t.Errorf("ParseProperties(nil) = %v, %v; want nil, nil", got, err)
+	}
+
+	for _, pair := range []string{"source", "=ci", strings.Repeat("k", 45) + "=v"} {
+		_, err := ParseProperties("prop", []string{pair})
+		appErr, ok := err.(*types.AppError)
+		if !ok || appErr.Code != types.ErrCodeInvalidInput {
+			t.Errorf("ParseProperties(%q) error = %v, want INVALID_INPUT", pair, err)
+		}
+	}
+}
+
+// TestExtendedProperties tests tagging events, filtering listings by tag
+// and changing tags on update
+func TestExtendedProperties(t *testing.T) {
+	ctx := context.Background()
+	client := NewClientWithBackend(NewFakeBackend(), "primary")
+	start := time.Date(2024, 1, 18, 14, 0, 0, 0, time.UTC)
+
+	create := func(summary string, private, shared map[string]string) *types.Event {
+		t.Helper()
+		event, err := client.CreateEvent(ctx, CreateEventParams{
+			Summary:          summary,
+			Start:            start,
+			End:              start.Add(time.Hour),
+			Properties:       private,
+			SharedProperties: shared,
+		})
+		if err != nil {
+			t.Fatalf("CreateEvent(%s) error = %v", summary, err)
+		}
+		return event
+	}
+
+	tagged := create("Tagged", map[string]string{"source": "ci", "ticket": "OPS-12"}, map[string]string{"team": "infra"})
+	create("Other Ticket", map[string]string{"source": "ci", "ticket": "OPS-13"}, nil)
+	create("Untagged", nil, nil)
+
+	if tagged.ExtendedProperties == nil || tagged.ExtendedProperties.Private["ticket"] != "OPS-12" ||
+		tagged.ExtendedProperties.Shared["team"] != "infra" {
+		t.Errorf("ExtendedProperties = %+v, want the tags it was created with", tagged.ExtendedProperties)
+	}
+
+	list := func(private, shared []string) []string {
+		t.Helper()
+		events, err := client.ListEvents(ctx, ListEventsParams{
+			From:             start.Add(-time.Hour),
+			To:               start.Add(2 * time.Hour),
+			Properties:       private,
+			SharedProperties: shared,
+		})
+		if err != nil {
+			t.Fatalf("ListEvents() error = %v", err)
+		}
+		summaries := make([]string, len(events))
+		for i, event := range events {
+			summaries[i] = event.Summary
+		}
+		sort.Strings(summaries)
+		return summaries
+	}
+
+	tests := []struct {
+		name    string
+		private []string
+		shared  []string
+		want    []string
+	}{
+		{"one private", []string{"source=ci"}, nil, []string{"Other Ticket", "Tagged"}},
+		{"all must match", []string{"source=ci", "ticket=OPS-12"}, nil, []string{"Tagged"}},
+		{"shared", nil, []string{"team=infra"}, []string{"Tagged"}},
+		{"private is not shared", nil, []string{"source=ci"}, []string{}},
+	}
+	for _, tt := range tests {
+		if got := list(tt.private, tt.shared); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: ListEvents() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := client.ListEvents(ctx, ListEventsParams{
+		From:       start,
+		To:         start.Add(time.Hour),
+		Properties: []string{"source"},
+	}); err == nil {
+		t.Error("ListEvents() with a filter missing '=' succeeded, want error")
+	}
+
+	updated, err := client.UpdateEvent(ctx, tagged.ID, CreateEventParams{
+		Properties: map[string]string{"ticket": "OPS-99"},
+	})
+	if err != nil {
+		t.Fatalf("UpdateEvent() error = %v", err)
+	}
+	want := &types.ExtendedProperties{
+		Private: map[string]string{"source": "ci", "ticket": "OPS-99"},
+		Shared:  map[string]string{"team": "infra"},
+	}
+	if !reflect.DeepEqual(updated.ExtendedProperties, want) {
+		t.Errorf("ExtendedProperties after update = %+v, want %+v", updated.ExtendedProperties, want)
+	}
+}
@@ -0,0 +1,112 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+
+	"github.com/btafoya/gcal-cli/pkg/types"
+)
+
+// Queued write operations
+const (
+	WriteCreate = "create"
+	WriteUpdate = "update"
+	WriteDelete = "delete"
+)
+
+// PendingWrite is a create, update or delete that could not reach the API
+type PendingWrite struct {
+	Operation  string            `json:"operation"`
+	CalendarID string            `json:"calendarId"`
+	EventID    string            `json:"eventId,omitempty"`
+	Params     CreateEventParams `json:"params"`
+	// ETag is the version of the event the write was based on, when known.
+	// Replays of updates and deletes fail with CONFLICT if it has changed.
+	ETag string `json:"etag,omitempty"`
+}
+
+// WriteQueue stores writes that failed with network errors for later replay
+type WriteQueue interface {
+	Enqueue(write PendingWrite) (string, error)
+}
+
+// isNetworkError reports whether err means the API could not be reached
+func isNetworkError(err error) bool {
+	var appErr *types.AppError
+	if errors.As(err, &appErr) && appErr.Code == types.ErrCodeNetworkError {
+		return true
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// queueOnFailure records a write that failed with a network error and returns
+// a QUEUED error in its place; other errors are returned unchanged
+func (c *Client) queueOnFailure(err error, write PendingWrite) error {
+	if c.Queue == nil || !isNetworkError(err) {
+		return err
+	}
+
+	// Remember which version of the event the write was based on
+	if write.ETag == "" && write.EventID != "" && c.Cache != nil {
+		if cached, ok := c.Cache.GetEvent(write.CalendarID, write.EventID); ok {
+			write.ETag = cached.Events[0].ETag
+		}
+	}
+
+	id, qerr := c.Queue.Enqueue(write)
+	if qerr != nil {
+		return err
+	}
+
+	return types.NewAppError(types.ErrCodeQueued,
+		fmt.Sprintf("%s queued for replay", write.Operation), true).
+		WithDetails(fmt.Sprintf("queue ID: %s", id)).
+		WithWrappedError(err).
+		WithSuggestedAction("Run 'gcal-cli queue replay' once the network is available")
+}
+
+// ReplayWrite applies a queued write. Updates and deletes whose event has
+// changed since the write was queued fail with CONFLICT unless force is set.
+// Replayed writes that fail are never queued again.
+func (c *Client) ReplayWrite(ctx context.Context, write PendingWrite, force bool) (*types.Event, error) {
+	replay := *c
+	replay.CalendarID = write.CalendarID
+	replay.Queue = nil
+
+	if write.Operation != WriteCreate && write.ETag != "" && !force {
+		current, err := replay.fetchEvent(ctx, write.EventID)
+		if err != nil {
+			return nil, err
+		}
+		if current.ETag != write.ETag {
+			return nil, types.NewAppError(types.ErrCodeConflict,
+				"event changed since the write was queued", true).
+				WithDetails(fmt.Sprintf("event %s: queued etag %s, current etag %s",
+					write.EventID, write.ETag, current.ETag)).
+				WithSuggestedAction("Review the event, then replay with --force or drop the queued write").
+				WithCurrent(current)
+		}
+	}
+
+	switch write.Operation {
+	case WriteCreate:
+		return replay.CreateEvent(ctx, write.Params)
+	case WriteUpdate:
+		return replay.UpdateEvent(ctx, write.EventID, write.Params)
+	case WriteDelete:
+		return nil, replay.DeleteEvent(ctx, write.EventID)
+	default:
+		return nil, types.ErrInvalidInput("operation",
+			fmt.Sprintf("unknown queued operation '%s'", write.Operation))
+	}
+}
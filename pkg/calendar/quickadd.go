@@ -0,0 +1,252 @@
+package calendar
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/btafoya/gcal-cli/pkg/types"
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// QuickAddResult reports how a quick-add sentence was read and the event
+// created from it
+type QuickAddResult struct {
+	Text string `json:"text"`
+	// Local is true when the sentence was parsed by ParseQuickAdd rather
+	// than by the server
+	Local  bool              `json:"local"`
+	Parsed CreateEventParams `json:"parsed"`
+	Event  *types.Event      `json:"event"`
+}
+
+var (
+	// An email address, with the connector joining it to the sentence
+	quickAddAttendee = regexp.MustCompile(`(?i)(?:\s*(?:,|\band\b|\bwith\b))?\s*([\w.+-]+@[\w-]+(?:\.[\w-]+)+)`)
+	quickAddDuration = regexp.MustCompile(`(?i)\bfor\s+(\d+(?:\.\d+)?)\s*(minutes?|mins?|m|hours?|hrs?|h)\b`)
+	quickAddOffset   = regexp.MustCompile(`(?i)\bin\s+\d+\s+(?:minutes?|hours?|days?|weeks?|months?)\b`)
+	quickAddDay      = regexp.MustCompile(`(?i)\b(?:on\s+)?((?:(?:next|this)\s+)?(?:today|tomorrow|` +
+		`monday|tuesday|wednesday|thursday|friday|saturday|sunday|mon|tue|wed|thu|fri|sat|sun))\b`)
+	quickAddClock    = regexp.MustCompile(`(?i)\b(?:at\s+)?(noon|midnight|\d{1,2}(?::\d{2})?\s*(?:am|pm)|\d{1,2}:\d{2})\b`)
+	quickAddLocation = regexp.MustCompile(`(?i)\s+at\s+(.+)$`)
+	quickAddTrailing = regexp.MustCompile(`(?i)(?:\s+(?:with|and|on|at)|\s*[,;-])+$`)
+)
+
+// ParseQuickAdd reads a sentence such as "Lunch with Sam tomorrow noon at
+// Cafe Rio for 90 minutes" into create parameters without the server. It
+// recognises:
+//   - a day (today, tomorrow, [next|this] <weekday>) and a time of day
+//     (2pm, 14:30, noon), or an offset such as "in 2 hours"; a day without a
+//     time gives an all-day event
+//   - a duration ("for 90 minutes", "for 2h"), otherwise duration is used
+//   - a location, everything after the last remaining " at "
+//   - attendees, any email addresses
+//
+// What is left is the title. Times are read in tz.
+func ParseQuickAdd(text string, tz *time.Location, duration time.Duration) (CreateEventParams, error) {
+	var params CreateEventParams
+	if tz == nil {
+		tz = time.Local
+	}
+	if tz != time.Local {
+		params.TimeZone = tz.String()
+	}
+
+	rest := strings.TrimSpace(text)
+	if rest == "" {
+		return params, types.ErrMissingRequired("text")
+	}
+
+	for _, match := range quickAddAttendee.FindAllStringSubmatch(rest, -1) {
+		params.Attendees = append(params.Attendees, match[1])
+	}
+	rest = quickAddAttendee.ReplaceAllString(rest, "")
+
+	if match, remaining := cutMatch(quickAddDuration, rest); match != nil {
+		d, err := parseQuickAddDuration(match[1], match[2])
+		if err != nil {
+			return params, err
+		}
+		duration, rest = d, remaining
+	}
+
+	var day, clock, offset string
+	if match, remaining := cutMatch(quickAddOffset, rest); match != nil {
+		offset, rest = strings.ToLower(match[0]), remaining
+	}
+	if match, remaining := cutMatch(quickAddDay, rest); match != nil {
+		day, rest = strings.ToLower(strings.Join(strings.Fields(match[1]), " ")), remaining
+	}
+	if match, remaining := cutMatch(quickAddClock, rest); match != nil {
+		clock, rest = strings.ToLower(strings.ReplaceAll(match[1], " ", "")), remaining
+	}
+
+	phrase := offset
+	if phrase == "" {
+		switch {
+		case day == "" && clock == "":
+			return params, types.ErrInvalidInput("text",
+				fmt.Sprintf("no date or time found in '%s'", text)).
+				WithSuggestedAction("Include a day or time such as 'tomorrow 2pm', or use 'events create'")
+		case day == "":
+			phrase = "today at " + clock
+		case clock == "":
+			phrase = day
+			params.AllDay = true
+		default:
+			phrase = day + " at " + clock
+		}
+	}
+
+	start, err := ParseNaturalLanguageDate(phrase, tz)
+	if err != nil {
+		return params, types.ErrInvalidInput("text", err.Error())
+	}
+	params.Start, err = time.Parse(time.RFC3339, start)
+	if err != nil {
+		return params, types.ErrInvalidInput("text", err.Error())
+	}
+	if params.AllDay {
+		params.End = params.Start.AddDate(0, 0, 1)
+	} else {
+		params.End = params.Start.Add(duration)
+	}
+
+	if match, remaining := cutMatch(quickAddLocation, rest); match != nil {
+		params.Location = strings.TrimSpace(quickAddTrailing.ReplaceAllString(match[1], ""))
+		rest = remaining
+	}
+
+	params.Summary = strings.Join(strings.Fields(rest), " ")
+	params.Summary = strings.TrimSpace(quickAddTrailing.ReplaceAllString(params.Summary, ""))
+	if params.Summary == "" {
+		return params, types.ErrInvalidInput("text",
+			fmt.Sprintf("no title found in '%s'", text))
+	}
+
+	return params, nil
+}
+
+// cutMatch removes the first match of re from s, returning its submatches
+// (nil without a match) and what is left
+func cutMatch(re *regexp.Regexp, s string) ([]string, string) {
+	loc := re.FindStringSubmatchIndex(s)
+	if loc == nil {
+		return nil, s
+	}
+
+	match := make([]string, len(loc)/2)
+	for i := range match {
+		if loc[2*i] >= 0 {
+			match[i] = s[loc[2*i]:loc[2*i+1]]
+		}
+	}
+	return match, s[:loc[0]] + " " + s[loc[1]:]
+}
+
+// parseQuickAddDuration converts "90" "minutes" or "1.5" "h" to a duration
+func parseQuickAddDuration(amount, unit string) (time.Duration, error) {
+	value, err := strconv.ParseFloat(amount, 64)
+	if err != nil || value <= 0 {
+		return 0, types.ErrInvalidInput("text", fmt.Sprintf("invalid duration '%s %s'", amount, unit))
+	}
+
+	if strings.HasPrefix(strings.ToLower(unit), "h") {
+		return time.Duration(value * float64(time.Hour)), nil
+	}
+	return time.Duration(value * float64(time.Minute)), nil
+}
+
+// QuickAdd creates an event in the client's calendar from a sentence that
+// the server interprets. Parsed reports what the server made of it.
+//
+// The request cannot carry a client-side event ID, so only rate limited
+// attempts, which the server rejected before doing anything, are retried; a
+// retry after any other failure could create the event twice.
+func (c *Client) QuickAdd(ctx context.Context, text string) (*QuickAddResult, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, types.ErrMissingRequired("text")
+	}
+
+	var created *calendar.Event
+	err := c.withRetry(ctx, "quick add event", func() error {
+		var err error
+		created, err = c.Backend.QuickAddEvent(ctx, c.CalendarID, text, WriteOptions{})
+		if err != nil && !isRateLimited(err) {
+			return handleAPIError(err, "quick add event", apiResource{"calendar", c.CalendarID})
+		}
+		return err
+	})
+	if err != nil {
+		return nil, handleAPIError(err, "quick add event", apiResource{"calendar", c.CalendarID})
+	}
+
+	c.invalidateCache(c.CalendarID, created.Id, created)
+
+	event := convertEvent(created)
+	return &QuickAddResult{
+		Text:   text,
+		Parsed: paramsFromEvent(event),
+		Event:  event,
+	}, nil
+}
+
+// QuickAddLocal parses a sentence with ParseQuickAdd and creates the event
+// like CreateEvent, so it is retried and queued the same way
+func (c *Client) QuickAddLocal(ctx context.Context, text string, tz *time.Location, duration time.Duration) (*QuickAddResult, error) {
+	params, err := ParseQuickAdd(text, tz, duration)
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := c.CreateEvent(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &QuickAddResult{
+		Text:   text,
+		Local:  true,
+		Parsed: params,
+		Event:  event,
+	}, nil
+}
+
+// isRateLimited reports whether a request was refused by a rate limit
+func isRateLimited(err error) bool {
+	apiErr, ok := err.(*googleapi.Error)
+	if !ok {
+		return false
+	}
+	return apiErr.Code == 429 || apiErr.Code == 403 && isRateLimitError(apiErr)
+}
+
+// paramsFromEvent returns the create parameters that describe an event
+func paramsFromEvent(event *types.Event) CreateEventParams {
+	params := CreateEventParams{
+		Summary:     event.Summary,
+		Description: event.Description,
+		Location:    event.Location,
+		TimeZone:    event.Start.TimeZone,
+		Recurrence:  event.Recurrence,
+	}
+
+	if event.Start.Date != "" {
+		params.AllDay = true
+		params.Start, _ = time.Parse("2006-01-02", event.Start.Date)
+		params.End, _ = time.Parse("2006-01-02", event.End.Date)
+	} else {
+		params.Start, _ = time.Parse(time.RFC3339, event.Start.DateTime)
+		params.End, _ = time.Parse(time.RFC3339, event.End.DateTime)
+	}
+
+	for _, attendee := range event.Attendees {
+		params.Attendees = append(params.Attendees, attendee.Email)
+	}
+
+	return params
+}
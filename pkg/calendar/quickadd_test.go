@@ -0,0 +1,166 @@
+package calendar
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/btafoya/gcal-cli/pkg/types"
+	"google.golang.org/api/calendar/v3"
+)
+
+// TestParseQuickAdd tests reading quick-add sentences locally
+func TestParseQuickAdd(t *testing.T) {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	nextMonday := today.AddDate(0, 0, (int(time.Monday-today.Weekday())+6)%7+1)
+	nextFriday := today.AddDate(0, 0, (int(time.Friday-today.Weekday())+6)%7+1)
+
+	tests := []struct {
+		name          string
+		text          string
+		wantSummary   string
+		wantLocation  string
+		wantAttendees []string
+		wantStart     time.Time
+		wantDuration  time.Duration
+		wantAllDay    bool
+	}{
+		{
+			name:         "title, day, time and location",
+			text:         "Lunch with Sam tomorrow noon at Cafe Rio",
+			wantSummary:  "Lunch with Sam",
+			wantLocation: "Cafe Rio",
+			wantStart:    today.AddDate(0, 0, 1).Add(12 * time.Hour),
+			wantDuration: time.Hour,
+		},
+		{
+			name:          "attendees and duration",
+			text:          "Design review with alice@example.com and bob@example.com next monday at 3:30pm for 90 minutes",
+			wantSummary:   "Design review",
+			wantAttendees: []string{"alice@example.com", "bob@example.com"},
+			wantStart:     nextMonday.Add(15*time.Hour + 30*time.Minute),
+			wantDuration:  90 * time.Minute,
+		},
+		{
+			name:         "time only is today",
+			text:         "Standup at 9am for 15 min",
+			wantSummary:  "Standup",
+			wantStart:    today.Add(9 * time.Hour),
+			wantDuration: 15 * time.Minute,
+		},
+		{
+			name:         "day only is all day",
+			text:         "Offsite on Friday at Lake House",
+			wantSummary:  "Offsite",
+			wantLocation: "Lake House",
+			wantStart:    nextFriday,
+			wantDuration: 24 * time.Hour,
+			wantAllDay:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := ParseQuickAdd(tt.text, time.UTC, time.Hour)
+			if err != nil {
+				t.Fatalf("ParseQuickAdd() error = %v", err)
+			}
+			if params.Summary != tt.wantSummary {
+				t.Errorf("Summary = %q, want %q", params.Summary, tt.wantSummary)
+			}
+			if params.Location != tt.wantLocation {
+				t.Errorf("Location = %q, want %q", params.Location, tt.wantLocation)
+			}
+			if !reflect.DeepEqual(params.Attendees, tt.wantAttendees) {
+				t.Errorf("Attendees = %v, want %v", params.Attendees, tt.wantAttendees)
+			}
+			if !params.Start.Equal(tt.wantStart) {
+				t.Errorf("Start = %v, want %v", params.Start, tt.wantStart)
+			}
+			if got := params.End.Sub(params.Start); got != tt.wantDuration {
+				t.Errorf("Duration = %v, want %v", got, tt.wantDuration)
+			}
+			if params.AllDay != tt.wantAllDay {
+				t.Errorf("AllDay = %v, want %v", params.AllDay, tt.wantAllDay)
+			}
+		})
+	}
+}
+
+// TestParseQuickAdd_Errors tests sentences that cannot be read
+func TestParseQuickAdd_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		wantCode string
+	}{
+		{"empty", "  ", types.ErrCodeMissingRequired},
+		{"no date or time", "Lunch with Sam", types.ErrCodeInvalidInput},
+		{"no title", "tomorrow at 3pm", types.ErrCodeInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseQuickAdd(tt.text, time.UTC, time.Hour)
+			appErr, ok := err.(*types.AppError)
+			if !ok || appErr.Code != tt.wantCode {
+				t.Errorf("ParseQuickAdd() error = %v, want %s", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+// TestQuickAdd tests creating events from sentences on the server and locally
+func TestQuickAdd(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFakeBackend()
+	client := NewClientWithBackend(fake, "primary")
+
+	result, err := client.QuickAdd(ctx, "Lunch with Sam tomorrow noon at Cafe Rio")
+	if err != nil {
+		t.Fatalf("QuickAdd() error = %v", err)
+	}
+	if result.Local || result.Parsed.Summary != "Lunch with Sam" || result.Parsed.Location != "Cafe Rio" {
+		t.Errorf("Unexpected server result: %+v", result)
+	}
+	if _, err := fake.GetEvent(ctx, "primary", result.Event.ID); err != nil {
+		t.Errorf("Quick-added event not stored: %v", err)
+	}
+
+	result, err = client.QuickAddLocal(ctx, "Sync with alice@example.com tomorrow 2pm for 30 minutes", time.UTC, time.Hour)
+	if err != nil {
+		t.Fatalf("QuickAddLocal() error = %v", err)
+	}
+	if !result.Local || result.Event.Summary != "Sync" || len(result.Event.Attendees) != 1 {
+		t.Errorf("Unexpected local result: %+v", result)
+	}
+}
+
+// failingQuickAddBackend fails quick-add requests with a server error
+type failingQuickAddBackend struct {
+	*FakeBackend
+	calls int
+}
+
+func (b *failingQuickAddBackend) QuickAddEvent(ctx context.Context, calendarID, text string, opts WriteOptions) (*calendar.Event, error) {
+	b.calls++
+	return nil, fakeError(http.StatusServiceUnavailable, "backendError", "Backend Error")
+}
+
+// TestQuickAdd_NoRetry tests that failed quick-adds are not repeated, since
+// the server may have created the event
+func TestQuickAdd_NoRetry(t *testing.T) {
+	backend := &failingQuickAddBackend{FakeBackend: NewFakeBackend()}
+	client := NewClientWithBackend(backend, "primary")
+	client.RetryDelay = time.Millisecond
+
+	if _, err := client.QuickAdd(context.Background(), "Lunch tomorrow noon"); err == nil {
+		t.Fatal("QuickAdd() succeeded, want error")
+	}
+	if backend.calls != 1 {
+		t.Errorf("QuickAddEvent called %d times, want 1", backend.calls)
+	}
+}
@@ -0,0 +1,247 @@
+package calendar
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// GoogleRequestsPerSecond is the Calendar API's default per-user quota
+// (600 queries per minute). api.rate_limit_buffer is a fraction of it.
+const GoogleRequestsPerSecond = 10.0
+
+// RateLimiter is a token bucket shared by every request made through a
+// backend, including those from concurrent batch operations
+type RateLimiter struct {
+	mu       sync.Mutex
+	rate     float64 // tokens added per second
+	burst    float64
+	tokens   float64
+	lastFill time.Time
+}
+
+// NewRateLimiter creates a limiter allowing perSecond requests on average
+// and up to burst at once. It returns nil, which never waits, if perSecond
+// is not positive.
+func NewRateLimiter(perSecond float64, burst int) *RateLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RateLimiter{
+		rate:     perSecond,
+		burst:    float64(burst),
+		tokens:   float64(burst),
+		lastFill: time.Now(),
+	}
+}
+
+// Wait blocks until a request may be made or the context is done
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	for {
+		delay := l.reserve()
+		if delay == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// reserve takes a token if one is available, or returns how long until the
+// next one is
+func (l *RateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens += now.Sub(l.lastFill).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.lastFill = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+// limitedBackend paces requests through a rate limiter and bounds each one
+// with a deadline
+type limitedBackend struct {
+	Backend
+	limiter *RateLimiter
+	timeout time.Duration
+}
+
+// NewLimitedBackend wraps backend so every request first waits for limiter
+// and then runs with at most timeout. A nil limiter or zero timeout disables
+// that part; with neither, backend is returned unchanged.
+func NewLimitedBackend(backend Backend, limiter *RateLimiter, timeout time.Duration) Backend {
+	if limiter == nil && timeout <= 0 {
+		return backend
+	}
+	return &limitedBackend{Backend: backend, limiter: limiter, timeout: timeout}
+}
+
+// begin waits for the limiter and returns the context for one request
+func (b *limitedBackend) begin(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	if err := b.limiter.Wait(ctx); err != nil {
+		return nil, nil, err
+	}
+	if b.timeout <= 0 {
+		return ctx, func() {}, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	return ctx, cancel, nil
+}
+
+func (b *limitedBackend) InsertEvent(ctx context.Context, calendarID string, event *calendar.Event, opts WriteOptions) (*calendar.Event, error) {
+	ctx, cancel, err := b.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	return b.Backend.InsertEvent(ctx, calendarID, event, opts)
+}
+
+func (b *limitedBackend) GetEvent(ctx context.Context, calendarID, eventID string) (*calendar.Event, error) {
+	ctx, cancel, err := b.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	return b.Backend.GetEvent(ctx, calendarID, eventID)
+}
+
+func (b *limitedBackend) ListEvents(ctx context.Context, calendarID string, opts ListOptions) (*calendar.Events, error) {
+	ctx, cancel, err := b.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	return b.Backend.ListEvents(ctx, calendarID, opts)
+}
+
+func (b *limitedBackend) UpdateEvent(ctx context.Context, calendarID, eventID string, event *calendar.Event, opts WriteOptions) (*calendar.Event, error) {
+	ctx, cancel, err := b.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	return b.Backend.UpdateEvent(ctx, calendarID, eventID, event, opts)
+}
+
+func (b *limitedBackend) PatchEvent(ctx context.Context, calendarID, eventID string, patch *calendar.Event, opts WriteOptions) (*calendar.Event, error) {
+	ctx, cancel, err := b.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	return b.Backend.PatchEvent(ctx, calendarID, eventID, patch, opts)
+}
+
+func (b *limitedBackend) DeleteEvent(ctx context.Context, calendarID, eventID string) error {
+	ctx, cancel, err := b.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	return b.Backend.DeleteEvent(ctx, calendarID, eventID)
+}
+
+func (b *limitedBackend) MoveEvent(ctx context.Context, calendarID, eventID, destinationID string, opts WriteOptions) (*calendar.Event, error) {
+	ctx, cancel, err := b.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	return b.Backend.MoveEvent(ctx, calendarID, eventID, destinationID, opts)
+}
+
+func (b *limitedBackend) ListInstances(ctx context.Context, calendarID, eventID string, opts ListOptions) (*calendar.Events, error) {
+	ctx, cancel, err := b.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	return b.Backend.ListInstances(ctx, calendarID, eventID, opts)
+}
+
+func (b *limitedBackend) QuickAddEvent(ctx context.Context, calendarID, text string, opts WriteOptions) (*calendar.Event, error) {
+	ctx, cancel, err := b.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	return b.Backend.QuickAddEvent(ctx, calendarID, text, opts)
+}
+
+func (b *limitedBackend) QueryFreeBusy(ctx context.Context, request *calendar.FreeBusyRequest) (*calendar.FreeBusyResponse, error) {
+	ctx, cancel, err := b.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	return b.Backend.QueryFreeBusy(ctx, request)
+}
+
+func (b *limitedBackend) ListACL(ctx context.Context, calendarID string) (*calendar.Acl, error) {
+	ctx, cancel, err := b.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	return b.Backend.ListACL(ctx, calendarID)
+}
+
+func (b *limitedBackend) InsertACL(ctx context.Context, calendarID string, rule *calendar.AclRule) (*calendar.AclRule, error) {
+	ctx, cancel, err := b.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	return b.Backend.InsertACL(ctx, calendarID, rule)
+}
+
+func (b *limitedBackend) DeleteACL(ctx context.Context, calendarID, ruleID string) error {
+	ctx, cancel, err := b.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	return b.Backend.DeleteACL(ctx, calendarID, ruleID)
+}
+
+func (b *limitedBackend) ListCalendars(ctx context.Context) (*calendar.CalendarList, error) {
+	ctx, cancel, err := b.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	return b.Backend.ListCalendars(ctx)
+}
+
+func (b *limitedBackend) GetCalendar(ctx context.Context, calendarID string) (*calendar.Calendar, error) {
+	ctx, cancel, err := b.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	return b.Backend.GetCalendar(ctx, calendarID)
+}
@@ -0,0 +1,177 @@
+package calendar
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/btafoya/gcal-cli/pkg/types"
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// stubBackend answers ListCalendars with a test function
+type stubBackend struct {
+	Backend
+	calls         atomic.Int32
+	listCalendars func(ctx context.Context) (*calendar.CalendarList, error)
+}
+
+func (b *stubBackend) ListCalendars(ctx context.Context) (*calendar.CalendarList, error) {
+	b.calls.Add(1)
+	return b.listCalendars(ctx)
+}
+
+// TestRateLimiter tests that the token bucket paces requests after a burst
+func TestRateLimiter(t *testing.T) {
+	ctx := context.Background()
+	limiter := NewRateLimiter(50, 2)
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		if err := limiter.Wait(ctx); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+	// Two requests pass at once, the other four wait 20ms each
+	if elapsed := time.Since(start); elapsed < 70*time.Millisecond {
+		t.Errorf("6 requests at 50/s with burst 2 took %v, want at least 80ms", elapsed)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := limiter.Wait(cancelled); err == nil {
+		t.Error("Expected error waiting with a cancelled context")
+	}
+
+	// A nil limiter never waits
+	if NewRateLimiter(0, 1) != nil {
+		t.Error("Expected nil limiter for a zero rate")
+	}
+	var unlimited *RateLimiter
+	if err := unlimited.Wait(cancelled); err != nil {
+		t.Errorf("nil Wait() error = %v", err)
+	}
+}
+
+// TestLimitedBackend_Timeout tests that slow requests time out and are retried
+func TestLimitedBackend_Timeout(t *testing.T) {
+	stub := &stubBackend{Backend: NewFakeBackend(), listCalendars: func(ctx context.Context) (*calendar.CalendarList, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}}
+
+	client := NewClientWithBackend(NewLimitedBackend(stub, nil, 20*time.Millisecond), "primary")
+	client.MaxRetries = 1
+	client.RetryDelay = time.Millisecond
+
+	if _, err := client.ListCalendars(context.Background()); err == nil {
+		t.Fatal("Expected error for a request past its deadline")
+	}
+	if calls := stub.calls.Load(); calls != 2 {
+		t.Errorf("Expected the timed out request to be retried once, got %d calls", calls)
+	}
+
+	if NewLimitedBackend(stub, nil, 0) != Backend(stub) {
+		t.Error("Expected backend to be returned unchanged without limits")
+	}
+}
+
+// TestWithRetry_RateLimit tests that rate limits reported as 403 are retried
+func TestWithRetry_RateLimit(t *testing.T) {
+	stub := &stubBackend{Backend: NewFakeBackend()}
+	stub.listCalendars = func(ctx context.Context) (*calendar.CalendarList, error) {
+		if stub.calls.Load() == 1 {
+			return nil, &googleapi.Error{Code: 403, Errors: []googleapi.ErrorItem{{Reason: "rateLimitExceeded"}}}
+		}
+		return &calendar.CalendarList{}, nil
+	}
+
+	client := NewClientWithBackend(stub, "primary")
+	client.RetryDelay = time.Millisecond
+	if _, err := client.ListCalendars(context.Background()); err != nil {
+		t.Fatalf("ListCalendars() error = %v", err)
+	}
+	if calls := stub.calls.Load(); calls != 2 {
+		t.Errorf("Expected 2 calls, got %d", calls)
+	}
+}
+
+// TestWithRetry_RetryAfterPastDeadline tests that a Retry-After beyond the
+// deadline fails at once instead of waiting
+func TestWithRetry_RetryAfterPastDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	header := http.Header{}
+	header.Set("Retry-After", "30")
+	stub := &stubBackend{Backend: NewFakeBackend(), listCalendars: func(ctx context.Context) (*calendar.CalendarList, error) {
+		return nil, &googleapi.Error{Code: 503, Header: header}
+	}}
+
+	client := NewClientWithBackend(stub, "primary")
+	start := time.Now()
+	_, err := client.ListCalendars(ctx)
+	if appErr, ok := err.(*types.AppError); !ok || appErr.Code != types.ErrCodeRateLimit {
+		t.Fatalf("Expected RATE_LIMIT, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected to fail at once, took %v", elapsed)
+	}
+	if calls := stub.calls.Load(); calls != 1 {
+		t.Errorf("Expected 1 call, got %d", calls)
+	}
+}
+
+// TestWithRetry_Cancelled tests that an interrupted operation stops retrying
+func TestWithRetry_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stub := &stubBackend{Backend: NewFakeBackend(), listCalendars: func(ctx context.Context) (*calendar.CalendarList, error) {
+		cancel()
+		return nil, &googleapi.Error{Code: 503}
+	}}
+
+	client := NewClientWithBackend(stub, "primary")
+	_, err := client.ListCalendars(ctx)
+	if appErr, ok := err.(*types.AppError); !ok || appErr.Code != types.ErrCodeCancelled {
+		t.Fatalf("Expected CANCELLED, got %v", err)
+	}
+	if calls := stub.calls.Load(); calls != 1 {
+		t.Errorf("Expected no retries after cancellation, got %d calls", calls)
+	}
+}
+
+// TestRetryDelay tests jittered backoff and Retry-After
+func TestRetryDelay(t *testing.T) {
+	client := NewClientWithBackend(NewFakeBackend(), "primary")
+	client.RetryDelay = 100 * time.Millisecond
+	client.MaxRetryDelay = 300 * time.Millisecond
+
+	for i := 0; i < 100; i++ {
+		if delay := client.retryDelay(2, nil); delay < 0 || delay > 200*time.Millisecond {
+			t.Fatalf("retryDelay(2) = %v, want 0-200ms", delay)
+		}
+		if delay := client.retryDelay(5, nil); delay > 300*time.Millisecond {
+			t.Fatalf("retryDelay(5) = %v, want at most the 300ms cap", delay)
+		}
+	}
+
+	client.MaxRetryDelay = 2 * time.Minute
+	header := http.Header{}
+	header.Set("Retry-After", "7")
+	if delay := client.retryDelay(1, &googleapi.Error{Code: 429, Header: header}); delay != 7*time.Second {
+		t.Errorf("retryDelay() with Retry-After: 7 = %v, want 7s", delay)
+	}
+
+	header.Set("Retry-After", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	if delay := client.retryDelay(1, &googleapi.Error{Code: 503, Header: header}); delay < 55*time.Second || delay > time.Minute {
+		t.Errorf("retryDelay() with Retry-After date = %v, want about 1m", delay)
+	}
+
+	// Retry-After is capped like the backoff
+	header.Set("Retry-After", "86400")
+	if delay := client.retryDelay(1, &googleapi.Error{Code: 429, Header: header}); delay != 2*time.Minute {
+		t.Errorf("retryDelay() with Retry-After: 86400 = %v, want the 2m cap", delay)
+	}
+}
@@ -0,0 +1,178 @@
+package calendar
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/btafoya/gcal-cli/pkg/types"
+	"google.golang.org/api/calendar/v3"
+)
+
+// Responses an attendee can give to an invitation
+var ResponseStatuses = []string{"accepted", "declined", "tentative"}
+
+// Scopes of a response to an instance of a recurring event
+const (
+	RespondScopeThis = "this"
+	RespondScopeAll  = "all"
+)
+
+// RespondParams contains parameters for answering an invitation
+type RespondParams struct {
+	Response string // accepted, declined or tentative
+	Comment  string // note to the organizer; empty keeps the current one
+	// Scope is "this" (default) to answer only the given event, or "all"
+	// to answer every instance when the event is part of a recurring one
+	Scope string
+}
+
+// InvitesParams selects the invitations ListInvites returns
+type InvitesParams struct {
+	ListEventsParams
+	Organizer string // only invitations from this organizer
+}
+
+// RespondToEvent sets the response of the authenticated user, the event's
+// self attendee, to an event in the client's calendar. Only that attendee is
+// sent, so changes made meanwhile by the organizer or other attendees are
+// kept.
+func (c *Client) RespondToEvent(ctx context.Context, eventID string, params RespondParams) (*types.Event, error) {
+	if eventID == "" {
+		return nil, types.ErrMissingRequired("event-id")
+	}
+	if err := validateRespondParams(params); err != nil {
+		return nil, err
+	}
+
+	event, err := c.getSourceEvent(ctx, c.CalendarID, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if params.Scope == RespondScopeAll && event.RecurringEventId != "" {
+		event, err = c.getSourceEvent(ctx, c.CalendarID, event.RecurringEventId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var self *calendar.EventAttendee
+	for _, attendee := range event.Attendees {
+		if attendee.Self {
+			self = attendee
+			break
+		}
+	}
+	if self == nil {
+		return nil, types.ErrInvalidInput("event-id",
+			fmt.Sprintf("you are not an attendee of event %s", event.Id)).
+			WithSuggestedAction("Only invitations can be answered; check the event ID and --calendar-id")
+	}
+
+	answer := *self
+	answer.ResponseStatus = params.Response
+	if params.Comment != "" {
+		answer.Comment = params.Comment
+	}
+	patch := &calendar.Event{
+		Attendees:        []*calendar.EventAttendee{&answer},
+		AttendeesOmitted: true,
+	}
+
+	var updated *calendar.Event
+	err = c.withRetry(ctx, "respond to event", func() error {
+		var err error
+		updated, err = c.Backend.PatchEvent(ctx, c.CalendarID, event.Id, patch, WriteOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, handleAPIError(err, "respond to event", apiResource{"event", event.Id})
+	}
+
+	c.invalidateCache(c.CalendarID, event.Id, updated)
+
+	return convertEvent(updated), nil
+}
+
+// ListInvites lists the events in a range that the authenticated user has
+// not answered yet
+func (c *Client) ListInvites(ctx context.Context, params InvitesParams) ([]*types.Event, error) {
+	events, err := c.ListEvents(ctx, params.ListEventsParams)
+	if err != nil {
+		return nil, err
+	}
+
+	invites := make([]*types.Event, 0)
+	for _, event := range events {
+		if params.Organizer != "" && !strings.EqualFold(event.Organizer, params.Organizer) {
+			continue
+		}
+		for _, attendee := range event.Attendees {
+			if attendee.Self && attendee.ResponseStatus == "needsAction" {
+				invites = append(invites, event)
+				break
+			}
+		}
+	}
+
+	return invites, nil
+}
+
+// RespondToInvites answers each of invites, continuing past failures. With
+// scope "all", a recurring event is answered once for all its instances.
+func (c *Client) RespondToInvites(ctx context.Context, invites []*types.Event, params RespondParams) ([]*BatchResult, error) {
+	if err := validateRespondParams(params); err != nil {
+		return nil, err
+	}
+
+	results := make([]*BatchResult, 0, len(invites))
+	answered := make(map[string]bool)
+	for i, invite := range invites {
+		eventID := invite.ID
+		if params.Scope == RespondScopeAll && invite.RecurringEventID != "" {
+			eventID = invite.RecurringEventID
+		}
+		if answered[eventID] {
+			continue
+		}
+		answered[eventID] = true
+
+		event, err := c.RespondToEvent(ctx, eventID, params)
+		if err != nil {
+			appErr, ok := err.(*types.AppError)
+			if !ok {
+				appErr = types.NewAppError(types.ErrCodeAPIError, "API operation failed", true).
+					WithDetails(fmt.Sprintf("failed to respond to event %s", eventID)).
+					WithWrappedError(err)
+			}
+			results = append(results, &BatchResult{Index: i, EventID: eventID, Error: appErr})
+			continue
+		}
+		results = append(results, &BatchResult{Success: true, Index: i, EventID: eventID, Event: event})
+	}
+
+	return results, nil
+}
+
+// validateRespondParams checks a response and its scope
+func validateRespondParams(params RespondParams) error {
+	valid := false
+	for _, status := range ResponseStatuses {
+		if params.Response == status {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return types.ErrInvalidInput("response",
+			fmt.Sprintf("must be one of %s, got '%s'", strings.Join(ResponseStatuses, ", "), params.Response))
+	}
+
+	switch params.Scope {
+	case "", RespondScopeThis, RespondScopeAll:
+		return nil
+	default:
+		return types.ErrInvalidInput("scope",
+			fmt.Sprintf("must be '%s' or '%s', got '%s'", RespondScopeThis, RespondScopeAll, params.Scope))
+	}
+}
@@ -0,0 +1,185 @@
+package calendar
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/btafoya/gcal-cli/pkg/types"
+	"google.golang.org/api/calendar/v3"
+)
+
+// newInvitedClient creates a client for me@example.com with a one-off
+// invitation ("review"), a weekly invitation ("standup", four instances) and
+// an event without attendees ("focus")
+func newInvitedClient(t *testing.T) (*Client, *FakeBackend) {
+	t.Helper()
+
+	invite := func(id, start, end string, recurrence ...string) *calendar.Event {
+		return &calendar.Event{
+			Id:         id,
+			Summary:    id,
+			Start:      &calendar.EventDateTime{DateTime: start},
+			End:        &calendar.EventDateTime{DateTime: end},
+			Recurrence: recurrence,
+			Organizer:  &calendar.EventOrganizer{Email: "boss@example.com"},
+			Attendees: []*calendar.EventAttendee{
+				{Email: "boss@example.com", Organizer: true, ResponseStatus: "accepted"},
+				{Email: "me@example.com", ResponseStatus: "needsAction"},
+				{Email: "other@example.com", ResponseStatus: "needsAction"},
+			},
+		}
+	}
+
+	fake := NewFakeBackendFromSeed(FakeSeed{Calendars: []FakeCalendarSeed{{
+		ID:      "me@example.com",
+		Primary: true,
+		Events: []*calendar.Event{
+			invite("review", "2030-01-08T14:00:00Z", "2030-01-08T15:00:00Z"),
+			invite("standup", "2030-01-07T09:00:00Z", "2030-01-07T09:15:00Z", "RRULE:FREQ=WEEKLY;COUNT=4"),
+			{
+				Id:    "focus",
+				Start: &calendar.EventDateTime{DateTime: "2030-01-09T09:00:00Z"},
+				End:   &calendar.EventDateTime{DateTime: "2030-01-09T11:00:00Z"},
+			},
+		},
+	}}})
+	client := NewClientWithBackend(fake, "primary")
+	client.RetryDelay = time.Millisecond
+	return client, fake
+}
+
+// invitesIn is the range holding every event of newInvitedClient
+var invitesIn = ListEventsParams{
+	From: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+	To:   time.Date(2030, 2, 1, 0, 0, 0, 0, time.UTC),
+}
+
+// responses maps each attendee of a stored event to its response
+func responses(t *testing.T, fake *FakeBackend, eventID string) map[string]string {
+	t.Helper()
+	event, err := fake.GetEvent(context.Background(), "primary", eventID)
+	if err != nil {
+		t.Fatalf("GetEvent(%s) error = %v", eventID, err)
+	}
+	result := make(map[string]string)
+	for _, attendee := range event.Attendees {
+		result[attendee.Email] = attendee.ResponseStatus
+		if attendee.Comment != "" {
+			result[attendee.Email] += ": " + attendee.Comment
+		}
+	}
+	return result
+}
+
+// TestRespondToEvent tests that responding changes only the self attendee
+func TestRespondToEvent(t *testing.T) {
+	client, fake := newInvitedClient(t)
+
+	event, err := client.RespondToEvent(context.Background(), "review", RespondParams{
+		Response: "tentative",
+		Comment:  "running late",
+	})
+	if err != nil {
+		t.Fatalf("RespondToEvent() error = %v", err)
+	}
+	if event.Organizer != "boss@example.com" {
+		t.Errorf("Organizer = %q, want boss@example.com", event.Organizer)
+	}
+
+	got := responses(t, fake, "review")
+	want := map[string]string{
+		"boss@example.com":  "accepted",
+		"me@example.com":    "tentative: running late",
+		"other@example.com": "needsAction",
+	}
+	for email, response := range want {
+		if got[email] != response {
+			t.Errorf("Response of %s = %q, want %q", email, got[email], response)
+		}
+	}
+}
+
+// TestRespondToEvent_Validation tests rejected responses
+func TestRespondToEvent_Validation(t *testing.T) {
+	client, _ := newInvitedClient(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		eventID string
+		params  RespondParams
+	}{
+		{"unknown response", "review", RespondParams{Response: "maybe"}},
+		{"unknown scope", "review", RespondParams{Response: "accepted", Scope: "following"}},
+		{"not an attendee", "focus", RespondParams{Response: "accepted"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.RespondToEvent(ctx, tt.eventID, tt.params)
+			appErr, ok := err.(*types.AppError)
+			if !ok || appErr.Code != types.ErrCodeInvalidInput {
+				t.Errorf("RespondToEvent() error = %v, want INVALID_INPUT", err)
+			}
+		})
+	}
+}
+
+// TestRespondToEvent_Scope tests answering one instance or the whole series
+func TestRespondToEvent_Scope(t *testing.T) {
+	client, fake := newInvitedClient(t)
+	ctx := context.Background()
+
+	first := "standup_20300107T090000Z"
+	if _, err := client.RespondToEvent(ctx, first, RespondParams{Response: "declined"}); err != nil {
+		t.Fatalf("RespondToEvent(this) error = %v", err)
+	}
+	if got := responses(t, fake, first)["me@example.com"]; got != "declined" {
+		t.Errorf("Instance response = %q, want declined", got)
+	}
+	if got := responses(t, fake, "standup")["me@example.com"]; got != "needsAction" {
+		t.Errorf("Series response after answering one instance = %q, want needsAction", got)
+	}
+
+	second := "standup_20300114T090000Z"
+	if _, err := client.RespondToEvent(ctx, second, RespondParams{Response: "accepted", Scope: RespondScopeAll}); err != nil {
+		t.Fatalf("RespondToEvent(all) error = %v", err)
+	}
+	if got := responses(t, fake, "standup")["me@example.com"]; got != "accepted" {
+		t.Errorf("Series response = %q, want accepted", got)
+	}
+}
+
+// TestListInvites tests listing unanswered invitations and answering them
+// in bulk
+func TestListInvites(t *testing.T) {
+	client, _ := newInvitedClient(t)
+	ctx := context.Background()
+
+	invites, err := client.ListInvites(ctx, InvitesParams{ListEventsParams: invitesIn})
+	if err != nil {
+		t.Fatalf("ListInvites() error = %v", err)
+	}
+	if len(invites) != 5 {
+		t.Fatalf("ListInvites() returned %d invites, want 5 (review and four standups)", len(invites))
+	}
+
+	filtered, err := client.ListInvites(ctx, InvitesParams{ListEventsParams: invitesIn, Organizer: "someone@example.com"})
+	if err != nil || len(filtered) != 0 {
+		t.Errorf("ListInvites(organizer) = %d invites, %v; want none", len(filtered), err)
+	}
+
+	results, err := client.RespondToInvites(ctx, invites, RespondParams{Response: "accepted", Scope: RespondScopeAll})
+	if err != nil {
+		t.Fatalf("RespondToInvites() error = %v", err)
+	}
+	if summary := GetBatchSummary(results); summary["total"] != 2 || summary["success"] != 2 {
+		t.Errorf("RespondToInvites() summary = %v, want 2 responses (review and the standup series)", summary)
+	}
+
+	remaining, err := client.ListInvites(ctx, InvitesParams{ListEventsParams: invitesIn})
+	if err != nil || len(remaining) != 0 {
+		t.Errorf("ListInvites() after responding = %d invites, %v; want none", len(remaining), err)
+	}
+}
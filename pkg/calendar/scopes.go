@@ -0,0 +1,183 @@
+package calendar
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/btafoya/gcal-cli/pkg/types"
+	"google.golang.org/api/calendar/v3"
+)
+
+// scopeRequirement lists the scopes that allow an operation and the
+// 'auth login --scopes' level that grants one of them
+type scopeRequirement struct {
+	operation string
+	scopes    []string
+	level     string
+}
+
+var (
+	readEvents = scopeRequirement{"reading events", []string{
+		calendar.CalendarScope, calendar.CalendarReadonlyScope,
+		calendar.CalendarEventsScope, calendar.CalendarEventsReadonlyScope,
+	}, "readonly"}
+	writeEvents = scopeRequirement{"changing events", []string{
+		calendar.CalendarScope, calendar.CalendarEventsScope,
+	}, "events"}
+	readFreeBusy = scopeRequirement{"querying free/busy", []string{
+		calendar.CalendarScope, calendar.CalendarReadonlyScope,
+		calendar.CalendarFreebusyScope, calendar.CalendarEventsFreebusyScope,
+	}, "readonly"}
+	readCalendars = scopeRequirement{"reading calendars", []string{
+		calendar.CalendarScope, calendar.CalendarReadonlyScope,
+		calendar.CalendarCalendarlistScope, calendar.CalendarCalendarlistReadonlyScope,
+		calendar.CalendarCalendarsScope, calendar.CalendarCalendarsReadonlyScope,
+	}, "readonly"}
+	readACL = scopeRequirement{"reading sharing rules", []string{
+		calendar.CalendarScope, calendar.CalendarAclsScope, calendar.CalendarAclsReadonlyScope,
+	}, "full"}
+	writeACL = scopeRequirement{"changing sharing rules", []string{
+		calendar.CalendarScope, calendar.CalendarAclsScope,
+	}, "full"}
+)
+
+// scopedBackend refuses operations the granted scopes do not cover before
+// any request is sent
+type scopedBackend struct {
+	Backend
+	granted map[string]bool
+}
+
+// NewScopedBackend wraps backend so operations outside the granted OAuth2
+// scopes fail up front with PERMISSION_DENIED. With no scopes (unknown),
+// backend is returned unchanged.
+func NewScopedBackend(backend Backend, scopes []string) Backend {
+	if len(scopes) == 0 {
+		return backend
+	}
+
+	granted := make(map[string]bool, len(scopes))
+	for _, scope := range scopes {
+		granted[scope] = true
+	}
+	return &scopedBackend{Backend: backend, granted: granted}
+}
+
+// require checks that one of the requirement's scopes was granted
+func (b *scopedBackend) require(req scopeRequirement) error {
+	for _, scope := range req.scopes {
+		if b.granted[scope] {
+			return nil
+		}
+	}
+
+	return types.NewAppError(types.ErrCodePermissionDenied,
+		"insufficient permissions", false).
+		WithDetails(fmt.Sprintf("the stored token does not allow %s", req.operation)).
+		WithSuggestedAction(fmt.Sprintf("Run 'gcal-cli auth login --scopes %s' to grant access", req.level))
+}
+
+func (b *scopedBackend) InsertEvent(ctx context.Context, calendarID string, event *calendar.Event, opts WriteOptions) (*calendar.Event, error) {
+	if err := b.require(writeEvents); err != nil {
+		return nil, err
+	}
+	return b.Backend.InsertEvent(ctx, calendarID, event, opts)
+}
+
+func (b *scopedBackend) GetEvent(ctx context.Context, calendarID, eventID string) (*calendar.Event, error) {
+	if err := b.require(readEvents); err != nil {
+		return nil, err
+	}
+	return b.Backend.GetEvent(ctx, calendarID, eventID)
+}
+
+func (b *scopedBackend) ListEvents(ctx context.Context, calendarID string, opts ListOptions) (*calendar.Events, error) {
+	if err := b.require(readEvents); err != nil {
+		return nil, err
+	}
+	return b.Backend.ListEvents(ctx, calendarID, opts)
+}
+
+func (b *scopedBackend) UpdateEvent(ctx context.Context, calendarID, eventID string, event *calendar.Event, opts WriteOptions) (*calendar.Event, error) {
+	if err := b.require(writeEvents); err != nil {
+		return nil, err
+	}
+	return b.Backend.UpdateEvent(ctx, calendarID, eventID, event, opts)
+}
+
+func (b *scopedBackend) PatchEvent(ctx context.Context, calendarID, eventID string, patch *calendar.Event, opts WriteOptions) (*calendar.Event, error) {
+	if err := b.require(writeEvents); err != nil {
+		return nil, err
+	}
+	return b.Backend.PatchEvent(ctx, calendarID, eventID, patch, opts)
+}
+
+func (b *scopedBackend) DeleteEvent(ctx context.Context, calendarID, eventID string) error {
+	if err := b.require(writeEvents); err != nil {
+		return err
+	}
+	return b.Backend.DeleteEvent(ctx, calendarID, eventID)
+}
+
+func (b *scopedBackend) MoveEvent(ctx context.Context, calendarID, eventID, destinationID string, opts WriteOptions) (*calendar.Event, error) {
+	if err := b.require(writeEvents); err != nil {
+		return nil, err
+	}
+	return b.Backend.MoveEvent(ctx, calendarID, eventID, destinationID, opts)
+}
+
+func (b *scopedBackend) ListInstances(ctx context.Context, calendarID, eventID string, opts ListOptions) (*calendar.Events, error) {
+	if err := b.require(readEvents); err != nil {
+		return nil, err
+	}
+	return b.Backend.ListInstances(ctx, calendarID, eventID, opts)
+}
+
+func (b *scopedBackend) QuickAddEvent(ctx context.Context, calendarID, text string, opts WriteOptions) (*calendar.Event, error) {
+	if err := b.require(writeEvents); err != nil {
+		return nil, err
+	}
+	return b.Backend.QuickAddEvent(ctx, calendarID, text, opts)
+}
+
+func (b *scopedBackend) QueryFreeBusy(ctx context.Context, request *calendar.FreeBusyRequest) (*calendar.FreeBusyResponse, error) {
+	if err := b.require(readFreeBusy); err != nil {
+		return nil, err
+	}
+	return b.Backend.QueryFreeBusy(ctx, request)
+}
+
+func (b *scopedBackend) ListACL(ctx context.Context, calendarID string) (*calendar.Acl, error) {
+	if err := b.require(readACL); err != nil {
+		return nil, err
+	}
+	return b.Backend.ListACL(ctx, calendarID)
+}
+
+func (b *scopedBackend) InsertACL(ctx context.Context, calendarID string, rule *calendar.AclRule) (*calendar.AclRule, error) {
+	if err := b.require(writeACL); err != nil {
+		return nil, err
+	}
+	return b.Backend.InsertACL(ctx, calendarID, rule)
+}
+
+func (b *scopedBackend) DeleteACL(ctx context.Context, calendarID, ruleID string) error {
+	if err := b.require(writeACL); err != nil {
+		return err
+	}
+	return b.Backend.DeleteACL(ctx, calendarID, ruleID)
+}
+
+func (b *scopedBackend) ListCalendars(ctx context.Context) (*calendar.CalendarList, error) {
+	if err := b.require(readCalendars); err != nil {
+		return nil, err
+	}
+	return b.Backend.ListCalendars(ctx)
+}
+
+func (b *scopedBackend) GetCalendar(ctx context.Context, calendarID string) (*calendar.Calendar, error) {
+	if err := b.require(readCalendars); err != nil {
+		return nil, err
+	}
+	return b.Backend.GetCalendar(ctx, calendarID)
+}
@@ -0,0 +1,45 @@
+package calendar
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/btafoya/gcal-cli/pkg/types"
+	"google.golang.org/api/calendar/v3"
+)
+
+// TestScopedBackend tests that operations outside the granted scopes are refused up front
+func TestScopedBackend(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFakeBackend()
+	start := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	params := CreateEventParams{Summary: "Standup", Start: start, End: start.Add(15 * time.Minute)}
+
+	readonly := NewClientWithBackend(NewScopedBackend(fake, []string{calendar.CalendarReadonlyScope}), "primary")
+	_, err := readonly.CreateEvent(ctx, params)
+	appErr, ok := err.(*types.AppError)
+	if !ok || appErr.Code != types.ErrCodePermissionDenied {
+		t.Fatalf("Expected PERMISSION_DENIED for write with read-only scope, got %v", err)
+	}
+	if appErr.SuggestedAction != "Run 'gcal-cli auth login --scopes events' to grant access" {
+		t.Errorf("Unexpected suggestion: %s", appErr.SuggestedAction)
+	}
+
+	events := NewClientWithBackend(NewScopedBackend(fake, []string{calendar.CalendarEventsScope, calendar.CalendarReadonlyScope}), "primary")
+	created, err := events.CreateEvent(ctx, params)
+	if err != nil {
+		t.Fatalf("CreateEvent() with events scope error = %v", err)
+	}
+	if _, err := readonly.GetEvent(ctx, created.ID); err != nil {
+		t.Errorf("GetEvent() with read-only scope error = %v", err)
+	}
+	if _, err := events.ListCalendars(ctx); err != nil {
+		t.Errorf("ListCalendars() with events scope error = %v", err)
+	}
+
+	// Unknown scopes leave the backend unchecked
+	if NewScopedBackend(fake, nil) != Backend(fake) {
+		t.Error("Expected backend to be returned unchanged without scopes")
+	}
+}
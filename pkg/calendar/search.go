@@ -21,6 +21,11 @@ type SearchFilter struct {
 	IsRecurring    *bool     // Filter by recurring events
 	MaxResults     int64     // Maximum results to return
 	OrderBy        string    // Sort order
+	// Properties and SharedProperties are key=value extended property
+	// filters, applied by the server
+	Properties       []string
+	SharedProperties []string
+	EventTypes       []string // Filter by event type, applied by the server
 }
 
 // SearchEvents performs an advanced search for events
@@ -37,6 +42,10 @@ func (c *Client) SearchEvents(ctx context.Context, filter SearchFilter) ([]*type
 		MaxResults: filter.MaxResults,
 		Query:      filter.Query,
 		OrderBy:    filter.OrderBy,
+
+		Properties:       filter.Properties,
+		SharedProperties: filter.SharedProperties,
+		EventTypes:       filter.EventTypes,
 	}
 
 	events, err := c.ListEvents(ctx, params)
@@ -26,6 +26,7 @@ type EventTemplate struct {
 	ColorID           string   `json:"colorId,omitempty"`
 	Visibility        string   `json:"visibility,omitempty"`
 	SendNotifications bool     `json:"sendNotifications"`
+	Meet              bool     `json:"meet,omitempty"` // add a Google Meet link
 }
 
 // TemplateManager manages event templates
@@ -200,17 +201,28 @@ func (c *Client) CreateEventFromTemplate(ctx context.Context, calendarID string,
 		if location, ok := overrides["location"].(string); ok {
 			event.Location = location
 		}
+		if meet, ok := overrides["meet"].(bool); ok {
+			template.Meet = meet
+		}
+	}
+
+	// Add a Meet conference
+	if template.Meet {
+		event.ConferenceData = newMeetRequest()
 	}
 
-	// Create event
-	createdEvent, err := c.Service.Events.Insert(calendarID, event).
-		SendUpdates("all").
-		Context(ctx).
-		Do()
+	// Create event with a client-side ID, so a retried insert cannot
+	// create a duplicate
+	event.Id = newEventID("")
+	opts := writeOptionsFor(event)
+	opts.SendUpdates = "all"
+	createdEvent, err := c.insertEvent(ctx, calendarID, event, opts)
 	if err != nil {
-		return nil, types.ErrAPIError.WithDetails(fmt.Sprintf("failed to create event from template: %v", err))
+		return nil, handleAPIError(err, "create event from template", apiResource{"event", event.Id})
 	}
 
+	c.invalidateCache(calendarID, createdEvent.Id, createdEvent)
+
 	return convertEvent(createdEvent), nil
 }
 
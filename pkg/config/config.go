@@ -16,6 +16,13 @@ type Config struct {
 	Auth     AuthConfig     `mapstructure:"auth"`
 	API      APIConfig      `mapstructure:"api"`
 	Events   EventsConfig   `mapstructure:"events"`
+	Server   ServerConfig   `mapstructure:"server"`
+	Backend  BackendConfig  `mapstructure:"backend"`
+	Cache    CacheConfig    `mapstructure:"cache"`
+	Queue    QueueConfig    `mapstructure:"queue"`
+
+	Profile  string                   `mapstructure:"profile"`
+	Profiles map[string]ProfileConfig `mapstructure:"profiles"`
 }
 
 // CalendarConfig holds calendar-related configuration
@@ -29,6 +36,9 @@ type OutputConfig struct {
 	DefaultFormat string `mapstructure:"default_format"`
 	ColorEnabled  bool   `mapstructure:"color_enabled"`
 	PrettyPrint   bool   `mapstructure:"pretty_print"`
+
+	// ErrorsToStderr writes error responses to stderr instead of stdout
+	ErrorsToStderr bool `mapstructure:"errors_to_stderr"`
 }
 
 // AuthConfig holds authentication-related configuration
@@ -36,10 +46,16 @@ type AuthConfig struct {
 	CredentialsPath string `mapstructure:"credentials_path"`
 	TokensPath      string `mapstructure:"tokens_path"`
 	AutoRefresh     bool   `mapstructure:"auto_refresh"`
+	Mode            string `mapstructure:"mode"` // oauth or none
+	Type            string `mapstructure:"type"` // oauth or service_account
+	Impersonate     string `mapstructure:"impersonate"`
+	TokenStore      string `mapstructure:"token_store"` // file or encrypted
+	RevokeURL       string `mapstructure:"revoke_url"`
 }
 
 // APIConfig holds API-related configuration
 type APIConfig struct {
+	Endpoint        string  `mapstructure:"endpoint"`
 	RetryAttempts   int     `mapstructure:"retry_attempts"`
 	RetryDelayMs    int     `mapstructure:"retry_delay_ms"`
 	RetryMaxDelayMs int     `mapstructure:"retry_max_delay_ms"`
@@ -54,6 +70,34 @@ type EventsConfig struct {
 	SendNotifications      bool `mapstructure:"send_notifications"`
 }
 
+// ServerConfig holds settings for the local REST server
+type ServerConfig struct {
+	Listen                 string `mapstructure:"listen"`
+	AuthToken              string `mapstructure:"auth_token"`
+	ShutdownTimeoutSeconds int    `mapstructure:"shutdown_timeout_seconds"`
+}
+
+// BackendConfig selects the calendar backend
+type BackendConfig struct {
+	Type        string `mapstructure:"type"`         // google or fake
+	FakeSeed    string `mapstructure:"fake_seed"`    // JSON seed file for the fake backend
+	FakePersist bool   `mapstructure:"fake_persist"` // write fake backend changes back to the seed file
+}
+
+// CacheConfig holds settings for the local event cache
+type CacheConfig struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	TTLSeconds int    `mapstructure:"ttl_seconds"`
+	Dir        string `mapstructure:"dir"`     // defaults to <config dir>/cache
+	Offline    bool   `mapstructure:"offline"` // answer reads from the cache only
+}
+
+// QueueConfig holds settings for the offline write queue
+type QueueConfig struct {
+	OnFailure bool   `mapstructure:"on_failure"` // queue writes that fail with network errors
+	Path      string `mapstructure:"path"`       // defaults to <config dir>/queue.json
+}
+
 // GetConfigDir returns the configuration directory path
 func GetConfigDir() (string, error) {
 	// Check XDG_CONFIG_HOME first
@@ -113,6 +157,20 @@ func Initialize(cfgFile string) error {
 	viper.SetEnvPrefix("GCAL")
 	viper.AutomaticEnv()
 
+	// Nested keys are not matched by AutomaticEnv, so bind these explicitly
+	viper.BindEnv("output.errors_to_stderr", "GCAL_OUTPUT_ERRORS_TO_STDERR")
+	viper.BindEnv("api.endpoint", "GCAL_API_ENDPOINT")
+	viper.BindEnv("api.retry_attempts", "GCAL_API_RETRY_ATTEMPTS")
+	viper.BindEnv("api.retry_delay_ms", "GCAL_API_RETRY_DELAY_MS")
+	viper.BindEnv("api.retry_max_delay_ms", "GCAL_API_RETRY_MAX_DELAY_MS")
+	viper.BindEnv("api.timeout_seconds", "GCAL_API_TIMEOUT_SECONDS")
+	viper.BindEnv("api.rate_limit_buffer", "GCAL_API_RATE_LIMIT_BUFFER")
+	viper.BindEnv("auth.mode", "GCAL_AUTH_MODE")
+	viper.BindEnv("auth.type", "GCAL_AUTH_TYPE")
+	viper.BindEnv("auth.impersonate", "GCAL_AUTH_IMPERSONATE")
+	viper.BindEnv("auth.token_store", "GCAL_AUTH_TOKEN_STORE")
+	viper.BindEnv("auth.revoke_url", "GCAL_AUTH_REVOKE_URL")
+
 	// Read config file (it's okay if it doesn't exist)
 	if err := viper.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
@@ -121,6 +179,12 @@ func Initialize(cfgFile string) error {
 		}
 	}
 
+	// auth.tokens_path used to be auth.token_path; older config files still
+	// set the old key, so it stands in for the default
+	if viper.InConfig("auth.token_path") && !viper.InConfig("auth.tokens_path") {
+		viper.SetDefault("auth.tokens_path", viper.GetString("auth.token_path"))
+	}
+
 	return nil
 }
 
@@ -134,14 +198,21 @@ func setDefaults() {
 	viper.SetDefault("output.default_format", "json")
 	viper.SetDefault("output.color_enabled", false)
 	viper.SetDefault("output.pretty_print", true)
+	viper.SetDefault("output.errors_to_stderr", false)
 
 	// Auth defaults
 	configDir, _ := GetConfigDir()
 	viper.SetDefault("auth.credentials_path", filepath.Join(configDir, "credentials.json"))
 	viper.SetDefault("auth.tokens_path", filepath.Join(configDir, "tokens.json"))
 	viper.SetDefault("auth.auto_refresh", true)
+	viper.SetDefault("auth.mode", "oauth")
+	viper.SetDefault("auth.type", "oauth")
+	viper.SetDefault("auth.impersonate", "")
+	viper.SetDefault("auth.token_store", "file")
+	viper.SetDefault("auth.revoke_url", "https://oauth2.googleapis.com/revoke")
 
 	// API defaults
+	viper.SetDefault("api.endpoint", "")
 	viper.SetDefault("api.retry_attempts", 3)
 	viper.SetDefault("api.retry_delay_ms", 1000)
 	viper.SetDefault("api.retry_max_delay_ms", 10000)
@@ -152,6 +223,26 @@ func setDefaults() {
 	viper.SetDefault("events.default_duration_minutes", 60)
 	viper.SetDefault("events.default_reminder_minutes", 10)
 	viper.SetDefault("events.send_notifications", true)
+
+	// Server defaults
+	viper.SetDefault("server.listen", "127.0.0.1:8787")
+	viper.SetDefault("server.auth_token", "")
+	viper.SetDefault("server.shutdown_timeout_seconds", 10)
+
+	// Backend defaults
+	viper.SetDefault("backend.type", "google")
+	viper.SetDefault("backend.fake_seed", "")
+	viper.SetDefault("backend.fake_persist", false)
+
+	// Cache defaults
+	viper.SetDefault("cache.enabled", true)
+	viper.SetDefault("cache.ttl_seconds", 300)
+	viper.SetDefault("cache.dir", filepath.Join(configDir, "cache"))
+	viper.SetDefault("cache.offline", false)
+
+	// Queue defaults
+	viper.SetDefault("queue.on_failure", false)
+	viper.SetDefault("queue.path", filepath.Join(configDir, "queue.json"))
 }
 
 // Load loads the configuration into a Config struct
@@ -189,6 +280,9 @@ func Get(key string) interface{} {
 
 // GetString returns a string configuration value
 func GetString(key string) string {
+	if value, ok := profileOverrides[key]; ok {
+		return value
+	}
 	return viper.GetString(key)
 }
 
@@ -202,6 +296,11 @@ func GetInt(key string) int {
 	return viper.GetInt(key)
 }
 
+// GetFloat64 returns a floating-point configuration value
+func GetFloat64(key string) float64 {
+	return viper.GetFloat64(key)
+}
+
 // Set sets a configuration value
 func Set(key string, value interface{}) {
 	viper.Set(key, value)
@@ -214,8 +313,28 @@ func DisplayConfig() (string, error) {
 		return "", err
 	}
 
+	// Show the settings of the active profile
+	for key, value := range profileOverrides {
+		switch key {
+		case "auth.credentials_path":
+			cfg.Auth.CredentialsPath = value
+		case "auth.tokens_path":
+			cfg.Auth.TokensPath = value
+		case "calendar.default_calendar_id":
+			cfg.Calendar.DefaultCalendarID = value
+		case "calendar.default_timezone":
+			cfg.Calendar.DefaultTimezone = value
+		case "auth.type":
+			cfg.Auth.Type = value
+		case "auth.impersonate":
+			cfg.Auth.Impersonate = value
+		}
+	}
+
 	return fmt.Sprintf(`Current Configuration:
 
+Profile:               %s (%d configured)
+
 Calendar:
   Default Calendar ID: %s
   Default Timezone:    %s
@@ -224,35 +343,95 @@ Output:
   Default Format:      %s
   Color Enabled:       %t
   Pretty Print:        %t
+  Errors To Stderr:    %t
 
 Authentication:
   Credentials Path:    %s
   Tokens Path:         %s
   Auto Refresh:        %t
+  Mode:                %s
+  Type:                %s
+  Impersonate:         %s
+  Token Store:         %s
+  Revoke URL:          %s
 
 API:
+  Endpoint:            %s
   Retry Attempts:      %d
   Retry Delay (ms):    %d
+  Max Delay (ms):      %d
   Timeout (seconds):   %d
+  Rate Limit Buffer:   %.2f
 
 Events:
   Default Duration:    %d minutes
   Default Reminder:    %d minutes
   Send Notifications:  %t
+
+Server:
+  Listen Address:      %s
+  Auth Token Set:      %t
+  Shutdown Timeout:    %d seconds
+
+Backend:
+  Type:                %s
+  Fake Seed:           %s
+  Fake Persist:        %t
+
+Cache:
+  Enabled:             %t
+  TTL:                 %d seconds
+  Directory:           %s
+  Offline:             %t
+
+Queue:
+  Queue On Failure:    %t
+  Journal Path:        %s
 `,
+		ActiveProfile(),
+		len(cfg.Profiles),
 		cfg.Calendar.DefaultCalendarID,
 		cfg.Calendar.DefaultTimezone,
 		cfg.Output.DefaultFormat,
 		cfg.Output.ColorEnabled,
 		cfg.Output.PrettyPrint,
+		cfg.Output.ErrorsToStderr,
 		cfg.Auth.CredentialsPath,
 		cfg.Auth.TokensPath,
 		cfg.Auth.AutoRefresh,
+		cfg.Auth.Mode,
+		cfg.Auth.Type,
+		cfg.Auth.Impersonate,
+		cfg.Auth.TokenStore,
+		cfg.Auth.RevokeURL,
+		displayEndpoint(cfg.API.Endpoint),
 		cfg.API.RetryAttempts,
 		cfg.API.RetryDelayMs,
+		cfg.API.RetryMaxDelayMs,
 		cfg.API.TimeoutSeconds,
+		cfg.API.RateLimitBuffer,
 		cfg.Events.DefaultDurationMinutes,
 		cfg.Events.DefaultReminderMinutes,
 		cfg.Events.SendNotifications,
+		cfg.Server.Listen,
+		cfg.Server.AuthToken != "",
+		cfg.Server.ShutdownTimeoutSeconds,
+		cfg.Backend.Type,
+		cfg.Backend.FakeSeed,
+		cfg.Backend.FakePersist,
+		cfg.Cache.Enabled,
+		cfg.Cache.TTLSeconds,
+		cfg.Cache.Dir,
+		cfg.Cache.Offline,
+		cfg.Queue.OnFailure,
+		cfg.Queue.Path,
 	), nil
 }
+
+// displayEndpoint describes the API endpoint, which is empty for Google's default
+func displayEndpoint(endpoint string) string {
+	if endpoint == "" {
+		return "(Google default)"
+	}
+	return endpoint
+}
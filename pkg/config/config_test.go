@@ -128,6 +128,36 @@ func TestInitialize(t *testing.T) {
 	}
 }
 
+// TestInitialize_LegacyTokenPath tests that the old auth.token_path key is
+// still read when auth.tokens_path is not set
+func TestInitialize_LegacyTokenPath(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tempDir)
+	configDir := filepath.Join(tempDir, "gcal-cli")
+	os.MkdirAll(configDir, 0700)
+	configFile := filepath.Join(configDir, "config.yaml")
+
+	viper.Reset()
+	defer viper.Reset()
+
+	os.WriteFile(configFile, []byte("auth:\n  token_path: /legacy/token.json\n"), 0600)
+	if err := Initialize(""); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	if got := GetString("auth.tokens_path"); got != "/legacy/token.json" {
+		t.Errorf("auth.tokens_path = %s, want the auth.token_path value", got)
+	}
+
+	viper.Reset()
+	os.WriteFile(configFile, []byte("auth:\n  token_path: /legacy/token.json\n  tokens_path: /new/tokens.json\n"), 0600)
+	if err := Initialize(""); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	if got := GetString("auth.tokens_path"); got != "/new/tokens.json" {
+		t.Errorf("auth.tokens_path = %s, want /new/tokens.json", got)
+	}
+}
+
 func TestSetDefaults(t *testing.T) {
 	viper.Reset()
 	setDefaults()
@@ -157,6 +187,11 @@ func TestSetDefaults(t *testing.T) {
 			expected: true,
 			checkFn:  func(k string) interface{} { return viper.GetBool(k) },
 		},
+		{
+			key:      "output.errors_to_stderr",
+			expected: false,
+			checkFn:  func(k string) interface{} { return viper.GetBool(k) },
+		},
 		{
 			key:      "auth.auto_refresh",
 			expected: true,
@@ -172,11 +207,21 @@ func TestSetDefaults(t *testing.T) {
 			expected: 1000,
 			checkFn:  func(k string) interface{} { return viper.GetInt(k) },
 		},
+		{
+			key:      "api.retry_max_delay_ms",
+			expected: 10000,
+			checkFn:  func(k string) interface{} { return viper.GetInt(k) },
+		},
 		{
 			key:      "api.timeout_seconds",
 			expected: 30,
 			checkFn:  func(k string) interface{} { return viper.GetInt(k) },
 		},
+		{
+			key:      "api.rate_limit_buffer",
+			expected: 0.9,
+			checkFn:  func(k string) interface{} { return viper.GetFloat64(k) },
+		},
 		{
 			key:      "events.default_duration_minutes",
 			expected: 60,
@@ -187,6 +232,71 @@ func TestSetDefaults(t *testing.T) {
 			expected: true,
 			checkFn:  func(k string) interface{} { return viper.GetBool(k) },
 		},
+		{
+			key:      "server.listen",
+			expected: "127.0.0.1:8787",
+			checkFn:  func(k string) interface{} { return viper.GetString(k) },
+		},
+		{
+			key:      "server.shutdown_timeout_seconds",
+			expected: 10,
+			checkFn:  func(k string) interface{} { return viper.GetInt(k) },
+		},
+		{
+			key:      "auth.mode",
+			expected: "oauth",
+			checkFn:  func(k string) interface{} { return viper.GetString(k) },
+		},
+		{
+			key:      "auth.type",
+			expected: "oauth",
+			checkFn:  func(k string) interface{} { return viper.GetString(k) },
+		},
+		{
+			key:      "auth.impersonate",
+			expected: "",
+			checkFn:  func(k string) interface{} { return viper.GetString(k) },
+		},
+		{
+			key:      "auth.token_store",
+			expected: "file",
+			checkFn:  func(k string) interface{} { return viper.GetString(k) },
+		},
+		{
+			key:      "auth.revoke_url",
+			expected: "https://oauth2.googleapis.com/revoke",
+			checkFn:  func(k string) interface{} { return viper.GetString(k) },
+		},
+		{
+			key:      "api.endpoint",
+			expected: "",
+			checkFn:  func(k string) interface{} { return viper.GetString(k) },
+		},
+		{
+			key:      "backend.type",
+			expected: "google",
+			checkFn:  func(k string) interface{} { return viper.GetString(k) },
+		},
+		{
+			key:      "backend.fake_persist",
+			expected: false,
+			checkFn:  func(k string) interface{} { return viper.GetBool(k) },
+		},
+		{
+			key:      "cache.enabled",
+			expected: true,
+			checkFn:  func(k string) interface{} { return viper.GetBool(k) },
+		},
+		{
+			key:      "cache.ttl_seconds",
+			expected: 300,
+			checkFn:  func(k string) interface{} { return viper.GetInt(k) },
+		},
+		{
+			key:      "queue.on_failure",
+			expected: false,
+			checkFn:  func(k string) interface{} { return viper.GetBool(k) },
+		},
 	}
 
 	for _, tt := range tests {
@@ -315,6 +425,22 @@ func TestGetInt(t *testing.T) {
 	}
 }
 
+func TestGetFloat64(t *testing.T) {
+	viper.Reset()
+	viper.Set("test.float", 0.75)
+
+	got := GetFloat64("test.float")
+	if got != 0.75 {
+		t.Errorf("GetFloat64() = %v, want 0.75", got)
+	}
+
+	// Test non-existent key
+	got = GetFloat64("non.existent")
+	if got != 0 {
+		t.Errorf("GetFloat64() for non-existent key = %v, want 0", got)
+	}
+}
+
 func TestGet(t *testing.T) {
 	viper.Reset()
 	viper.Set("test.key", "value")
@@ -363,6 +489,10 @@ func TestDisplayConfig(t *testing.T) {
 		"Authentication:",
 		"API:",
 		"Events:",
+		"Server:",
+		"Backend:",
+		"Cache:",
+		"Queue:",
 		"primary", // default calendar ID
 		"json",    // default format
 	}
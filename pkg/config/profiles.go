@@ -0,0 +1,284 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/btafoya/gcal-cli/pkg/types"
+	"github.com/spf13/viper"
+)
+
+// DefaultProfile names the top-level auth and calendar settings
+const DefaultProfile = "default"
+
+// ProfileConfig holds the settings of a named account profile. Empty fields
+// fall back to the top-level configuration, except TokensPath which defaults
+// to <config dir>/profiles/<name>/tokens.json.
+type ProfileConfig struct {
+	CredentialsPath   string `mapstructure:"credentials_path" json:"credentialsPath,omitempty"`
+	TokensPath        string `mapstructure:"tokens_path" json:"tokensPath,omitempty"`
+	DefaultCalendarID string `mapstructure:"default_calendar_id" json:"defaultCalendarId,omitempty"`
+	DefaultTimezone   string `mapstructure:"default_timezone" json:"defaultTimezone,omitempty"`
+	AuthType          string `mapstructure:"auth_type" json:"authType,omitempty"`
+	Impersonate       string `mapstructure:"impersonate" json:"impersonate,omitempty"`
+}
+
+// profileNamePattern restricts names to what survives viper's lower-casing
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// activeProfile is the profile selected by ApplyProfile
+var activeProfile string
+
+// profileOverrides holds the active profile's settings keyed by the
+// top-level keys they replace. GetString consults it first, so the config
+// file itself is never modified by selecting a profile.
+var profileOverrides map[string]string
+
+// ValidateProfileName checks that name can be used as a profile name
+func ValidateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return types.ErrInvalidInput("profile",
+			fmt.Sprintf("invalid profile name '%s' (use lowercase letters, digits, '-' and '_')", name))
+	}
+	return nil
+}
+
+// ApplyProfile selects the active profile: name if not empty, otherwise
+// GCAL_PROFILE, otherwise the profile key of the config file. Keys listed in
+// explicit were set on the command line and keep their values.
+func ApplyProfile(name string, explicit ...string) error {
+	if name == "" {
+		name = os.Getenv("GCAL_PROFILE")
+	}
+	if name == "" {
+		name = viper.GetString("profile")
+	}
+
+	activeProfile = ""
+	profileOverrides = nil
+	if name == "" || name == DefaultProfile {
+		return nil
+	}
+
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+
+	profile := ResolveProfile(name)
+	overrides := map[string]string{
+		"auth.credentials_path":        profile.CredentialsPath,
+		"auth.tokens_path":             profile.TokensPath,
+		"calendar.default_calendar_id": profile.DefaultCalendarID,
+		"calendar.default_timezone":    profile.DefaultTimezone,
+		"auth.type":                    profile.AuthType,
+		"auth.impersonate":             profile.Impersonate,
+	}
+	for _, key := range explicit {
+		delete(overrides, key)
+	}
+
+	activeProfile = name
+	profileOverrides = overrides
+	return nil
+}
+
+// ActiveProfile returns the name of the active profile
+func ActiveProfile() string {
+	if activeProfile == "" {
+		return DefaultProfile
+	}
+	return activeProfile
+}
+
+// Profiles returns the configured profiles
+func Profiles() (map[string]ProfileConfig, error) {
+	profiles := make(map[string]ProfileConfig)
+	if err := viper.UnmarshalKey("profiles", &profiles); err != nil {
+		return nil, types.ErrConfigError("error reading profiles").
+			WithWrappedError(err)
+	}
+	return profiles, nil
+}
+
+// ProfileNames returns the configured profile names in sorted order, led by
+// the default profile
+func ProfileNames() ([]string, error) {
+	profiles, err := Profiles()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		if name != DefaultProfile {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return append([]string{DefaultProfile}, names...), nil
+}
+
+// ProfileExists reports whether name is the default or a configured profile
+func ProfileExists(name string) bool {
+	if name == DefaultProfile {
+		return true
+	}
+	profiles, err := Profiles()
+	if err != nil {
+		return false
+	}
+	_, ok := profiles[name]
+	return ok
+}
+
+// ResolveProfile returns the effective settings of a profile with unset
+// fields filled in. The default profile is the top-level configuration.
+func ResolveProfile(name string) ProfileConfig {
+	resolved := ProfileConfig{
+		CredentialsPath:   viper.GetString("auth.credentials_path"),
+		TokensPath:        viper.GetString("auth.tokens_path"),
+		DefaultCalendarID: viper.GetString("calendar.default_calendar_id"),
+		DefaultTimezone:   viper.GetString("calendar.default_timezone"),
+		AuthType:          viper.GetString("auth.type"),
+		Impersonate:       viper.GetString("auth.impersonate"),
+	}
+	if name == "" || name == DefaultProfile {
+		return resolved
+	}
+
+	profiles, _ := Profiles()
+	profile := profiles[name]
+
+	if profile.CredentialsPath != "" {
+		resolved.CredentialsPath = profile.CredentialsPath
+	}
+	if profile.TokensPath != "" {
+		resolved.TokensPath = profile.TokensPath
+	} else {
+		resolved.TokensPath = filepath.Join(ProfileDir(name), "tokens.json")
+	}
+	if profile.DefaultCalendarID != "" {
+		resolved.DefaultCalendarID = profile.DefaultCalendarID
+	}
+	if profile.DefaultTimezone != "" {
+		resolved.DefaultTimezone = profile.DefaultTimezone
+	}
+	if profile.AuthType != "" {
+		resolved.AuthType = profile.AuthType
+	}
+	if profile.Impersonate != "" {
+		resolved.Impersonate = profile.Impersonate
+	}
+
+	return resolved
+}
+
+// ProfileDir returns the directory holding a profile's files
+func ProfileDir(name string) string {
+	configDir, _ := GetConfigDir()
+	return filepath.Join(configDir, "profiles", name)
+}
+
+// SaveProfile stores a profile in the config file
+func SaveProfile(name string, profile ProfileConfig) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	if name == DefaultProfile {
+		return types.ErrInvalidInput("profile",
+			"the default profile uses the top-level auth and calendar settings")
+	}
+
+	viper.Set("profiles."+name, map[string]interface{}{
+		"credentials_path":    profile.CredentialsPath,
+		"tokens_path":         profile.TokensPath,
+		"default_calendar_id": profile.DefaultCalendarID,
+		"default_timezone":    profile.DefaultTimezone,
+		"auth_type":           profile.AuthType,
+		"impersonate":         profile.Impersonate,
+	})
+	return Save()
+}
+
+// UseProfile makes name the active profile in the config file
+func UseProfile(name string) error {
+	if !ProfileExists(name) {
+		return profileNotFound(name)
+	}
+
+	if name == DefaultProfile {
+		name = ""
+	}
+	viper.Set("profile", name)
+	return Save()
+}
+
+// DeleteProfile removes a profile from the config file. If it was the
+// profile selected in the config file, the default profile is selected.
+func DeleteProfile(name string) error {
+	if name == DefaultProfile {
+		return types.ErrInvalidInput("profile", "the default profile cannot be deleted")
+	}
+
+	profiles, err := Profiles()
+	if err != nil {
+		return err
+	}
+	if _, ok := profiles[name]; !ok {
+		return profileNotFound(name)
+	}
+
+	remaining := make(map[string]interface{}, len(profiles))
+	for other := range profiles {
+		if other != name {
+			remaining[other] = viper.GetStringMap("profiles." + other)
+		}
+	}
+
+	settings := viper.AllSettings()
+	settings["profiles"] = remaining
+	if viper.GetString("profile") == name {
+		settings["profile"] = ""
+	}
+
+	// Viper cannot unset keys read from the config file, so the file is
+	// rewritten without the profile and read back in
+	configDir, err := EnsureConfigDir()
+	if err != nil {
+		return err
+	}
+	configPath := filepath.Join(configDir, "config.yaml")
+
+	rewritten := viper.New()
+	if err := rewritten.MergeConfigMap(settings); err != nil {
+		return types.ErrConfigError("error writing config file").
+			WithDetails(configPath).
+			WithWrappedError(err)
+	}
+	if err := rewritten.WriteConfigAs(configPath); err != nil {
+		return types.ErrConfigError("error writing config file").
+			WithDetails(configPath).
+			WithWrappedError(err)
+	}
+
+	viper.SetConfigFile(configPath)
+	if err := viper.ReadInConfig(); err != nil {
+		return types.ErrConfigError("error reading config file").
+			WithDetails(configPath).
+			WithWrappedError(err)
+	}
+	viper.Set("profiles", remaining)
+	viper.Set("profile", settings["profile"])
+
+	return nil
+}
+
+// profileNotFound creates the error for an unknown profile
+func profileNotFound(name string) error {
+	return types.ErrNotFound("profile", name).
+		WithSuggestedAction(fmt.Sprintf("Run 'gcal-cli auth login --profile %s' to create it", name))
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// setupProfiles writes a config file with two profiles and initializes viper from it
+func setupProfiles(t *testing.T) string {
+	t.Helper()
+
+	tempDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tempDir)
+	t.Setenv("GCAL_PROFILE", "")
+
+	configDir := filepath.Join(tempDir, "gcal-cli")
+	os.MkdirAll(configDir, 0700)
+	os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte(`
+calendar:
+  default_calendar_id: primary
+auth:
+  credentials_path: /shared/credentials.json
+profile: work
+profiles:
+  work:
+    default_calendar_id: team@example.com
+    default_timezone: Europe/Berlin
+  personal:
+    credentials_path: /personal/credentials.json
+    tokens_path: /personal/tokens.json
+`), 0600)
+
+	viper.Reset()
+	t.Cleanup(func() {
+		viper.Reset()
+		ApplyProfile(DefaultProfile)
+	})
+	if err := Initialize(""); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	return configDir
+}
+
+// TestApplyProfile tests profile selection and precedence
+func TestApplyProfile(t *testing.T) {
+	configDir := setupProfiles(t)
+
+	// The config file selects the work profile
+	if err := ApplyProfile(""); err != nil {
+		t.Fatalf("ApplyProfile() error = %v", err)
+	}
+	if ActiveProfile() != "work" {
+		t.Fatalf("ActiveProfile() = %s, want work", ActiveProfile())
+	}
+	if got := GetString("calendar.default_calendar_id"); got != "team@example.com" {
+		t.Errorf("calendar ID = %s, want team@example.com", got)
+	}
+	if got := GetString("auth.credentials_path"); got != "/shared/credentials.json" {
+		t.Errorf("credentials path = %s, want the shared top-level path", got)
+	}
+	if got, want := GetString("auth.tokens_path"), filepath.Join(configDir, "profiles", "work", "tokens.json"); got != want {
+		t.Errorf("tokens path = %s, want %s", got, want)
+	}
+
+	// GCAL_PROFILE beats the config file, the flag beats both
+	t.Setenv("GCAL_PROFILE", "personal")
+	ApplyProfile("")
+	if ActiveProfile() != "personal" || GetString("auth.tokens_path") != "/personal/tokens.json" {
+		t.Errorf("Expected personal profile from GCAL_PROFILE, got %s", ActiveProfile())
+	}
+	if got := GetString("calendar.default_calendar_id"); got != "primary" {
+		t.Errorf("calendar ID = %s, want top-level primary", got)
+	}
+
+	ApplyProfile("default")
+	if ActiveProfile() != DefaultProfile || GetString("calendar.default_calendar_id") != "primary" {
+		t.Errorf("Expected top-level settings for the default profile")
+	}
+
+	// Flags set on the command line keep their values
+	ApplyProfile("work", "calendar.default_calendar_id")
+	if got := GetString("calendar.default_calendar_id"); got != "primary" {
+		t.Errorf("calendar ID = %s, want the explicit value", got)
+	}
+	if got := GetString("calendar.default_timezone"); got != "Europe/Berlin" {
+		t.Errorf("timezone = %s, want Europe/Berlin", got)
+	}
+
+	if err := ApplyProfile("Work Account"); err == nil {
+		t.Error("Expected error for invalid profile name")
+	}
+}
+
+// TestProfileLifecycle tests creating, selecting and deleting profiles
+func TestProfileLifecycle(t *testing.T) {
+	configDir := setupProfiles(t)
+
+	names, err := ProfileNames()
+	if err != nil {
+		t.Fatalf("ProfileNames() error = %v", err)
+	}
+	if len(names) != 3 || names[0] != DefaultProfile || names[1] != "personal" || names[2] != "work" {
+		t.Errorf("ProfileNames() = %v", names)
+	}
+
+	if err := SaveProfile("ci", ProfileConfig{DefaultCalendarID: "ci@example.com"}); err != nil {
+		t.Fatalf("SaveProfile() error = %v", err)
+	}
+	if err := SaveProfile(DefaultProfile, ProfileConfig{}); err == nil {
+		t.Error("Expected error when saving the default profile")
+	}
+	if err := UseProfile("ci"); err != nil {
+		t.Fatalf("UseProfile() error = %v", err)
+	}
+	if err := UseProfile("missing"); err == nil {
+		t.Error("Expected error for unknown profile")
+	}
+
+	// Changes survive a reload
+	viper.Reset()
+	Initialize("")
+	ApplyProfile("")
+	if ActiveProfile() != "ci" || GetString("calendar.default_calendar_id") != "ci@example.com" {
+		t.Errorf("Expected saved ci profile to be active, got %s", ActiveProfile())
+	}
+
+	if err := DeleteProfile("ci"); err != nil {
+		t.Fatalf("DeleteProfile() error = %v", err)
+	}
+	if err := DeleteProfile(DefaultProfile); err == nil {
+		t.Error("Expected error when deleting the default profile")
+	}
+	if err := DeleteProfile("ci"); err == nil {
+		t.Error("Expected error when deleting a missing profile")
+	}
+
+	viper.Reset()
+	viper.SetConfigFile(filepath.Join(configDir, "config.yaml"))
+	viper.ReadInConfig()
+	if ProfileExists("ci") || !ProfileExists("work") {
+		t.Error("Expected only the deleted profile to be removed")
+	}
+	if viper.GetString("profile") != "" {
+		t.Errorf("Expected deleted active profile to be deselected, got %q", viper.GetString("profile"))
+	}
+}
@@ -23,6 +23,20 @@ const EventsCreateExamples = `Examples:
     --end "2024-01-17T10:00:00-05:00" \
     --attendees "client@example.com,teammate@company.com"
 
+  # Create a meeting with a Google Meet link
+  gcal-cli events create \
+    --title "Design Sync" \
+    --start "2024-01-18T14:00:00" \
+    --end "2024-01-18T14:30:00" \
+    --meet
+
+  # Tag an event so it can be found later (see events list --has-prop)
+  gcal-cli events create \
+    --title "Incident Review" \
+    --start "2024-01-18T16:00:00" \
+    --end "2024-01-18T17:00:00" \
+    --prop source=pager --prop ticket=OPS-1234
+
   # Create all-day event
   gcal-cli events create \
     --title "Conference" \
@@ -50,6 +64,13 @@ const EventsCreateExamples = `Examples:
     --start "2024-01-19T10:00:00" \
     --end "2024-01-19T11:00:00" \
     --format json | jq -r '.data.event.id')
+
+  # Safe to re-run: the same key never creates a second event
+  gcal-cli events create \
+    --title "Invoice review" \
+    --start "2024-01-22T10:00:00" \
+    --end "2024-01-22T10:30:00" \
+    --idempotency-key "invoice-2024-01"
 `
 
 // EventsListExamples provides comprehensive examples for events list command
@@ -77,6 +98,18 @@ const EventsListExamples = `Examples:
     --to "2024-01-20" \
     --order-by updated
 
+  # List events tagged with extended properties (all must match)
+  gcal-cli events list \
+    --from "2024-01-01" \
+    --to "2024-01-31" \
+    --has-prop source=pager --has-prop ticket=OPS-1234
+
+  # List only out-of-office and focus time events
+  gcal-cli events list \
+    --from "2024-01-01" \
+    --to "2024-01-31" \
+    --event-type outOfOffice,focusTime
+
   # LLM Agent Usage: Parse event list
   gcal-cli events list \
     --from "2024-01-15" \
@@ -131,6 +164,15 @@ const EventsUpdateExamples = `Examples:
   gcal-cli events update abc123xyz \
     --attendees "new@example.com,another@example.com"
 
+  # Remove the location and description
+  gcal-cli events update abc123xyz --clear location,description
+
+  # Add a Google Meet link to an existing event
+  gcal-cli events update abc123xyz --meet
+
+  # Change one tag; the event's other properties are kept
+  gcal-cli events update abc123xyz --prop status=resolved
+
   # Update multiple fields at once
   gcal-cli events update abc123xyz \
     --title "Revised Meeting" \
@@ -138,12 +180,88 @@ const EventsUpdateExamples = `Examples:
     --end "2024-01-15T15:00:00" \
     --location "Conference Room C"
 
+  # Update only if nobody changed the event since it was read
+  ETAG=$(gcal-cli events get abc123xyz | jq -r '.data.event.etag')
+  gcal-cli events update abc123xyz --title "Revised" --if-match "$ETAG"
+
+  # Overwrite whatever version is current
+  gcal-cli events update abc123xyz --title "Revised" --force
+
   # LLM Agent Usage: Conditional update
   if [ "$STATUS" = "tentative" ]; then
     gcal-cli events update abc123xyz --title "CONFIRMED: $TITLE"
   fi
 `
 
+// EventsMoveExamples provides examples for events move command
+const EventsMoveExamples = `Examples:
+  # Move an event from the primary calendar to a team calendar
+  gcal-cli events move abc123xyz --to team@example.com
+
+  # Move an event between two shared calendars
+  gcal-cli events move abc123xyz \
+    --calendar-id team@example.com \
+    --to archive@example.com
+`
+
+// EventsCopyExamples provides examples for events copy command
+const EventsCopyExamples = `Examples:
+  # Copy an event to another calendar
+  gcal-cli events copy abc123xyz --to family@example.com
+
+  # Copy an event to several calendars
+  gcal-cli events copy abc123xyz --to team@example.com,family@example.com
+
+  # LLM Agent Usage: Get the ID of each copy
+  gcal-cli events copy abc123xyz --to team@example.com | \
+    jq -r '.data.events | to_entries[] | "\(.key) \(.value.id)"'
+`
+
+// EventsDuplicateExamples provides examples for events duplicate command
+const EventsDuplicateExamples = `Examples:
+  # Repeat a meeting next week at the same time
+  gcal-cli events duplicate abc123xyz --start "2024-01-22T10:00:00"
+
+  # Duplicate an all-day event to another day
+  gcal-cli events duplicate abc123xyz --start "2024-02-01"
+`
+
+// EventsQuickAddExamples provides examples for events quick-add command
+const EventsQuickAddExamples = `Examples:
+  # Let Google parse the sentence
+  gcal-cli events quick-add "Lunch with Sam tomorrow noon at Cafe Rio"
+
+  # Parse locally, with a duration and attendees
+  gcal-cli events quick-add --local "Design review with alice@example.com next monday at 3pm for 90 minutes"
+
+  # A day without a time creates an all-day event
+  gcal-cli events quick-add --local "Offsite on friday at Lake House"
+`
+
+// EventsRespondExamples provides examples for events respond command
+const EventsRespondExamples = `Examples:
+  # Accept an invitation
+  gcal-cli events respond abc123xyz accepted
+
+  # Decline with a note to the organizer
+  gcal-cli events respond abc123xyz declined --comment "Out that week"
+
+  # Accept every instance of a recurring meeting from one of its instances
+  gcal-cli events respond abc123xyz_20240115T150000Z accepted --scope all
+`
+
+// EventsInvitesExamples provides examples for events invites command
+const EventsInvitesExamples = `Examples:
+  # Invitations you have not answered in the next 30 days
+  gcal-cli events invites
+
+  # Invitations in a specific range from one organizer
+  gcal-cli events invites --from 2024-01-01 --to 2024-01-31 --organizer boss@example.com
+
+  # Accept all pending standups, once per recurring series
+  gcal-cli events invites --query standup --respond accepted --scope all
+`
+
 // EventsDeleteExamples provides comprehensive examples for events delete command
 const EventsDeleteExamples = `Examples:
   # Delete event with confirmation prompt
@@ -201,6 +319,28 @@ const AuthLoginExamples = `Examples:
   # Authenticate with custom credentials file
   gcal-cli auth login --credentials /path/to/credentials.json
 
+  # Log in to a second account as the "work" profile
+  gcal-cli auth login --profile work
+
+  # Request read-only access (events adds event writes, full is the default)
+  gcal-cli auth login --scopes readonly
+
+  # Use a fixed callback port, e.g. one registered as the redirect URI
+  gcal-cli auth login --port 9004
+
+  # Print the authorization URL instead of opening a browser
+  gcal-cli auth login --no-browser
+
+  # Log in over SSH or in a container (no local browser needed)
+  gcal-cli auth login --device
+
+  # Log in from the Docker image, keeping the token in a volume
+  docker run -it -v gcal-config:/root/.config/gcal-cli gcal-cli auth login --device
+
+  # Verify a service account key acting for a Workspace user
+  GCAL_AUTH_TYPE=service_account gcal-cli auth login \
+    --impersonate alice@example.com
+
   # LLM Agent Usage: Check authentication success
   RESULT=$(gcal-cli auth login --format json)
   if [ "$(echo $RESULT | jq -r '.success')" = "true" ]; then
@@ -208,6 +348,19 @@ const AuthLoginExamples = `Examples:
   fi
 `
 
+// AuthMigrateTokensExamples provides examples for auth migrate-tokens command
+const AuthMigrateTokensExamples = `Examples:
+  # Encrypt the token of the active profile (prompts for a passphrase)
+  gcal-cli auth migrate-tokens
+
+  # Encrypt the tokens of every profile non-interactively
+  GCAL_TOKEN_PASSPHRASE="$(cat ~/.gcal-passphrase)" gcal-cli auth migrate-tokens --all
+
+  # Later commands need the same passphrase to read the token
+  export GCAL_TOKEN_PASSPHRASE="$(cat ~/.gcal-passphrase)"
+  gcal-cli events list
+`
+
 // AuthStatusExamples provides comprehensive examples for auth status command
 const AuthStatusExamples = `Examples:
   # Check authentication status
@@ -216,6 +369,9 @@ const AuthStatusExamples = `Examples:
   # Check with JSON output
   gcal-cli auth status --format json
 
+  # LLM Agent Usage: Show who a service account is acting as
+  gcal-cli auth status --impersonate alice@example.com | jq -r '.data.identity'
+
   # LLM Agent Usage: Verify authentication before operations
   IS_AUTH=$(gcal-cli auth status --format json | jq -r '.data.authenticated')
   if [ "$IS_AUTH" != "true" ]; then
@@ -224,6 +380,14 @@ const AuthStatusExamples = `Examples:
 
   # LLM Agent Usage: Get token expiry
   EXPIRY=$(gcal-cli auth status --format json | jq -r '.data.tokenExpiry')
+
+  # LLM Agent Usage: List profiles that need a login
+  gcal-cli auth status --live --format json | \
+    jq -r '.data.profiles[] | select(.authenticated | not) | .name'
+
+  # LLM Agent Usage: List profiles without a stored token, offline
+  gcal-cli auth status --format json | \
+    jq -r '.data.profiles[] | select(.tokenPresent == false) | .name'
 `
 
 // AuthLogoutExamples provides comprehensive examples for auth logout command
@@ -231,10 +395,126 @@ const AuthLogoutExamples = `Examples:
   # Logout and clear tokens
   gcal-cli auth logout
 
+  # Revoke the grant at Google, then clear tokens
+  gcal-cli auth logout --revoke
+
   # LLM Agent Usage: Logout with confirmation
   gcal-cli auth logout --format json | jq '.data.message'
 `
 
+// ServeExamples provides comprehensive examples for serve command
+const ServeExamples = `Examples:
+  # Set a bearer token and start the server on the default address
+  gcal-cli config set server.auth_token "$(openssl rand -hex 32)"
+  gcal-cli serve
+
+  # Listen on a custom address
+  gcal-cli serve --listen 127.0.0.1:9000
+
+  # Serve an offline fake calendar seeded from JSON
+  gcal-cli serve --backend fake --fake-seed demo.json
+
+  # List events over HTTP
+  curl -H "Authorization: Bearer $TOKEN" \
+    "http://127.0.0.1:8787/events?from=2024-01-15&to=2024-01-20"
+
+  # Create an event over HTTP
+  curl -X POST -H "Authorization: Bearer $TOKEN" \
+    -d '{"summary": "Sync", "start": "2024-01-15T10:00:00Z", "end": "2024-01-15T10:30:00Z"}' \
+    http://127.0.0.1:8787/events
+
+  # Query free/busy for a calendar
+  curl -X POST -H "Authorization: Bearer $TOKEN" \
+    -d '{"timeMin": "2024-01-15T09:00:00Z", "timeMax": "2024-01-15T17:00:00Z"}' \
+    "http://127.0.0.1:8787/freebusy?calendar_id=primary"
+`
+
+// CacheStatusExamples provides comprehensive examples for cache status command
+const CacheStatusExamples = `Examples:
+  # Show cache statistics
+  gcal-cli cache status
+
+  # LLM Agent Usage: Check whether cached data is stale
+  gcal-cli cache status --format json | jq '.data.status.staleEntries'
+`
+
+// CacheClearExamples provides comprehensive examples for cache clear command
+const CacheClearExamples = `Examples:
+  # Remove all cached entries
+  gcal-cli cache clear
+
+  # Remove cached entries for one calendar
+  gcal-cli cache clear --calendar team@example.com
+
+  # Read from the cache without network access
+  gcal-cli events list --from 2024-01-15 --to 2024-01-20 --offline
+`
+
+// QueueListExamples provides comprehensive examples for queue list command
+const QueueListExamples = `Examples:
+  # Queue writes that fail while the network is down
+  gcal-cli events create --title "Standup" \
+    --start "2024-01-15T09:00:00Z" --end "2024-01-15T09:15:00Z" \
+    --queue-on-failure
+
+  # List queued writes
+  gcal-cli queue list
+
+  # LLM Agent Usage: Count pending writes
+  gcal-cli queue list --format json | jq '.data.count'
+`
+
+// QueueReplayExamples provides comprehensive examples for queue replay command
+const QueueReplayExamples = `Examples:
+  # Replay queued writes in order
+  gcal-cli queue replay
+
+  # Replay even if events changed since the writes were queued
+  gcal-cli queue replay --force
+
+  # LLM Agent Usage: Show writes that conflicted
+  gcal-cli queue replay --format json | \
+    jq '.data.results[] | select(.error.code == "CONFLICT")'
+`
+
+// QueueDropExamples provides comprehensive examples for queue drop command
+const QueueDropExamples = `Examples:
+  # Drop specific queued writes
+  gcal-cli queue drop q3 q4
+
+  # Drop every queued write
+  gcal-cli queue drop --all
+`
+
+// ProfilesListExamples provides comprehensive examples for profiles list command
+const ProfilesListExamples = `Examples:
+  # List profiles and their settings
+  gcal-cli profiles list
+
+  # Run a single command with another profile
+  gcal-cli events list --from 2024-01-15 --to 2024-01-20 --profile work
+  GCAL_PROFILE=work gcal-cli calendars list
+`
+
+// ProfilesUseExamples provides comprehensive examples for profiles use command
+const ProfilesUseExamples = `Examples:
+  # Use the work profile by default
+  gcal-cli profiles use work
+
+  # Go back to the top-level settings
+  gcal-cli profiles use default
+
+  # Give a profile its own default calendar and timezone
+  gcal-cli config set profiles.work.default_calendar_id team@example.com
+  gcal-cli config set profiles.work.default_timezone Europe/Berlin
+`
+
+// ProfilesDeleteExamples provides comprehensive examples for profiles delete command
+const ProfilesDeleteExamples = `Examples:
+  # Delete a profile and its stored token
+  gcal-cli profiles delete work
+`
+
 // ConfigShowExamples provides comprehensive examples for config show command
 const ConfigShowExamples = `Examples:
   # Show current configuration
@@ -309,3 +589,50 @@ create_event_if_not_exists() {
   fi
 }
 `
+
+// EventsOutOfOfficeExamples provides examples for the events ooo command
+const EventsOutOfOfficeExamples = `Examples:
+  # Out of office Monday to Friday, declining new invitations
+  gcal-cli events ooo --from 2024-01-15 --to 2024-01-19
+
+  # Also decline meetings already accepted, with a note
+  gcal-cli events ooo \
+    --from 2024-01-15 --to 2024-01-19 \
+    --decline-mode declineAllConflictingInvitations \
+    --decline-message "On vacation, back on the 22nd"
+
+  # Out for an afternoon
+  gcal-cli events ooo --from "2024-01-15 13:00" --to "2024-01-15 17:00" --title "Dentist"
+
+  # LLM Agent Usage: Get the event ID
+  gcal-cli events ooo --from 2024-01-15 --to 2024-01-19 | jq -r '.data.event.id'`
+
+// EventsFocusExamples provides examples for the events focus command
+const EventsFocusExamples = `Examples:
+  # Two hours of focus time
+  gcal-cli events focus --start "2024-01-15 09:00" --end "2024-01-15 11:00"
+
+  # Set Chat to do not disturb for the block
+  gcal-cli events focus \
+    --start "2024-01-15 09:00" --end "2024-01-15 11:00" \
+    --title "Deep work" \
+    --chat-status doNotDisturb
+
+  # Focus time that never declines invitations
+  gcal-cli events focus --start "2024-01-15 14:00" --end "2024-01-15 15:00" --decline-mode declineNone`
+
+// EventsWorkingLocationExamples provides examples for the events working-location command
+const EventsWorkingLocationExamples = `Examples:
+  # Working from home today
+  gcal-cli events working-location --home
+
+  # In the office on a given day, at a desk
+  gcal-cli events working-location --office --label "HQ" --building-id hq-1 --desk-id 4-12 --date 2024-01-16
+
+  # Somewhere else for the morning
+  gcal-cli events working-location --custom --label "Client site" \
+    --start "2024-01-17 09:00" --end "2024-01-17 12:00"
+
+  # LLM Agent Usage: Where am I working this week?
+  gcal-cli events list --from 2024-01-15 --to 2024-01-20 --event-type workingLocation | \
+    jq '.data.events[] | {date: .start.date, location: .workingLocation}'`
@@ -0,0 +1,67 @@
+// Package filelock provides advisory locks on lock files, so gcal-cli
+// processes running at the same time can take turns changing shared files
+// such as the token and the write queue.
+package filelock
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/btafoya/gcal-cli/pkg/types"
+)
+
+// Timeout bounds how long a process waits for another one to release a lock
+const Timeout = 30 * time.Second
+
+// pollInterval is how often a held lock is retried
+const pollInterval = 50 * time.Millisecond
+
+// errLocked is returned by tryLock when another process holds the lock
+var errLocked = errors.New("file is locked")
+
+// Lock is an exclusive advisory lock on a lock file
+type Lock struct {
+	file *os.File
+}
+
+// Acquire takes an exclusive advisory lock on path, creating it if needed,
+// and waits up to Timeout for other holders to release it
+func Acquire(path string) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, lockError(path, err)
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, lockError(path, err)
+	}
+
+	deadline := time.Now().Add(Timeout)
+	for {
+		err := tryLock(file)
+		if err == nil {
+			return &Lock{file: file}, nil
+		}
+		if err != errLocked || time.Now().After(deadline) {
+			file.Close()
+			return nil, lockError(path, err).
+				WithSuggestedAction("Another gcal-cli process may be stuck; remove the lock file if none is running")
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// Unlock releases the lock
+func (l *Lock) Unlock() error {
+	defer l.file.Close()
+	return unlock(l.file)
+}
+
+// lockError creates the error for a lock that could not be taken
+func lockError(path string, err error) *types.AppError {
+	return types.NewAppError(types.ErrCodeFileError, "file operation failed", true).
+		WithDetails("could not lock " + path).
+		WithWrappedError(err)
+}
@@ -0,0 +1,44 @@
+package filelock
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestAcquire tests that a second holder waits until the lock is released
+func TestAcquire(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "file.lock")
+
+	first, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	acquired := make(chan *Lock)
+	go func() {
+		second, err := Acquire(path)
+		if err != nil {
+			t.Errorf("second Acquire() error = %v", err)
+		}
+		acquired <- second
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("Expected the second Acquire() to wait for the first lock")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	if err := first.Unlock(); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+	select {
+	case second := <-acquired:
+		if second != nil {
+			second.Unlock()
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the second Acquire() to succeed after Unlock()")
+	}
+}
@@ -2,6 +2,7 @@ package output
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -57,43 +58,122 @@ func (f *TextFormatter) formatEventData(builder *strings.Builder, data *types.Ev
 	}
 
 	if data.Event != nil {
-		builder.WriteString("Event Details:\n")
-		builder.WriteString(fmt.Sprintf("  ID:       %s\n", data.Event.ID))
-		builder.WriteString(fmt.Sprintf("  Title:    %s\n", data.Event.Summary))
-		if data.Event.Description != "" {
-			builder.WriteString(fmt.Sprintf("  Description: %s\n", data.Event.Description))
-		}
-		builder.WriteString(fmt.Sprintf("  Start:    %s\n", f.formatEventTime(&data.Event.Start)))
-		builder.WriteString(fmt.Sprintf("  End:      %s\n", f.formatEventTime(&data.Event.End)))
-		builder.WriteString(fmt.Sprintf("  Status:   %s\n", data.Event.Status))
-		if data.Event.Location != "" {
-			builder.WriteString(fmt.Sprintf("  Location: %s\n", data.Event.Location))
+		f.formatEvent(builder, data.Event)
+	} else if data.EventID != "" {
+		builder.WriteString(fmt.Sprintf("Event ID: %s\n", data.EventID))
+	}
+}
+
+func (f *TextFormatter) formatEvent(builder *strings.Builder, event *types.Event) {
+	builder.WriteString("Event Details:\n")
+	builder.WriteString(fmt.Sprintf("  ID:       %s\n", event.ID))
+	builder.WriteString(fmt.Sprintf("  Title:    %s\n", event.Summary))
+	if event.Description != "" {
+		builder.WriteString(fmt.Sprintf("  Description: %s\n", event.Description))
+	}
+	builder.WriteString(fmt.Sprintf("  Start:    %s\n", f.formatEventTime(&event.Start)))
+	builder.WriteString(fmt.Sprintf("  End:      %s\n", f.formatEventTime(&event.End)))
+	builder.WriteString(fmt.Sprintf("  Status:   %s\n", event.Status))
+	if event.Location != "" {
+		builder.WriteString(fmt.Sprintf("  Location: %s\n", event.Location))
+	}
+	if event.EventType != "" && event.EventType != "default" {
+		builder.WriteString(fmt.Sprintf("  Type:     %s\n", event.EventType))
+	}
+	if location := event.WorkingLocation; location != nil {
+		where := location.Type
+		if location.Label != "" {
+			where += " (" + location.Label + ")"
 		}
+		builder.WriteString(fmt.Sprintf("  Working:  %s\n", where))
+	}
+
+	if event.Conference != nil {
+		f.formatConference(builder, event.Conference)
+	}
 
-		if len(data.Event.Attendees) > 0 {
-			builder.WriteString("\nAttendees:\n")
-			for _, attendee := range data.Event.Attendees {
-				status := ""
-				switch attendee.ResponseStatus {
-				case "accepted":
-					status = "✓"
-				case "declined":
-					status = "✗"
-				case "tentative":
-					status = "?"
-				default:
-					status = "○"
-				}
-				builder.WriteString(fmt.Sprintf("  %s %s (%s)\n", status, attendee.Email, attendee.ResponseStatus))
+	if len(event.Attendees) > 0 {
+		builder.WriteString("\nAttendees:\n")
+		for _, attendee := range event.Attendees {
+			status := ""
+			switch attendee.ResponseStatus {
+			case "accepted":
+				status = "✓"
+			case "declined":
+				status = "✗"
+			case "tentative":
+				status = "?"
+			default:
+				status = "○"
 			}
+			builder.WriteString(fmt.Sprintf("  %s %s (%s)\n", status, attendee.Email, attendee.ResponseStatus))
+		}
+	}
+
+	if event.ExtendedProperties != nil {
+		f.formatProperties(builder, "Properties", event.ExtendedProperties.Private)
+		f.formatProperties(builder, "Shared Properties", event.ExtendedProperties.Shared)
+	}
+
+	if event.HTMLLink != "" {
+		builder.WriteString(fmt.Sprintf("\nLink: %s\n", event.HTMLLink))
+	}
+}
+
+func (f *TextFormatter) formatProperties(builder *strings.Builder, title string, properties map[string]string) {
+	if len(properties) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	builder.WriteString(fmt.Sprintf("\n%s:\n", title))
+	for _, key := range keys {
+		builder.WriteString(fmt.Sprintf("  %s=%s\n", key, properties[key]))
+	}
+}
+
+func (f *TextFormatter) formatConference(builder *strings.Builder, conference *types.Conference) {
+	solution := conference.Solution
+	if solution == "" {
+		solution = "Conference"
+	}
+	builder.WriteString(fmt.Sprintf("\n%s:\n", solution))
+
+	if len(conference.EntryPoints) == 0 && conference.Status != "" {
+		builder.WriteString(fmt.Sprintf("  Status:   %s\n", conference.Status))
+	}
+	for _, entry := range conference.EntryPoints {
+		label := "Join:"
+		if entry.Type != "" {
+			label = strings.ToUpper(entry.Type[:1]) + entry.Type[1:] + ":"
+		}
+		line := fmt.Sprintf("  %-9s %s", label, entry.URI)
+		if entry.Type == "phone" && entry.Label != "" {
+			line = fmt.Sprintf("  %-9s %s", label, entry.Label)
 		}
+		if entry.PIN != "" {
+			line += fmt.Sprintf(" (PIN: %s)", entry.PIN)
+		}
+		builder.WriteString(line + "\n")
+	}
+}
 
-		if data.Event.HTMLLink != "" {
-			builder.WriteString(fmt.Sprintf("\nLink: %s\n", data.Event.HTMLLink))
+// videoLink returns the URI of a conference's video entry point
+func videoLink(conference *types.Conference) string {
+	if conference == nil {
+		return ""
+	}
+	for _, entry := range conference.EntryPoints {
+		if entry.Type == "video" {
+			return entry.URI
 		}
-	} else if data.EventID != "" {
-		builder.WriteString(fmt.Sprintf("Event ID: %s\n", data.EventID))
 	}
+	return ""
 }
 
 func (f *TextFormatter) formatEventListData(builder *strings.Builder, data *types.EventListData) {
@@ -107,6 +187,9 @@ func (f *TextFormatter) formatEventListData(builder *strings.Builder, data *type
 		if event.Location != "" {
 			builder.WriteString(fmt.Sprintf("    Location: %s\n", event.Location))
 		}
+		if link := videoLink(event.Conference); link != "" {
+			builder.WriteString(fmt.Sprintf("    Video: %s\n", link))
+		}
 		builder.WriteString("\n")
 	}
 
@@ -129,8 +212,28 @@ func (f *TextFormatter) formatAuthData(builder *strings.Builder, data *types.Aut
 }
 
 func (f *TextFormatter) formatGenericData(builder *strings.Builder, data map[string]interface{}) {
-	for key, value := range data {
-		builder.WriteString(fmt.Sprintf("%s: %v\n", key, value))
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// Events are shown in full, after the other values
+	var events []*types.Event
+	for _, key := range keys {
+		switch value := data[key].(type) {
+		case *types.Event:
+			events = append(events, value)
+		case []*types.Event:
+			events = append(events, value...)
+		default:
+			builder.WriteString(fmt.Sprintf("%s: %v\n", key, value))
+		}
+	}
+
+	for _, event := range events {
+		builder.WriteString("\n")
+		f.formatEvent(builder, event)
 	}
 }
 
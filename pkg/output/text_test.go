@@ -126,3 +126,37 @@ func TestTextFormatter_Format_EventList(t *testing.T) {
 		t.Error("Output should contain second event")
 	}
 }
+
+func TestTextFormatter_Format_Conference(t *testing.T) {
+	formatter := &TextFormatter{}
+
+	response := types.SuccessResponse("create", map[string]interface{}{
+		"message": "Event created successfully",
+		"event": &types.Event{
+			ID:      "test123",
+			Summary: "Design Sync",
+			Start:   types.EventTime{DateTime: "2024-01-18T14:00:00Z"},
+			End:     types.EventTime{DateTime: "2024-01-18T14:30:00Z"},
+			Conference: &types.Conference{
+				ID:       "abc-defg-hij",
+				Solution: "Google Meet",
+				Status:   "success",
+				EntryPoints: []types.EntryPoint{
+					{Type: "video", URI: "https://meet.google.com/abc-defg-hij"},
+					{Type: "phone", URI: "tel:+1-555-0100", Label: "+1 555-0100", PIN: "123456789"},
+				},
+			},
+		},
+	})
+
+	output, err := formatter.Format(response)
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	for _, want := range []string{"Design Sync", "Google Meet", "https://meet.google.com/abc-defg-hij", "+1 555-0100 (PIN: 123456789)"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Output should contain %q:\n%s", want, output)
+		}
+	}
+}
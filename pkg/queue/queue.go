@@ -0,0 +1,286 @@
+// Package queue keeps a durable journal of calendar writes that failed
+// because the API could not be reached, and replays them in order once the
+// network is back.
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/btafoya/gcal-cli/pkg/calendar"
+	"github.com/btafoya/gcal-cli/pkg/filelock"
+	"github.com/btafoya/gcal-cli/pkg/types"
+)
+
+// Entry is a queued write
+type Entry struct {
+	ID       string    `json:"id"`
+	QueuedAt time.Time `json:"queuedAt"`
+	Attempts int       `json:"attempts"`
+	// LastError is the outcome of the most recent failed replay
+	LastError *types.AppError `json:"lastError,omitempty"`
+	calendar.PendingWrite
+}
+
+// journal is the on-disk format of the queue
+type journal struct {
+	NextID  int      `json:"nextId"`
+	Entries []*Entry `json:"entries"`
+}
+
+// Journal is a file-backed write queue. It implements calendar.WriteQueue.
+// Changes hold a lock file next to the journal from load to save, so
+// processes queueing at the same time never drop each other's entries.
+type Journal struct {
+	path string
+	mu   sync.Mutex
+	now  func() time.Time
+}
+
+// Open returns the journal stored at path. The file is created on first write.
+func Open(path string) *Journal {
+	return &Journal{
+		path: path,
+		now:  time.Now,
+	}
+}
+
+// Path returns the journal file path
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// Enqueue appends a write to the journal and returns its queue ID
+func (j *Journal) Enqueue(write calendar.PendingWrite) (string, error) {
+	unlock, err := j.lock()
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	data, err := j.load()
+	if err != nil {
+		return "", err
+	}
+
+	data.NextID++
+	entry := &Entry{
+		ID:           fmt.Sprintf("q%d", data.NextID),
+		QueuedAt:     j.now().UTC(),
+		PendingWrite: write,
+	}
+	data.Entries = append(data.Entries, entry)
+
+	if err := j.save(data); err != nil {
+		return "", err
+	}
+
+	return entry.ID, nil
+}
+
+// List returns the queued writes in the order they will be replayed
+func (j *Journal) List() ([]*Entry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	data, err := j.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return data.Entries, nil
+}
+
+// Drop removes the given entries, or every entry when no IDs are given, and
+// returns how many were removed
+func (j *Journal) Drop(ids ...string) (int, error) {
+	unlock, err := j.lock()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	data, err := j.load()
+	if err != nil {
+		return 0, err
+	}
+
+	drop := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		drop[id] = true
+	}
+
+	kept := data.Entries[:0]
+	for _, entry := range data.Entries {
+		if len(ids) > 0 && !drop[entry.ID] {
+			kept = append(kept, entry)
+			continue
+		}
+		delete(drop, entry.ID)
+	}
+
+	for id := range drop {
+		return 0, types.ErrNotFound("queued write", id)
+	}
+
+	removed := len(data.Entries) - len(kept)
+	data.Entries = kept
+	if err := j.save(data); err != nil {
+		return 0, err
+	}
+
+	return removed, nil
+}
+
+// Replay applies queued writes in order through client. Writes that succeed
+// are removed from the journal; failed and conflicting writes stay queued
+// with their error. Replay stops at the first network error since the rest
+// would fail the same way. Results are indexed by position in the queue.
+// The journal stays locked throughout, so no write is replayed twice.
+func (j *Journal) Replay(ctx context.Context, client *calendar.Client, force bool) ([]*calendar.BatchResult, error) {
+	unlock, err := j.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	data, err := j.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*calendar.BatchResult
+	kept := make([]*Entry, 0, len(data.Entries))
+
+	for i, entry := range data.Entries {
+		// Once the network is gone, keep the remaining writes untouched
+		if len(results) > 0 && isNetworkFailure(results[len(results)-1]) {
+			kept = append(kept, data.Entries[i:]...)
+			break
+		}
+
+		event, err := client.ReplayWrite(ctx, entry.PendingWrite, force)
+
+		result := &calendar.BatchResult{
+			Index:   i,
+			EventID: entry.EventID,
+		}
+		if err != nil {
+			appErr, ok := err.(*types.AppError)
+			if !ok {
+				appErr = types.NewAppError(types.ErrCodeAPIError, "API operation failed", true).
+					WithDetails(fmt.Sprintf("replay of %s failed", entry.ID)).
+					WithWrappedError(err)
+			}
+			result.Error = appErr
+
+			entry.Attempts++
+			entry.LastError = appErr
+			kept = append(kept, entry)
+		} else {
+			result.Success = true
+			if event != nil {
+				result.EventID = event.ID
+				result.Event = event
+			}
+		}
+		results = append(results, result)
+	}
+
+	data.Entries = kept
+	if err := j.save(data); err != nil {
+		return results, err
+	}
+
+	return results, nil
+}
+
+// isNetworkFailure reports whether a replay failed because the API was unreachable
+func isNetworkFailure(result *calendar.BatchResult) bool {
+	return result.Error != nil && result.Error.Code == types.ErrCodeNetworkError
+}
+
+// lock takes the in-process mutex and the journal's lock file
+func (j *Journal) lock() (unlock func(), err error) {
+	j.mu.Lock()
+	lock, err := filelock.Acquire(j.path + ".lock")
+	if err != nil {
+		j.mu.Unlock()
+		return nil, err
+	}
+	return func() {
+		lock.Unlock()
+		j.mu.Unlock()
+	}, nil
+}
+
+// load reads the journal; a missing file is an empty queue
+func (j *Journal) load() (*journal, error) {
+	raw, err := os.ReadFile(j.path)
+	if os.IsNotExist(err) {
+		return &journal{}, nil
+	}
+	if err != nil {
+		return nil, types.NewAppError(types.ErrCodeFileError, "file operation failed", false).
+			WithDetails("could not read queue journal: " + j.path).
+			WithWrappedError(err)
+	}
+
+	var data journal
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, types.NewAppError(types.ErrCodeInvalidFormat, "invalid queue journal", false).
+			WithDetails(err.Error()).
+			WithWrappedError(err).
+			WithSuggestedAction("Fix or remove " + j.path)
+	}
+
+	return &data, nil
+}
+
+// save writes the journal atomically and syncs it to disk, so a crash never
+// leaves a partially written queue behind
+func (j *Journal) save(data *journal) error {
+	fileError := func(details string, err error) error {
+		return types.NewAppError(types.ErrCodeFileError, "file operation failed", false).
+			WithDetails(details).
+			WithWrappedError(err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(j.path), 0700); err != nil {
+		return fileError("could not create queue directory", err)
+	}
+
+	raw, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fileError("could not encode queue journal", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(j.path), ".queue-*")
+	if err != nil {
+		return fileError("could not write queue journal", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(raw, '\n')); err != nil {
+		tmp.Close()
+		return fileError("could not write queue journal", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fileError("could not write queue journal", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fileError("could not write queue journal", err)
+	}
+
+	if err := os.Rename(tmp.Name(), j.path); err != nil {
+		return fileError("could not write queue journal", err)
+	}
+
+	return nil
+}
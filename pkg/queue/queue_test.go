@@ -0,0 +1,265 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/btafoya/gcal-cli/pkg/cache"
+	"github.com/btafoya/gcal-cli/pkg/calendar"
+	"github.com/btafoya/gcal-cli/pkg/types"
+	gcal "google.golang.org/api/calendar/v3"
+)
+
+// flakyBackend fails event reads and writes with a transport error while down
+type flakyBackend struct {
+	calendar.Backend
+	down bool
+}
+
+func (b *flakyBackend) unreachable() error {
+	return &url.Error{Op: "Post", URL: "https://www.googleapis.com/calendar/v3", Err: errors.New("connection refused")}
+}
+
+func (b *flakyBackend) InsertEvent(ctx context.Context, calendarID string, event *gcal.Event, opts calendar.WriteOptions) (*gcal.Event, error) {
+	if b.down {
+		return nil, b.unreachable()
+	}
+	return b.Backend.InsertEvent(ctx, calendarID, event, opts)
+}
+
+func (b *flakyBackend) GetEvent(ctx context.Context, calendarID, eventID string) (*gcal.Event, error) {
+	if b.down {
+		return nil, b.unreachable()
+	}
+	return b.Backend.GetEvent(ctx, calendarID, eventID)
+}
+
+func (b *flakyBackend) UpdateEvent(ctx context.Context, calendarID, eventID string, event *gcal.Event, opts calendar.WriteOptions) (*gcal.Event, error) {
+	if b.down {
+		return nil, b.unreachable()
+	}
+	return b.Backend.UpdateEvent(ctx, calendarID, eventID, event, opts)
+}
+
+func (b *flakyBackend) PatchEvent(ctx context.Context, calendarID, eventID string, patch *gcal.Event, opts calendar.WriteOptions) (*gcal.Event, error) {
+	if b.down {
+		return nil, b.unreachable()
+	}
+	return b.Backend.PatchEvent(ctx, calendarID, eventID, patch, opts)
+}
+
+func (b *flakyBackend) DeleteEvent(ctx context.Context, calendarID, eventID string) error {
+	if b.down {
+		return b.unreachable()
+	}
+	return b.Backend.DeleteEvent(ctx, calendarID, eventID)
+}
+
+func newQueuedClient(t *testing.T) (*calendar.Client, *flakyBackend, *Journal) {
+	t.Helper()
+
+	backend := &flakyBackend{Backend: calendar.NewFakeBackend()}
+	journal := Open(filepath.Join(t.TempDir(), "queue.json"))
+
+	client := calendar.NewClientWithBackend(backend, "primary")
+	client.RetryDelay = time.Millisecond
+	client.MaxRetries = 0
+	client.Cache = cache.New(t.TempDir(), time.Hour)
+	client.Queue = journal
+
+	return client, backend, journal
+}
+
+func standup(hour int) calendar.CreateEventParams {
+	start := time.Date(2024, 1, 15, hour, 0, 0, 0, time.UTC)
+	return calendar.CreateEventParams{Summary: "Standup", Start: start, End: start.Add(15 * time.Minute)}
+}
+
+func errorCode(err error) string {
+	if appErr, ok := err.(*types.AppError); ok {
+		return appErr.Code
+	}
+	return ""
+}
+
+// TestQueueOnFailure_Replay tests that failed writes are queued and replayed in order
+func TestQueueOnFailure_Replay(t *testing.T) {
+	ctx := context.Background()
+	client, backend, journal := newQueuedClient(t)
+
+	existing, err := client.CreateEvent(ctx, standup(8))
+	if err != nil {
+		t.Fatalf("CreateEvent() error = %v", err)
+	}
+
+	backend.down = true
+	if _, err := client.CreateEvent(ctx, standup(9)); errorCode(err) != types.ErrCodeQueued {
+		t.Fatalf("Expected QUEUED for create, got %v", err)
+	}
+	if err := client.DeleteEvent(ctx, existing.ID); errorCode(err) != types.ErrCodeQueued {
+		t.Fatalf("Expected QUEUED for delete, got %v", err)
+	}
+
+	entries, err := journal.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].Operation != calendar.WriteCreate || entries[1].Operation != calendar.WriteDelete {
+		t.Fatalf("Unexpected queue: %+v", entries)
+	}
+
+	// Still offline: replay stops at the first network error
+	results, err := journal.Replay(ctx, client, false)
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Error == nil || results[0].Error.Code != types.ErrCodeNetworkError {
+		t.Fatalf("Expected a single network failure, got %+v", results)
+	}
+	entries, _ = journal.List()
+	if len(entries) != 2 || entries[0].Attempts != 1 || entries[1].Attempts != 0 {
+		t.Errorf("Expected both writes kept with one attempt recorded, got %+v", entries)
+	}
+
+	backend.down = false
+	results, err = journal.Replay(ctx, client, false)
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if summary := calendar.GetBatchSummary(results); summary["success"] != 2 {
+		t.Fatalf("Expected 2 successful replays, got %+v", results)
+	}
+	if entries, _ := journal.List(); len(entries) != 0 {
+		t.Errorf("Expected empty queue after replay, got %d entries", len(entries))
+	}
+
+	events, err := client.ListEvents(ctx, calendar.ListEventsParams{
+		From: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("ListEvents() error = %v", err)
+	}
+	if len(events) != 1 || events[0].ID == existing.ID {
+		t.Errorf("Expected only the replayed event, got %+v", events)
+	}
+}
+
+// TestReplay_Conflict tests etag conflict detection for queued updates
+func TestReplay_Conflict(t *testing.T) {
+	ctx := context.Background()
+	client, backend, journal := newQueuedClient(t)
+
+	created, err := client.CreateEvent(ctx, standup(9))
+	if err != nil {
+		t.Fatalf("CreateEvent() error = %v", err)
+	}
+	if _, err := client.GetEvent(ctx, created.ID); err != nil {
+		t.Fatalf("GetEvent() error = %v", err)
+	}
+
+	backend.down = true
+	if _, err := client.UpdateEvent(ctx, created.ID, calendar.CreateEventParams{Location: "Room 4B"}); errorCode(err) != types.ErrCodeQueued {
+		t.Fatalf("Expected QUEUED for update, got %v", err)
+	}
+	entries, _ := journal.List()
+	if len(entries) != 1 || entries[0].ETag != created.ETag {
+		t.Fatalf("Expected queued update with etag %s, got %+v", created.ETag, entries)
+	}
+
+	// Someone else edits the event while we are offline
+	backend.down = false
+	event, _ := backend.GetEvent(ctx, "primary", created.ID)
+	event.Summary = "Standup (moved)"
+	backend.UpdateEvent(ctx, "primary", created.ID, event, calendar.WriteOptions{})
+
+	results, err := journal.Replay(ctx, client, false)
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Error == nil || results[0].Error.Code != types.ErrCodeConflict {
+		t.Fatalf("Expected CONFLICT, got %+v", results)
+	}
+	if entries, _ := journal.List(); len(entries) != 1 {
+		t.Fatalf("Expected conflicting write to stay queued, got %d entries", len(entries))
+	}
+
+	results, err = journal.Replay(ctx, client, true)
+	if err != nil || len(results) != 1 || !results[0].Success {
+		t.Fatalf("Expected forced replay to succeed, got %+v, %v", results, err)
+	}
+	if results[0].Event.Location != "Room 4B" || results[0].Event.Summary != "Standup (moved)" {
+		t.Errorf("Expected forced update merged with the current event, got %+v", results[0].Event)
+	}
+}
+
+// TestDrop tests removing queued writes
+func TestDrop(t *testing.T) {
+	journal := Open(filepath.Join(t.TempDir(), "nested", "queue.json"))
+
+	if removed, err := journal.Drop(); err != nil || removed != 0 {
+		t.Errorf("Drop() on empty queue = %d, %v", removed, err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := journal.Enqueue(calendar.PendingWrite{Operation: calendar.WriteDelete, CalendarID: "primary", EventID: "evt"}); err != nil {
+			t.Fatalf("Enqueue() error = %v", err)
+		}
+	}
+
+	if _, err := journal.Drop("q9"); errorCode(err) != types.ErrCodeNotFound {
+		t.Errorf("Expected NOT_FOUND for unknown ID, got %v", err)
+	}
+
+	if removed, err := journal.Drop("q2"); err != nil || removed != 1 {
+		t.Errorf("Drop(q2) = %d, %v", removed, err)
+	}
+	entries, _ := journal.List()
+	if len(entries) != 2 || entries[0].ID != "q1" || entries[1].ID != "q3" {
+		t.Errorf("Unexpected entries after drop: %+v", entries)
+	}
+
+	// IDs are never reused
+	id, _ := journal.Enqueue(calendar.PendingWrite{Operation: calendar.WriteDelete, CalendarID: "primary", EventID: "evt"})
+	if id != "q4" {
+		t.Errorf("Expected new ID q4, got %s", id)
+	}
+
+	if removed, err := journal.Drop(); err != nil || removed != 3 {
+		t.Errorf("Drop() = %d, %v; want 3", removed, err)
+	}
+}
+
+// TestEnqueue_SharedJournal tests that journals opened separately on the
+// same file, as by parallel processes, never drop each other's entries
+func TestEnqueue_SharedJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			journal := Open(path)
+			for n := 0; n < 5; n++ {
+				if _, err := journal.Enqueue(calendar.PendingWrite{Operation: calendar.WriteDelete, CalendarID: "primary", EventID: "evt"}); err != nil {
+					t.Errorf("Enqueue() error = %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	entries, err := Open(path).List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(entries) != 20 {
+		t.Errorf("Expected 20 queued writes, got %d", len(entries))
+	}
+}
@@ -0,0 +1,399 @@
+// Package recorder provides an HTTP transport that records API traffic to
+// cassette files and replays it, so calendar code can be tested
+// deterministically against realistic responses.
+package recorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/btafoya/gcal-cli/pkg/types"
+)
+
+// Mode selects whether a Recorder captures or serves traffic
+type Mode string
+
+const (
+	// ModeRecord sends requests to the real transport and saves them
+	ModeRecord Mode = "record"
+	// ModeReplay serves responses from the cassette without network access
+	ModeReplay Mode = "replay"
+)
+
+// Redacted replaces secrets in recorded traffic
+const Redacted = "REDACTED"
+
+// sensitiveKeys are redacted from headers, query strings and bodies
+var sensitiveKeys = []string{
+	"access_token",
+	"refresh_token",
+	"id_token",
+	"client_secret",
+	"code",
+	"key",
+}
+
+// sensitiveHeaders are redacted from recorded requests and responses
+var sensitiveHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+// Cassette is a recorded sequence of HTTP interactions
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction is a single recorded request and its response
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is the recorded part of an HTTP request
+type Request struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// Response is the recorded part of an HTTP response
+type Response struct {
+	StatusCode int         `json:"statusCode"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Recorder is an http.RoundTripper that records or replays a cassette
+type Recorder struct {
+	mode Mode
+	path string
+	base http.RoundTripper
+
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// New creates a recorder for the cassette at path. In record mode requests
+// go to base (http.DefaultTransport if nil) and the cassette is rewritten
+// after every interaction. In replay mode the cassette must already exist.
+func New(path string, mode Mode, base http.RoundTripper) (*Recorder, error) {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	r := &Recorder{
+		mode:     mode,
+		path:     path,
+		base:     base,
+		cassette: &Cassette{},
+	}
+
+	switch mode {
+	case ModeRecord:
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, types.NewAppError(types.ErrCodeFileError, "file operation failed", false).
+				WithDetails("could not create cassette directory").
+				WithWrappedError(err)
+		}
+	case ModeReplay:
+		cassette, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		r.cassette = cassette
+		r.used = make([]bool, len(cassette.Interactions))
+	default:
+		return nil, types.ErrInvalidInput("mode",
+			fmt.Sprintf("unknown recorder mode '%s' (must be 'record' or 'replay')", mode))
+	}
+
+	return r, nil
+}
+
+// Load reads a cassette from disk
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, types.NewAppError(types.ErrCodeFileError, "file operation failed", false).
+			WithDetails("could not read cassette: " + path).
+			WithWrappedError(err).
+			WithSuggestedAction("Record the cassette first")
+	}
+
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, types.NewAppError(types.ErrCodeInvalidFormat, "invalid cassette", false).
+			WithDetails(err.Error()).
+			WithWrappedError(err)
+	}
+
+	return &cassette, nil
+}
+
+// Client returns an HTTP client that uses the recorder as its transport
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Mode returns the recorder mode
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Cassette returns the interactions recorded or loaded so far
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return &Cassette{Interactions: append([]*Interaction(nil), r.cassette.Interactions...)}
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := Request{
+		Method:  req.Method,
+		URL:     redactURL(req.URL),
+		Headers: redactHeaders(req.Header),
+		Body:    redactBody(body, req.Header.Get("Content-Type")),
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, recorded)
+	}
+
+	return r.record(req, recorded)
+}
+
+// record forwards the request and saves the redacted interaction
+func (r *Recorder) record(req *http.Request, recorded Request) (*http.Response, error) {
+	resp, err := r.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	interaction := &Interaction{
+		Request: recorded,
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Headers:    redactHeaders(resp.Header),
+			Body:       redactBody(respBody, resp.Header.Get("Content-Type")),
+		},
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	if err := r.save(); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// replay serves the first unused interaction that matches the request
+func (r *Recorder) replay(req *http.Request, recorded Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.cassette.Interactions {
+		if r.used[i] || !matches(interaction.Request, recorded) {
+			continue
+		}
+		r.used[i] = true
+
+		headers := interaction.Response.Headers.Clone()
+		if headers == nil {
+			headers = make(http.Header)
+		}
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        headers,
+			Body:          io.NopCloser(strings.NewReader(interaction.Response.Body)),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, types.ErrNotFound("recorded interaction", recorded.Method+" "+recorded.URL).
+		WithDetails(fmt.Sprintf("no unused interaction in %s matches %s %s", r.path, recorded.Method, recorded.URL)).
+		WithSuggestedAction("Re-record the cassette")
+}
+
+// save writes the cassette to disk; callers must hold the lock
+func (r *Recorder) save() error {
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return types.NewAppError(types.ErrCodeFileError, "file operation failed", false).
+			WithDetails("could not marshal cassette").
+			WithWrappedError(err)
+	}
+
+	if err := os.WriteFile(r.path, append(data, '\n'), 0644); err != nil {
+		return types.NewAppError(types.ErrCodeFileError, "file operation failed", false).
+			WithDetails("could not write cassette: " + r.path).
+			WithWrappedError(err)
+	}
+
+	return nil
+}
+
+// matches compares requests by method, URL and normalized body
+func matches(recorded, incoming Request) bool {
+	return recorded.Method == incoming.Method &&
+		recorded.URL == incoming.URL &&
+		normalizeBody(recorded.Body) == normalizeBody(incoming.Body)
+}
+
+// normalizeBody re-encodes JSON bodies so formatting differences don't matter
+func normalizeBody(body string) string {
+	var value interface{}
+	if err := json.Unmarshal([]byte(body), &value); err != nil {
+		return body
+	}
+
+	normalized, _ := json.Marshal(value)
+	return string(normalized)
+}
+
+// readBody reads and restores the request body
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
+
+// redactURL removes secrets from query parameters
+func redactURL(u *url.URL) string {
+	redacted := *u
+	query := redacted.Query()
+	for _, key := range sensitiveKeys {
+		if query.Has(key) {
+			query.Set(key, Redacted)
+		}
+	}
+	redacted.RawQuery = query.Encode()
+
+	return redacted.String()
+}
+
+// redactHeaders copies headers with credentials removed
+func redactHeaders(headers http.Header) http.Header {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	redacted := headers.Clone()
+	for _, name := range sensitiveHeaders {
+		if redacted.Get(name) != "" {
+			redacted.Set(name, Redacted)
+		}
+	}
+
+	return redacted
+}
+
+// redactBody removes secrets from JSON and form-encoded bodies
+func redactBody(body []byte, contentType string) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		values, err := url.ParseQuery(string(body))
+		if err == nil {
+			for _, key := range sensitiveKeys {
+				if values.Has(key) {
+					values.Set(key, Redacted)
+				}
+			}
+			return values.Encode()
+		}
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return string(body)
+	}
+
+	if !redactJSON(value) {
+		return string(body)
+	}
+
+	redacted, _ := json.Marshal(value)
+	return string(redacted)
+}
+
+// redactJSON replaces sensitive values in decoded JSON and reports changes
+func redactJSON(value interface{}) bool {
+	changed := false
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if isSensitiveKey(key) {
+				if _, ok := child.(string); ok {
+					v[key] = Redacted
+					changed = true
+					continue
+				}
+			}
+			if redactJSON(child) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, child := range v {
+			if redactJSON(child) {
+				changed = true
+			}
+		}
+	}
+
+	return changed
+}
+
+// isSensitiveKey reports whether a JSON key holds a secret. Only token and
+// secret names are checked here; generic names like "key" and "code" are
+// common in API payloads and only redacted from query and form parameters.
+func isSensitiveKey(key string) bool {
+	switch key {
+	case "access_token", "refresh_token", "id_token", "client_secret":
+		return true
+	}
+	return false
+}
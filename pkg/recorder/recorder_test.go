@@ -0,0 +1,178 @@
+package recorder
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRecordAndReplay tests that recorded traffic is served back in replay mode
+func TestRecordAndReplay(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"path": "` + r.URL.Path + `", "echo": ` + string(body) + `}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cassettes", "echo.json")
+
+	rec, err := New(path, ModeRecord, nil)
+	if err != nil {
+		t.Fatalf("New() record error = %v", err)
+	}
+
+	resp, err := rec.Client().Post(server.URL+"/events", "application/json", strings.NewReader(`{"n": 1}`))
+	if err != nil {
+		t.Fatalf("Record request failed: %v", err)
+	}
+	recorded, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	server.Close()
+
+	replay, err := New(path, ModeReplay, nil)
+	if err != nil {
+		t.Fatalf("New() replay error = %v", err)
+	}
+
+	// Formatting differences in JSON bodies still match
+	resp, err = replay.Client().Post(server.URL+"/events", "application/json", strings.NewReader(`{ "n" : 1 }`))
+	if err != nil {
+		t.Fatalf("Replay request failed: %v", err)
+	}
+	replayed, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if string(replayed) != string(recorded) {
+		t.Errorf("Replayed body %q does not match recorded %q", replayed, recorded)
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 call to the real server, got %d", calls)
+	}
+
+	// Each interaction is served once
+	if _, err := replay.Client().Post(server.URL+"/events", "application/json", strings.NewReader(`{"n": 1}`)); err == nil {
+		t.Error("Expected error when the interaction was already used")
+	}
+}
+
+// TestReplay_Order tests that identical requests are served in recorded order
+func TestReplay_Order(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "order.json")
+	cassette := `{"interactions": [
+  {"request": {"method": "GET", "url": "https://example.com/items"}, "response": {"statusCode": 429, "body": "slow down"}},
+  {"request": {"method": "GET", "url": "https://example.com/items"}, "response": {"statusCode": 200, "body": "ok"}}
+]}`
+	if err := os.WriteFile(path, []byte(cassette), 0644); err != nil {
+		t.Fatalf("Failed to write cassette: %v", err)
+	}
+
+	rec, err := New(path, ModeReplay, nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	for _, expected := range []int{429, 200} {
+		resp, err := rec.Client().Get("https://example.com/items")
+		if err != nil {
+			t.Fatalf("Replay request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != expected {
+			t.Errorf("Expected status %d, got %d", expected, resp.StatusCode)
+		}
+	}
+
+	if _, err := rec.Client().Get("https://example.com/other"); err == nil {
+		t.Error("Expected error for unrecorded request")
+	}
+}
+
+// TestRedaction tests that credentials never reach the cassette
+func TestRedaction(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret-cookie")
+		w.Write([]byte(`{"access_token": "secret-access", "refresh_token": "secret-refresh", "expires_in": 3600}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "token.json")
+	rec, err := New(path, ModeRecord, nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {"secret-refresh"},
+		"client_secret": {"secret-client"},
+	}
+	req, _ := http.NewRequest("POST", server.URL+"/token?key=secret-key", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer secret-bearer")
+
+	resp, err := rec.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	// The caller still sees the real response
+	if !strings.Contains(string(body), "secret-access") {
+		t.Errorf("Expected unredacted response for the caller, got %s", body)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read cassette: %v", err)
+	}
+	for _, secret := range []string{"secret-access", "secret-refresh", "secret-client", "secret-key", "secret-bearer", "secret-cookie"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("Cassette contains %q", secret)
+		}
+	}
+	if !strings.Contains(string(data), "refresh_token") {
+		t.Error("Expected non-secret parts of the request to be kept")
+	}
+
+	// Replayed requests are redacted the same way, so they still match
+	replay, err := New(path, ModeReplay, nil)
+	if err != nil {
+		t.Fatalf("New() replay error = %v", err)
+	}
+	form.Set("refresh_token", "another-refresh")
+	req, _ = http.NewRequest("POST", server.URL+"/token?key=another-key", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if _, err := replay.Client().Do(req); err != nil {
+		t.Errorf("Expected redacted request to match, got %v", err)
+	}
+}
+
+// TestNew_Errors tests invalid modes and missing cassettes
+func TestNew_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := New(filepath.Join(dir, "missing.json"), ModeReplay, nil); err == nil {
+		t.Error("Expected error for missing cassette in replay mode")
+	}
+
+	if _, err := New(filepath.Join(dir, "x.json"), Mode("stream"), nil); err == nil {
+		t.Error("Expected error for unknown mode")
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	os.WriteFile(bad, []byte("{not json"), 0644)
+	if _, err := New(bad, ModeReplay, nil); err == nil {
+		t.Error("Expected error for invalid cassette")
+	}
+}
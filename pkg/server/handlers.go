@@ -0,0 +1,358 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/btafoya/gcal-cli/pkg/calendar"
+	"github.com/btafoya/gcal-cli/pkg/types"
+)
+
+// maxBodyBytes limits the size of request bodies
+const maxBodyBytes = 1 << 20
+
+// BatchRequest is the body accepted by POST /batch
+type BatchRequest struct {
+	Operation       string                                `json:"operation"` // create, update or delete
+	Events          []calendar.CreateEventParams          `json:"events,omitempty"`
+	Updates         map[string]calendar.CreateEventParams `json:"updates,omitempty"`
+	EventIDs        []string                              `json:"eventIds,omitempty"`
+	ContinueOnError bool                                  `json:"continueOnError"`
+	MaxConcurrent   int                                   `json:"maxConcurrent,omitempty"`
+	IdempotencyKey  string                                `json:"idempotencyKey,omitempty"` // create only
+}
+
+func (s *Server) handleListEvents(w http.ResponseWriter, r *http.Request) {
+	client, err := s.client(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	query := r.URL.Query()
+	params := calendar.ListEventsParams{
+		Query:   query.Get("q"),
+		OrderBy: query.Get("order_by"),
+
+		Properties:       query["has_prop"],
+		SharedProperties: query["has_shared_prop"],
+		EventTypes:       query["event_type"],
+	}
+
+	if params.From, err = parseTimeParam("from", query.Get("from")); err != nil {
+		writeError(w, err)
+		return
+	}
+	if params.To, err = parseTimeParam("to", query.Get("to")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if raw := query.Get("max_results"); raw != "" {
+		maxResults, convErr := strconv.ParseInt(raw, 10, 64)
+		if convErr != nil {
+			writeError(w, types.ErrInvalidInput("max_results", "must be a number"))
+			return
+		}
+		params.MaxResults = maxResults
+	}
+
+	events, err := client.ListEvents(r.Context(), params)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, types.SuccessResponse("list", map[string]interface{}{
+		"events": events,
+		"count":  len(events),
+	}))
+}
+
+func (s *Server) handleCreateEvent(w http.ResponseWriter, r *http.Request) {
+	client, err := s.client(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var params calendar.CreateEventParams
+	if err := decodeBody(r, &params); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	event, err := client.CreateEvent(r.Context(), params)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, types.SuccessResponse("create", map[string]interface{}{
+		"event":   event,
+		"message": "Event created successfully",
+	}))
+}
+
+func (s *Server) handleGetEvent(w http.ResponseWriter, r *http.Request) {
+	client, err := s.client(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	event, err := client.GetEvent(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, types.SuccessResponse("get", map[string]interface{}{
+		"event": event,
+	}))
+}
+
+func (s *Server) handleUpdateEvent(w http.ResponseWriter, r *http.Request) {
+	client, err := s.client(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var params calendar.CreateEventParams
+	if err := decodeBody(r, &params); err != nil {
+		writeError(w, err)
+		return
+	}
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		params.IfMatch = ifMatch
+	}
+
+	event, err := client.UpdateEvent(r.Context(), r.PathValue("id"), params)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, types.SuccessResponse("update", map[string]interface{}{
+		"event":   event,
+		"message": "Event updated successfully",
+	}))
+}
+
+func (s *Server) handleDeleteEvent(w http.ResponseWriter, r *http.Request) {
+	client, err := s.client(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	eventID := r.PathValue("id")
+	if err := client.DeleteEvent(r.Context(), eventID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, types.SuccessResponse("delete", map[string]interface{}{
+		"eventId": eventID,
+		"message": "Event deleted successfully",
+	}))
+}
+
+func (s *Server) handleFreeBusy(w http.ResponseWriter, r *http.Request) {
+	client, err := s.client(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var request calendar.FreeBusyQueryRequest
+	if err := decodeBody(r, &request); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if len(request.CalendarIDs) == 0 {
+		request.CalendarIDs = []string{client.CalendarID}
+	}
+
+	result, err := client.QueryFreeBusy(r.Context(), request)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, types.SuccessResponse("freebusy", result))
+}
+
+func (s *Server) handleListCalendars(w http.ResponseWriter, r *http.Request) {
+	client, err := s.client(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	calendars, err := client.ListCalendars(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, types.SuccessResponse("list_calendars", map[string]interface{}{
+		"calendars": calendars,
+		"count":     len(calendars),
+	}))
+}
+
+func (s *Server) handleBatch(w http.ResponseWriter, r *http.Request) {
+	client, err := s.client(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var request BatchRequest
+	if err := decodeBody(r, &request); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var results []*calendar.BatchResult
+	switch request.Operation {
+	case "create":
+		results, err = client.BatchCreateEvents(r.Context(), calendar.BatchCreateParams{
+			Events:          request.Events,
+			ContinueOnError: request.ContinueOnError,
+			MaxConcurrent:   request.MaxConcurrent,
+			IdempotencyKey:  request.IdempotencyKey,
+		})
+	case "update":
+		results, err = client.BatchUpdateEvents(r.Context(), calendar.BatchUpdateParams{
+			Updates:         request.Updates,
+			ContinueOnError: request.ContinueOnError,
+			MaxConcurrent:   request.MaxConcurrent,
+		})
+	case "delete":
+		results, err = client.BatchDeleteEvents(r.Context(), calendar.BatchDeleteParams{
+			EventIDs:        request.EventIDs,
+			ContinueOnError: request.ContinueOnError,
+			MaxConcurrent:   request.MaxConcurrent,
+		})
+	default:
+		writeError(w, types.ErrInvalidInput("operation",
+			"must be 'create', 'update', or 'delete'"))
+		return
+	}
+
+	data := map[string]interface{}{
+		"operation": request.Operation,
+		"results":   results,
+		"summary":   calendar.GetBatchSummary(results),
+	}
+
+	if err != nil {
+		// Keep per-item results so callers can see which operations failed
+		appErr := toAppError(err)
+		response := types.ErrorResponse(appErr)
+		if results != nil {
+			response.Data = data
+		}
+		writeJSON(w, statusForError(appErr), response)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, types.SuccessResponse("batch", data))
+}
+
+func (s *Server) handleNotFound(w http.ResponseWriter, r *http.Request) {
+	writeError(w, types.ErrNotFound("endpoint", fmt.Sprintf("%s %s", r.Method, r.URL.Path)).
+		WithSuggestedAction("Use /events, /events/{id}, /freebusy, /calendars or /batch"))
+}
+
+// decodeBody decodes a JSON request body into dst
+func decodeBody(r *http.Request, dst interface{}) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxBodyBytes))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(dst); err != nil {
+		return types.NewAppError(types.ErrCodeInvalidFormat,
+			"invalid JSON request body", true).
+			WithDetails(err.Error()).
+			WithWrappedError(err)
+	}
+
+	return nil
+}
+
+// parseTimeParam parses a query parameter as RFC3339 or YYYY-MM-DD
+func parseTimeParam(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, types.ErrMissingRequired(name).
+			WithSuggestedAction(fmt.Sprintf("Provide the %s query parameter", name))
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+
+	return time.Time{}, types.ErrInvalidInput(name,
+		fmt.Sprintf("invalid date format: %s (expected YYYY-MM-DD or RFC3339)", value))
+}
+
+// writeError writes an error response envelope with a matching status code
+func writeError(w http.ResponseWriter, err error) {
+	appErr := toAppError(err)
+	writeJSON(w, statusForError(appErr), types.ErrorResponse(appErr))
+}
+
+// writeJSON writes a response envelope as JSON
+func writeJSON(w http.ResponseWriter, status int, response *types.Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+// toAppError converts any error into an AppError
+func toAppError(err error) *types.AppError {
+	var appErr *types.AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	return types.NewAppError(types.ErrCodeAPIError, "API operation failed", true).
+		WithDetails("operation failed").
+		WithWrappedError(err)
+}
+
+// statusForError maps error codes to HTTP status codes
+func statusForError(err *types.AppError) int {
+	switch err.Code {
+	case types.ErrCodeAuthFailed, types.ErrCodeTokenExpired, types.ErrCodeInvalidCreds:
+		return http.StatusUnauthorized
+	case types.ErrCodeInvalidInput, types.ErrCodeMissingRequired,
+		types.ErrCodeInvalidFormat, types.ErrCodeInvalidTimeRange:
+		return http.StatusBadRequest
+	case types.ErrCodePermissionDenied:
+		return http.StatusForbidden
+	case types.ErrCodeNotFound:
+		return http.StatusNotFound
+	case types.ErrCodeConflict:
+		return http.StatusConflict
+	case types.ErrCodePreconditionFailed:
+		return http.StatusPreconditionFailed
+	case types.ErrCodeRateLimit, types.ErrCodeQuotaExceeded:
+		return http.StatusTooManyRequests
+	case types.ErrCodeNetworkError:
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
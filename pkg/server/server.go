@@ -0,0 +1,189 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/btafoya/gcal-cli/pkg/calendar"
+	"github.com/btafoya/gcal-cli/pkg/types"
+)
+
+// DefaultShutdownTimeout is used when no shutdown timeout is configured
+const DefaultShutdownTimeout = 10 * time.Second
+
+// Config holds settings for the REST server
+type Config struct {
+	Addr            string
+	AuthToken       string
+	CalendarID      string
+	ShutdownTimeout time.Duration
+
+	// ConfigureClient, if set, adjusts the calendar client built for each
+	// request, such as its retry settings
+	ConfigureClient func(client *calendar.Client)
+}
+
+// BackendFactory creates the calendar backend shared by all requests
+type BackendFactory func(ctx context.Context) (calendar.Backend, error)
+
+// Server exposes calendar operations over a local REST API
+type Server struct {
+	config     Config
+	newBackend BackendFactory
+
+	mu      sync.Mutex
+	backend calendar.Backend
+}
+
+// New creates a new REST server. The backend is created on first use so a
+// single authenticated backend is shared across requests.
+func New(newBackend BackendFactory, config Config) *Server {
+	if config.CalendarID == "" {
+		config.CalendarID = "primary"
+	}
+	if config.ShutdownTimeout <= 0 {
+		config.ShutdownTimeout = DefaultShutdownTimeout
+	}
+
+	return &Server{
+		config:     config,
+		newBackend: newBackend,
+	}
+}
+
+// ListenAndServe listens on the configured address and serves requests
+// until the context is cancelled, then shuts down gracefully
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	if s.config.AuthToken == "" {
+		return types.ErrConfigError("server auth token is not configured").
+			WithDetails("server.auth_token").
+			WithSuggestedAction("Run 'gcal-cli config set server.auth_token <token>' to set a bearer token")
+	}
+
+	// Fail fast if the stored credentials are unusable
+	if _, err := s.calendarBackend(ctx); err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", s.config.Addr)
+	if err != nil {
+		return types.ErrNetworkError("could not listen on " + s.config.Addr).
+			WithWrappedError(err).
+			WithSuggestedAction("Choose a different address with --listen")
+	}
+
+	return s.Serve(ctx, listener)
+}
+
+// Serve accepts connections on the listener until the context is cancelled
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	httpServer := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- httpServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-errChan:
+		if err != nil && err != http.ErrServerClosed {
+			return types.ErrNetworkError("server failed").
+				WithWrappedError(err)
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
+	defer cancel()
+
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return types.ErrNetworkError("failed to shut down server").
+			WithWrappedError(err)
+	}
+
+	return nil
+}
+
+// Handler returns the HTTP handler with routing and authentication applied
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /events", s.handleListEvents)
+	mux.HandleFunc("POST /events", s.handleCreateEvent)
+	mux.HandleFunc("GET /events/{id}", s.handleGetEvent)
+	mux.HandleFunc("PATCH /events/{id}", s.handleUpdateEvent)
+	mux.HandleFunc("PUT /events/{id}", s.handleUpdateEvent)
+	mux.HandleFunc("DELETE /events/{id}", s.handleDeleteEvent)
+	mux.HandleFunc("POST /freebusy", s.handleFreeBusy)
+	mux.HandleFunc("GET /calendars", s.handleListCalendars)
+	mux.HandleFunc("POST /batch", s.handleBatch)
+	mux.HandleFunc("/", s.handleNotFound)
+
+	return s.requireAuth(mux)
+}
+
+// requireAuth rejects requests that do not carry the configured bearer token
+func (s *Server) requireAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || s.config.AuthToken == "" ||
+			subtle.ConstantTimeCompare([]byte(token), []byte(s.config.AuthToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="gcal-cli"`)
+			writeError(w, types.ErrAuthFailed("missing or invalid bearer token").
+				WithSuggestedAction("Send 'Authorization: Bearer <server.auth_token>' with each request"))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// calendarBackend returns the shared calendar backend, creating it on first use
+func (s *Server) calendarBackend(ctx context.Context) (calendar.Backend, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.backend != nil {
+		return s.backend, nil
+	}
+
+	if s.newBackend == nil {
+		return nil, types.ErrConfigError("no calendar backend configured")
+	}
+
+	// The backend outlives any single request, so it must not be bound to one
+	backend, err := s.newBackend(context.WithoutCancel(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	s.backend = backend
+	return backend, nil
+}
+
+// client returns a calendar client for the requested calendar
+func (s *Server) client(r *http.Request) (*calendar.Client, error) {
+	backend, err := s.calendarBackend(r.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	calendarID := r.URL.Query().Get("calendar_id")
+	if calendarID == "" {
+		calendarID = s.config.CalendarID
+	}
+
+	client := calendar.NewClientWithBackend(backend, calendarID)
+	if s.config.ConfigureClient != nil {
+		s.config.ConfigureClient(client)
+	}
+	return client, nil
+}
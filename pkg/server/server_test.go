@@ -0,0 +1,340 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btafoya/gcal-cli/pkg/calendar"
+	"github.com/btafoya/gcal-cli/pkg/types"
+	gcal "google.golang.org/api/calendar/v3"
+	"google.golang.org/api/option"
+)
+
+const testToken = "test-server-token"
+
+// newTestServer creates a server whose calendar service talks to a fake API
+func newTestServer(t *testing.T, api http.Handler) *Server {
+	t.Helper()
+
+	apiServer := httptest.NewServer(api)
+	t.Cleanup(apiServer.Close)
+
+	service, err := gcal.NewService(context.Background(),
+		option.WithEndpoint(apiServer.URL+"/"),
+		option.WithHTTPClient(apiServer.Client()))
+	if err != nil {
+		t.Fatalf("Failed to create calendar service: %v", err)
+	}
+
+	s := New(nil, Config{AuthToken: testToken})
+	s.backend = calendar.NewGoogleBackend(service)
+	return s
+}
+
+// doRequest sends an authenticated request to the server handler
+func doRequest(t *testing.T, s *Server, method, path, body string) (*httptest.ResponseRecorder, *types.Response) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, req)
+
+	var response types.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Response is not a JSON envelope: %v (%s)", err, w.Body.String())
+	}
+
+	return w, &response
+}
+
+// TestRequireAuth tests bearer token enforcement
+func TestRequireAuth(t *testing.T) {
+	s := New(nil, Config{AuthToken: testToken})
+
+	tests := []struct {
+		name   string
+		header string
+	}{
+		{"missing header", ""},
+		{"wrong scheme", "Basic " + testToken},
+		{"wrong token", "Bearer not-the-token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/calendars", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			s.Handler().ServeHTTP(w, req)
+
+			if w.Code != http.StatusUnauthorized {
+				t.Errorf("Expected status 401, got %d", w.Code)
+			}
+
+			var response types.Response
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to parse response: %v", err)
+			}
+			if response.Success || response.Error == nil || response.Error.Code != types.ErrCodeAuthFailed {
+				t.Errorf("Expected AUTH_FAILED error envelope, got %+v", response)
+			}
+		})
+	}
+}
+
+// TestHandleListEvents tests listing events through the fake API
+func TestHandleListEvents(t *testing.T) {
+	var gotCalendar, gotQuery string
+	s := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotCalendar = strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/calendars/"), "/events")
+		gotQuery = r.URL.Query().Get("q")
+		json.NewEncoder(w).Encode(&gcal.Events{
+			Items: []*gcal.Event{
+				{Id: "evt1", Summary: "Standup", Start: &gcal.EventDateTime{DateTime: "2024-01-15T09:00:00Z"}},
+			},
+		})
+	}))
+
+	w, response := doRequest(t, s, "GET",
+		"/events?from=2024-01-15&to=2024-01-16&q=standup&calendar_id=team@example.com", "")
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !response.Success || response.Operation != "list" {
+		t.Errorf("Unexpected envelope: %+v", response)
+	}
+	if gotCalendar != "team@example.com" {
+		t.Errorf("Expected calendar 'team@example.com', got '%s'", gotCalendar)
+	}
+	if gotQuery != "standup" {
+		t.Errorf("Expected query 'standup', got '%s'", gotQuery)
+	}
+
+	data, _ := response.Data.(map[string]interface{})
+	if count, _ := data["count"].(float64); count != 1 {
+		t.Errorf("Expected count 1, got %v", data["count"])
+	}
+}
+
+// TestHandleEvents_FakeBackend tests a create/get round trip against the fake backend
+func TestHandleEvents_FakeBackend(t *testing.T) {
+	fake := calendar.NewFakeBackend()
+	s := New(func(ctx context.Context) (calendar.Backend, error) {
+		return fake, nil
+	}, Config{AuthToken: testToken})
+
+	w, response := doRequest(t, s, "POST", "/events",
+		`{"summary": "Review", "start": "2024-01-15T10:00:00Z", "end": "2024-01-15T11:00:00Z"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	data, _ := response.Data.(map[string]interface{})
+	event, _ := data["event"].(map[string]interface{})
+	id, _ := event["id"].(string)
+	if id == "" {
+		t.Fatalf("Expected created event ID, got %+v", data)
+	}
+
+	w, response = doRequest(t, s, "GET", "/events/"+id, "")
+	if w.Code != http.StatusOK || !response.Success {
+		t.Fatalf("Expected created event to be readable, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+// TestHandleListEvents_MissingRange tests validation of query parameters
+func TestHandleListEvents_MissingRange(t *testing.T) {
+	s := newTestServer(t, http.NotFoundHandler())
+
+	w, response := doRequest(t, s, "GET", "/events?to=2024-01-16", "")
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+	if response.Error == nil || response.Error.Code != types.ErrCodeMissingRequired {
+		t.Errorf("Expected MISSING_REQUIRED error, got %+v", response.Error)
+	}
+}
+
+// TestHandleCreateEvent_InvalidBody tests JSON body validation
+func TestHandleCreateEvent_InvalidBody(t *testing.T) {
+	s := newTestServer(t, http.NotFoundHandler())
+
+	w, response := doRequest(t, s, "POST", "/events", `{"summary": "x", "bogus": true}`)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+	if response.Error == nil || response.Error.Code != types.ErrCodeInvalidFormat {
+		t.Errorf("Expected INVALID_FORMAT error, got %+v", response.Error)
+	}
+}
+
+// TestHandleGetEvent_NotFound tests API error propagation
+func TestHandleGetEvent_NotFound(t *testing.T) {
+	s := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error": {"code": 404, "message": "Not Found"}}`))
+	}))
+
+	w, response := doRequest(t, s, "GET", "/events/missing", "")
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+	if response.Error == nil || response.Error.Code != types.ErrCodeNotFound {
+		t.Errorf("Expected NOT_FOUND error, got %+v", response.Error)
+	}
+}
+
+// TestHandleUpdateEvent_IfMatch tests that updates are conditional on the
+// event's etag, taken from the If-Match header when given
+func TestHandleUpdateEvent_IfMatch(t *testing.T) {
+	var sentIfMatch []string
+	s := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PATCH" {
+			sentIfMatch = append(sentIfMatch, r.Header.Get("If-Match"))
+		}
+		json.NewEncoder(w).Encode(&gcal.Event{
+			Id:      "evt1",
+			Etag:    `"2"`,
+			Summary: "Standup",
+			Start:   &gcal.EventDateTime{DateTime: "2024-01-15T09:00:00Z"},
+			End:     &gcal.EventDateTime{DateTime: "2024-01-15T09:15:00Z"},
+		})
+	}))
+
+	req := httptest.NewRequest("PATCH", "/events/evt1", strings.NewReader(`{"summary": "Retro"}`))
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	req.Header.Set("If-Match", `"1"`)
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("Expected status 412, got %d: %s", w.Code, w.Body.String())
+	}
+	var response types.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if response.Error == nil || response.Error.Code != types.ErrCodePreconditionFailed || response.Error.Current == nil {
+		t.Errorf("Expected PRECONDITION_FAILED with the current event, got %+v", response.Error)
+	}
+	if len(sentIfMatch) != 0 {
+		t.Errorf("Stale update was sent to the API")
+	}
+
+	w, _ = doRequest(t, s, "PATCH", "/events/evt1", `{"summary": "Retro"}`)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(sentIfMatch) != 1 || sentIfMatch[0] != `"2"` {
+		t.Errorf("Expected If-Match of the read etag, got %q", sentIfMatch)
+	}
+}
+
+// TestHandleBatch_InvalidOperation tests batch operation validation
+func TestHandleBatch_InvalidOperation(t *testing.T) {
+	s := newTestServer(t, http.NotFoundHandler())
+
+	w, response := doRequest(t, s, "POST", "/batch", `{"operation": "merge"}`)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+	if response.Error == nil || response.Error.Code != types.ErrCodeInvalidInput {
+		t.Errorf("Expected INVALID_INPUT error, got %+v", response.Error)
+	}
+}
+
+// TestHandleNotFound tests unknown endpoints
+func TestHandleNotFound(t *testing.T) {
+	s := newTestServer(t, http.NotFoundHandler())
+
+	w, response := doRequest(t, s, "GET", "/unknown", "")
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+	if response.Error == nil || response.Error.Code != types.ErrCodeNotFound {
+		t.Errorf("Expected NOT_FOUND error, got %+v", response.Error)
+	}
+}
+
+// TestStatusForError tests error code to HTTP status mapping
+func TestStatusForError(t *testing.T) {
+	tests := []struct {
+		code   string
+		status int
+	}{
+		{types.ErrCodeAuthFailed, http.StatusUnauthorized},
+		{types.ErrCodeInvalidInput, http.StatusBadRequest},
+		{types.ErrCodeInvalidTimeRange, http.StatusBadRequest},
+		{types.ErrCodePermissionDenied, http.StatusForbidden},
+		{types.ErrCodeNotFound, http.StatusNotFound},
+		{types.ErrCodeConflict, http.StatusConflict},
+		{types.ErrCodePreconditionFailed, http.StatusPreconditionFailed},
+		{types.ErrCodeRateLimit, http.StatusTooManyRequests},
+		{types.ErrCodeQuotaExceeded, http.StatusTooManyRequests},
+		{types.ErrCodeNetworkError, http.StatusBadGateway},
+		{types.ErrCodeAPIError, http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			got := statusForError(types.NewAppError(tt.code, "test", true))
+			if got != tt.status {
+				t.Errorf("statusForError(%s) = %d, want %d", tt.code, got, tt.status)
+			}
+		})
+	}
+}
+
+// TestServe_GracefulShutdown tests that cancelling the context stops the server
+func TestServe_GracefulShutdown(t *testing.T) {
+	s := New(nil, Config{AuthToken: testToken, ShutdownTimeout: time.Second})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Serve(ctx, listener)
+	}()
+
+	// Server should answer while running
+	resp, err := http.Get("http://" + listener.Addr().String() + "/calendars")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", resp.StatusCode)
+	}
+
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Serve returned error after shutdown: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Server did not shut down")
+	}
+}
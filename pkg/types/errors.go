@@ -16,15 +16,21 @@ const (
 	ErrCodeInvalidTimeRange = "INVALID_TIME_RANGE"
 
 	// API errors
-	ErrCodeNotFound         = "NOT_FOUND"
-	ErrCodeRateLimit        = "RATE_LIMIT"
-	ErrCodeAPIError         = "API_ERROR"
-	ErrCodePermissionDenied = "PERMISSION_DENIED"
+	ErrCodeNotFound           = "NOT_FOUND"
+	ErrCodeRateLimit          = "RATE_LIMIT"
+	ErrCodeAPIError           = "API_ERROR"
+	ErrCodePermissionDenied   = "PERMISSION_DENIED"
+	ErrCodeConflict           = "CONFLICT"
+	ErrCodeQuotaExceeded      = "QUOTA_EXCEEDED"
+	ErrCodePreconditionFailed = "PRECONDITION_FAILED"
 
 	// System errors
 	ErrCodeConfigError  = "CONFIG_ERROR"
 	ErrCodeNetworkError = "NETWORK_ERROR"
 	ErrCodeFileError    = "FILE_ERROR"
+	ErrCodeCacheMiss    = "CACHE_MISS"
+	ErrCodeQueued       = "QUEUED"
+	ErrCodeCancelled    = "CANCELLED"
 )
 
 // AppError represents a structured error with code and recovery information
@@ -34,7 +40,10 @@ type AppError struct {
 	Details         string `json:"details,omitempty"`
 	Recoverable     bool   `json:"recoverable"`
 	SuggestedAction string `json:"suggestedAction,omitempty"`
-	wrapped         error  // Internal error for debugging
+	// Current is the server's version of a resource that changed under a
+	// conditional write, so callers can merge without another request
+	Current interface{} `json:"current,omitempty"`
+	wrapped error       // Internal error for debugging
 }
 
 // Error implements the error interface
@@ -71,6 +80,12 @@ func (e *AppError) WithSuggestedAction(action string) *AppError {
 	return e
 }
 
+// WithCurrent attaches the current version of the resource
+func (e *AppError) WithCurrent(current interface{}) *AppError {
+	e.Current = current
+	return e
+}
+
 // WithWrappedError wraps an underlying error
 func (e *AppError) WithWrappedError(err error) *AppError {
 	e.wrapped = err
@@ -131,6 +146,19 @@ func ErrNetworkError(message string) *AppError {
 		WithSuggestedAction("Check your internet connection and try again")
 }
 
+// ErrCancelled creates an error for an operation interrupted by the user
+func ErrCancelled() *AppError {
+	return NewAppError(ErrCodeCancelled, "operation cancelled", true).
+		WithSuggestedAction("Run the command again to complete the operation")
+}
+
+// ErrCacheMiss creates an error for reads the offline cache cannot answer
+func ErrCacheMiss(resource string) *AppError {
+	return NewAppError(ErrCodeCacheMiss,
+		fmt.Sprintf("%s not available in the local cache", resource), true).
+		WithSuggestedAction("Run the command without --offline to populate the cache")
+}
+
 // ErrFileError creates a file operation error
 var ErrFileError = NewAppError(ErrCodeFileError, "File operation failed", true)
 
@@ -12,6 +12,71 @@ type Event struct {
 	Recurrence  []string   `json:"recurrence,omitempty"`
 	Location    string     `json:"location,omitempty"`
 	HTMLLink    string     `json:"htmlLink,omitempty"`
+	ETag        string     `json:"etag,omitempty"`
+	Organizer   string     `json:"organizer,omitempty"`
+	// Conference holds the video and phone details, such as a Meet link
+	Conference *Conference `json:"conference,omitempty"`
+	// RecurringEventID is the recurring event an instance belongs to
+	RecurringEventID string `json:"recurringEventId,omitempty"`
+	// ExtendedProperties are key/value tags stored with the event
+	ExtendedProperties *ExtendedProperties `json:"extendedProperties,omitempty"`
+	// EventType is default, outOfOffice, focusTime, workingLocation,
+	// birthday or fromGmail; the matching settings below are set for the
+	// first three
+	EventType       string           `json:"eventType,omitempty"`
+	OutOfOffice     *OutOfOffice     `json:"outOfOffice,omitempty"`
+	FocusTime       *FocusTime       `json:"focusTime,omitempty"`
+	WorkingLocation *WorkingLocation `json:"workingLocation,omitempty"`
+}
+
+// OutOfOffice holds the settings of an out-of-office event
+type OutOfOffice struct {
+	// AutoDeclineMode is declineNone, declineAllConflictingInvitations or
+	// declineOnlyNewConflictingInvitations
+	AutoDeclineMode string `json:"autoDeclineMode,omitempty"`
+	DeclineMessage  string `json:"declineMessage,omitempty"`
+}
+
+// FocusTime holds the settings of a focus time event
+type FocusTime struct {
+	AutoDeclineMode string `json:"autoDeclineMode,omitempty"` // as for OutOfOffice
+	DeclineMessage  string `json:"declineMessage,omitempty"`
+	ChatStatus      string `json:"chatStatus,omitempty"` // available or doNotDisturb
+}
+
+// WorkingLocation is where the user works during a working location event
+type WorkingLocation struct {
+	Type       string `json:"type"`            // homeOffice, officeLocation or customLocation
+	Label      string `json:"label,omitempty"` // name of the office or custom location
+	BuildingID string `json:"buildingId,omitempty"`
+	FloorID    string `json:"floorId,omitempty"`
+	DeskID     string `json:"deskId,omitempty"`
+}
+
+// ExtendedProperties are the key/value tags of an event. Private properties
+// are only seen on this calendar's copy of the event; shared ones are seen
+// by all attendees.
+type ExtendedProperties struct {
+	Private map[string]string `json:"private,omitempty"`
+	Shared  map[string]string `json:"shared,omitempty"`
+}
+
+// Conference represents the conference attached to an event
+type Conference struct {
+	ID       string `json:"id,omitempty"`
+	Solution string `json:"solution,omitempty"` // e.g. "Google Meet"
+	// Status is "pending" while the conference is still being created and
+	// has no entry points yet, "success" once it has, or "failure"
+	Status      string       `json:"status,omitempty"`
+	EntryPoints []EntryPoint `json:"entryPoints,omitempty"`
+}
+
+// EntryPoint is one way of joining a conference
+type EntryPoint struct {
+	Type  string `json:"type"` // video, phone, sip or more
+	URI   string `json:"uri"`
+	Label string `json:"label,omitempty"`
+	PIN   string `json:"pin,omitempty"`
 }
 
 // EventTime represents a point in time for an event
@@ -27,4 +92,8 @@ type Attendee struct {
 	ResponseStatus string `json:"responseStatus"`
 	Organizer      bool   `json:"organizer,omitempty"`
 	DisplayName    string `json:"displayName,omitempty"`
+	Comment        string `json:"comment,omitempty"`
+	// Self is true for the attendee that is the calendar being read, i.e.
+	// the authenticated user on their own calendar
+	Self bool `json:"self,omitempty"`
 }
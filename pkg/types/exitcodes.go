@@ -0,0 +1,65 @@
+package types
+
+import "errors"
+
+// Process exit codes, one per class of error code, so scripts and agents can
+// branch on the kind of failure without parsing output
+const (
+	ExitOK         = 0
+	ExitError      = 1 // API, file and unclassified errors
+	ExitUsage      = 2 // invalid or missing input, unknown flags
+	ExitAuth       = 3
+	ExitNotFound   = 4
+	ExitPermission = 5
+	ExitRateLimit  = 6
+	ExitNetwork    = 7
+	ExitConfig     = 8
+	ExitConflict   = 9
+	ExitQueued     = 10
+	ExitCacheMiss  = 11
+	ExitCancelled  = 130 // 128 + SIGINT, as shells report it
+)
+
+// ExitCodeFor returns the process exit code for an error code
+func ExitCodeFor(code string) int {
+	switch code {
+	case ErrCodeAuthFailed, ErrCodeTokenExpired, ErrCodeInvalidCreds:
+		return ExitAuth
+	case ErrCodeInvalidInput, ErrCodeMissingRequired, ErrCodeInvalidFormat, ErrCodeInvalidTimeRange:
+		return ExitUsage
+	case ErrCodeNotFound:
+		return ExitNotFound
+	case ErrCodePermissionDenied:
+		return ExitPermission
+	case ErrCodeRateLimit, ErrCodeQuotaExceeded:
+		return ExitRateLimit
+	case ErrCodeNetworkError:
+		return ExitNetwork
+	case ErrCodeConfigError:
+		return ExitConfig
+	case ErrCodeConflict, ErrCodePreconditionFailed:
+		return ExitConflict
+	case ErrCodeQueued:
+		return ExitQueued
+	case ErrCodeCacheMiss:
+		return ExitCacheMiss
+	case ErrCodeCancelled:
+		return ExitCancelled
+	default:
+		return ExitError
+	}
+}
+
+// ExitCode returns the process exit code for err: ExitOK for nil, the code's
+// class for an AppError, and ExitError otherwise
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return ExitCodeFor(appErr.Code)
+	}
+	return ExitError
+}
@@ -0,0 +1,42 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// TestExitCode tests the mapping from errors to process exit codes
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"success", nil, ExitOK},
+		{"auth", ErrAuthFailed("no token"), ExitAuth},
+		{"expired token", ErrTokenExpired(), ExitAuth},
+		{"validation", ErrInvalidInput("start", "bad time"), ExitUsage},
+		{"missing flag", ErrMissingRequired("title"), ExitUsage},
+		{"not found", ErrNotFound("event", "abc"), ExitNotFound},
+		{"permission", NewAppError(ErrCodePermissionDenied, "insufficient permissions", false), ExitPermission},
+		{"rate limit", ErrRateLimit(), ExitRateLimit},
+		{"quota", NewAppError(ErrCodeQuotaExceeded, "API quota exceeded", true), ExitRateLimit},
+		{"conflict", NewAppError(ErrCodeConflict, "event ID already used", false), ExitConflict},
+		{"precondition", NewAppError(ErrCodePreconditionFailed, "event changed", true), ExitConflict},
+		{"network", ErrNetworkError("offline"), ExitNetwork},
+		{"config", ErrConfigError("bad config"), ExitConfig},
+		{"cancelled", ErrCancelled(), ExitCancelled},
+		{"api", NewAppError(ErrCodeAPIError, "API operation failed", true), ExitError},
+		{"wrapped", fmt.Errorf("create: %w", ErrRateLimit()), ExitRateLimit},
+		{"plain", errors.New("boom"), ExitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.want {
+				t.Errorf("ExitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btafoya/gcal-cli/pkg/auth"
+	"github.com/btafoya/gcal-cli/pkg/calendar"
+	gcal "google.golang.org/api/calendar/v3"
+)
+
+// newFakeCalendarAPI starts an httptest server that answers a small subset of
+// the Calendar API. Point gcal-cli at it with api.endpoint (or
+// GCAL_API_ENDPOINT) and auth.mode: none.
+func newFakeCalendarAPI(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /calendars/{calendarId}/events", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("Expected no credentials, got Authorization header")
+		}
+
+		json.NewEncoder(w).Encode(&gcal.Events{
+			Items: []*gcal.Event{{
+				Id:      "evt1",
+				Summary: "Standup in " + r.PathValue("calendarId"),
+				Start:   &gcal.EventDateTime{DateTime: "2024-01-15T09:00:00Z"},
+				End:     &gcal.EventDateTime{DateTime: "2024-01-15T09:15:00Z"},
+			}},
+		})
+	})
+	mux.HandleFunc("POST /calendars/{calendarId}/events", func(w http.ResponseWriter, r *http.Request) {
+		var event gcal.Event
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		event.Id = "created1"
+		event.Status = "confirmed"
+		json.NewEncoder(w).Encode(&event)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error": {"code": 404, "message": "Not Found"}}`))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newEndpointClient creates a calendar client the same way the CLI does with
+// auth.mode: none and api.endpoint set to the fake server
+func newEndpointClient(t *testing.T, endpoint string) *calendar.Client {
+	t.Helper()
+
+	service, err := auth.NewUnauthenticatedService(context.Background(), endpoint)
+	if err != nil {
+		t.Fatalf("NewUnauthenticatedService() error = %v", err)
+	}
+
+	client := calendar.NewClient(service, "team@example.com")
+	client.RetryDelay = time.Millisecond
+	return client
+}
+
+// TestCustomEndpoint_ListEvents tests listing events from a local stand-in
+func TestCustomEndpoint_ListEvents(t *testing.T) {
+	server := newFakeCalendarAPI(t)
+	client := newEndpointClient(t, server.URL)
+
+	events, err := client.ListEvents(context.Background(), calendar.ListEventsParams{
+		From: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("ListEvents() error = %v", err)
+	}
+
+	if len(events) != 1 || !strings.HasSuffix(events[0].Summary, "team@example.com") {
+		t.Errorf("Unexpected events: %+v", events)
+	}
+}
+
+// TestCustomEndpoint_CreateEvent tests creating an event on a local stand-in
+func TestCustomEndpoint_CreateEvent(t *testing.T) {
+	server := newFakeCalendarAPI(t)
+	client := newEndpointClient(t, server.URL)
+
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	event, err := client.CreateEvent(context.Background(), calendar.CreateEventParams{
+		Summary: "Review",
+		Start:   start,
+		End:     start.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("CreateEvent() error = %v", err)
+	}
+
+	if event.ID != "created1" || event.Summary != "Review" {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+}
+
+// TestCustomEndpoint_NotFound tests that API errors from the stand-in are translated
+func TestCustomEndpoint_NotFound(t *testing.T) {
+	server := newFakeCalendarAPI(t)
+	client := newEndpointClient(t, server.URL)
+
+	if _, err := client.GetEvent(context.Background(), "missing"); err == nil {
+		t.Error("Expected error for missing event")
+	}
+}
+
+// TestCustomEndpoint_Required tests that auth mode none needs an endpoint
+func TestCustomEndpoint_Required(t *testing.T) {
+	if _, err := auth.NewUnauthenticatedService(context.Background(), ""); err == nil {
+		t.Error("Expected error when no endpoint is configured")
+	}
+}